@@ -0,0 +1,283 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// archive kinds
+const (
+	archiveZip = iota
+	archiveTar
+)
+
+// tarMagicOffset is where a tar header says "ustar" (POSIX and GNU tar)
+const tarMagicOffset = 257
+
+// archiveFile is a zip or tar archive, possibly compressed, whose members
+// are opened one at a time
+type archiveFile struct {
+	fn      string
+	kind    int
+	members []string // regular files in archive order
+	data    []byte   // zip read from a pipe, nil for files
+}
+
+// isTarFile checks whether fn is a tar archive, compressed or not
+func isTarFile(fn string) bool {
+	rc, err := openTarStream(fn)
+	if err != nil {
+		return false
+	}
+	defer rc.Close()
+	head := make([]byte, tarMagicOffset+5)
+	if _, err := io.ReadFull(rc, head); err != nil {
+		return false
+	}
+	return bytes.Equal(head[tarMagicOffset:], []byte("ustar"))
+}
+
+// openTarStream opens fn and decompresses it by content, the result is the
+// raw tar stream
+func openTarStream(fn string) (io.ReadCloser, error) {
+	file, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(file)
+	head, _ := br.Peek(compressMagicLen)
+	dr, err := newDecompressor(detectCompression(head), br)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{dr, fileCloser{file, dr}}, nil
+}
+
+// isArchiveFile checks whether fn is a zip (but not a workbook) or tar archive
+func isArchiveFile(fn string, head []byte) bool {
+	if bytes.HasPrefix(head, []byte(zipMagic)) {
+		return !isXLSXZipFile(fn)
+	}
+	return isTarFile(fn)
+}
+
+// openArchive lists the files in the zip or tar archive fn
+func openArchive(fn string) (*archiveFile, error) {
+	head, err := readFileHead(fn, len(zipMagic))
+	if err != nil {
+		return nil, err
+	}
+	a := &archiveFile{fn: fn}
+	if bytes.HasPrefix(head, []byte(zipMagic)) {
+		zr, closer, err := a.openZip()
+		if err != nil {
+			return nil, err
+		}
+		defer closer.Close()
+		a.listZip(zr)
+	} else {
+		a.kind = archiveTar
+		rc, err := openTarStream(fn)
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		tr := tar.NewReader(rc)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if hdr.Typeflag == tar.TypeReg && !skipArchiveMember(hdr.Name) {
+				a.members = append(a.members, hdr.Name)
+			}
+		}
+	}
+	if len(a.members) == 0 {
+		return nil, errors.New(fn + ": the archive holds no files")
+	}
+	return a, nil
+}
+
+// openZipPipe reads a zip from a pipe, it opens as a workbook when it holds
+// xl/workbook.xml and as an archive otherwise
+func openZipPipe(r io.Reader) (*sourceSet, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	a := &archiveFile{fn: "stdin", data: data}
+	zr, _, err := a.openZip()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		if f.Name == "xl/workbook.xml" {
+			wb, err := openXLSX(data)
+			if err != nil {
+				return nil, err
+			}
+			return newXLSXSourceSet(wb), nil
+		}
+	}
+	a.listZip(zr)
+	if len(a.members) == 0 {
+		return nil, errors.New("stdin: the archive holds no files")
+	}
+	return newArchiveSourceSet(a), nil
+}
+
+// openZip opens the zip file of a, or the zip read from a pipe
+func (a *archiveFile) openZip() (*zip.Reader, io.Closer, error) {
+	if a.data != nil {
+		zr, err := zip.NewReader(bytes.NewReader(a.data), int64(len(a.data)))
+		return zr, io.NopCloser(nil), err
+	}
+	zr, err := zip.OpenReader(a.fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &zr.Reader, zr, nil
+}
+
+// listZip sets the members of a to the regular files of zr
+func (a *archiveFile) listZip(zr *zip.Reader) {
+	for _, f := range zr.File {
+		if f.Mode().IsRegular() && !skipArchiveMember(f.Name) {
+			a.members = append(a.members, f.Name)
+		}
+	}
+}
+
+// skipArchiveMember leaves out the metadata macOS adds to archives
+func skipArchiveMember(name string) bool {
+	return strings.HasPrefix(name, "__MACOSX/") || strings.HasSuffix(name, "/.DS_Store") || name == ".DS_Store"
+}
+
+// open returns the content of a member as stored in the archive
+func (a *archiveFile) open(name string) (io.ReadCloser, error) {
+	if a.kind == archiveZip {
+		zr, closer, err := a.openZip()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range zr.File {
+			if f.Name == name {
+				rc, err := f.Open()
+				if err != nil {
+					closer.Close()
+					return nil, err
+				}
+				return struct {
+					io.Reader
+					io.Closer
+				}{rc, closer}, nil
+			}
+		}
+		closer.Close()
+		return nil, errors.New(name + " not found in " + a.fn)
+	}
+
+	rc, err := openTarStream(a.fn)
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rc.Close()
+			return nil, err
+		}
+		if hdr.Name == name {
+			return struct {
+				io.Reader
+				io.Closer
+			}{tr, rc}, nil
+		}
+	}
+	rc.Close()
+	return nil, errors.New(name + " not found in " + a.fn)
+}
+
+// loadMember loads a member into b through the pipe loaders, compressed
+// members are decompressed and the separator comes from the member name
+func (a *archiveFile) loadMember(name string, b *Buffer) error {
+	rc, err := a.open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	r, err := decompressPipe(bufio.NewReader(rc))
+	if err != nil {
+		return err
+	}
+	if r, err = decodePipe(r); err != nil {
+		return err
+	}
+
+	// Skip rules are applied per member, so switching members behaves the same
+	skipNum := args.SkipNum
+	defer func() { args.SkipNum = skipNum }()
+
+	switch detectPipeFormat(r) {
+	case formatJSONLines:
+		return loadJSONLPipeToBuffer(r, b)
+	case formatParquet:
+		return loadParquetPipeToBuffer(r, b)
+	case formatArrow:
+		return loadArrowPipeToBuffer(r, b)
+	case formatArchive:
+		return errors.New(name + ": archives and workbooks inside an archive cannot be opened")
+	}
+	if b.sep == "" {
+		if strings.HasSuffix(plainFileName(name), ".csv") {
+			b.sep = ","
+		} else if strings.HasSuffix(plainFileName(name), ".tsv") {
+			b.sep = "\t"
+		}
+	}
+	return loadPipeToBuffer(r, b)
+}
+
+// newArchiveSourceSet exposes the members of an archive to the source picker
+func newArchiveSourceSet(a *archiveFile) *sourceSet {
+	return &sourceSet{
+		kind:  "File",
+		names: a.members,
+		load:  a.loadMember,
+	}
+}
+
+// splitArchiveMember splits "bundle.zip:data/x.csv" into the archive and the
+// member name, ok is false unless the part before a colon is an existing file
+func splitArchiveMember(arg string) (fn, member string, ok bool) {
+	if _, err := os.Stat(arg); err == nil {
+		return "", "", false
+	}
+	for i := 0; i < len(arg); i++ {
+		if arg[i] != ':' {
+			continue
+		}
+		if info, err := os.Stat(arg[:i]); err == nil && info.Mode().IsRegular() {
+			return arg[:i], arg[i+1:], true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDetectArchiveFormat(t *testing.T) {
+	tests := []struct {
+		fn   string
+		want int
+	}{
+		{"./data/test/bundle.zip", formatArchive},
+		{"./data/test/bundle.tar.gz", formatArchive},
+		{"./data/test/numeric_data.csv.gz", formatDelimited},
+		{"./data/test/test.csv", formatDelimited},
+	}
+	for _, tt := range tests {
+		if got, err := detectFileFormat(tt.fn); err != nil || got != tt.want {
+			t.Errorf("detectFileFormat(%s) = %d, %v, want %d", tt.fn, got, err, tt.want)
+		}
+	}
+}
+
+func TestArchiveMembers(t *testing.T) {
+	defer args.setDefault()
+	tests := []struct {
+		fn      string
+		members []string
+	}{
+		{"./data/test/bundle.zip", []string{"data/sales.csv", "data/regions.tsv.gz", "notes.csv"}},
+		{"./data/test/bundle.tar.gz", []string{"data/sales.csv", "data/regions.tsv.gz"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fn, func(t *testing.T) {
+			a, err := openArchive(tt.fn)
+			if err != nil {
+				t.Fatalf("openArchive() error = %v", err)
+			}
+			if !reflect.DeepEqual(a.members, tt.members) {
+				t.Errorf("members = %q, want %q", a.members, tt.members)
+			}
+
+			args.SkipNum = 1
+			set := newArchiveSourceSet(a)
+			want := map[string][][]string{
+				"data/sales.csv":      {{"north", "12"}, {"south", "7"}},
+				"data/regions.tsv.gz": {{"N", "North"}, {"S", "South"}},
+			}
+			for name, rows := range want {
+				b := createNewBuffer()
+				if err := set.loadInto(name, b); err != nil {
+					t.Fatalf("loadInto(%s) error = %v", name, err)
+				}
+				if !reflect.DeepEqual(b.cont, rows) {
+					t.Errorf("%s = %q, want %q", name, b.cont, rows)
+				}
+				if set.current != name {
+					t.Errorf("current = %q, want %q", set.current, name)
+				}
+			}
+			if args.SkipNum != 1 {
+				t.Errorf("SkipNum = %d after loading, want 1", args.SkipNum)
+			}
+			if err := set.loadInto("missing.csv", createNewBuffer()); err == nil {
+				t.Error("Loading a missing member should fail")
+			}
+		})
+	}
+}
+
+func TestSplitArchiveMember(t *testing.T) {
+	tests := []struct {
+		arg, fn, member string
+		ok              bool
+	}{
+		{"./data/test/bundle.zip:data/sales.csv", "./data/test/bundle.zip", "data/sales.csv", true},
+		{"./data/test/bundle.tar.gz:notes.csv", "./data/test/bundle.tar.gz", "notes.csv", true},
+		{"./data/test/bundle.zip", "", "", false},
+		{"./data/test/missing.zip:a.csv", "", "", false},
+	}
+	for _, tt := range tests {
+		fn, member, ok := splitArchiveMember(tt.arg)
+		if fn != tt.fn || member != tt.member || ok != tt.ok {
+			t.Errorf("splitArchiveMember(%q) = %q, %q, %v", tt.arg, fn, member, ok)
+		}
+	}
+}
+
+func TestOpenZipPipe(t *testing.T) {
+	bundle, err := os.ReadFile("./data/test/bundle.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		data  []byte
+		kind  string
+		first string
+	}{
+		{"archive", bundle, "File", "data/sales.csv"},
+		{"workbook", writeTestXLSX(t), "Sheet", "Sales"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := bufio.NewReader(bytes.NewReader(tt.data))
+			if format := detectPipeFormat(r); format != formatArchive {
+				t.Fatalf("detectPipeFormat() = %d, want %d", format, formatArchive)
+			}
+			set, err := openZipPipe(r)
+			if err != nil {
+				t.Fatalf("openZipPipe() error = %v", err)
+			}
+			if set.kind != tt.kind || set.names[0] != tt.first {
+				t.Fatalf("set = %s %q, want %s starting with %s", set.kind, set.names, tt.kind, tt.first)
+			}
+			if err := set.loadInto(tt.first, createNewBuffer()); err != nil {
+				t.Errorf("loadInto(%s) error = %v", tt.first, err)
+			}
+		})
+	}
+}
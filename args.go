@@ -2,28 +2,64 @@ package main
 
 // Args struct
 type Args struct {
-	FileName   string
-	Sep        string
-	SkipSymbol []string //ignore line with specified prefix
-	SkipNum    int      //Number of lines that should be skipped
-	ShowNum    []int    //columns that should be displayed
-	HideNum    []int    //columns that should be hidden
-	Header     int      //header display mode
-	NLine      int      //number of lines that should be displayed
-	Strict     bool     // check for missing data
-	AsyncLoad  bool     // enable async loading for progressive rendering
-	MemoryMB   int      // Memory limit in MB (0 = unlimited/default, >0 = custom limit)
+	FileName     string
+	Sep          string
+	Quote        string   // quote character, empty to turn quoting off
+	Escape       string   // escape character, empty to escape quotes by doubling
+	SkipSymbol   []string //ignore line with specified prefix
+	SkipNum      int      //Number of lines that should be skipped
+	Columns      []string //columns that should be displayed, by number, range, name or re:pattern
+	HideColumns  []string //columns that should be hidden
+	Header       int      //header display mode
+	NLine        int      //number of lines that should be displayed
+	Strict       bool     // check for missing data
+	AsyncLoad    bool     // enable async loading for progressive rendering
+	MemoryMB     int      // Memory limit in MB (0 = unlimited/default, >0 = custom limit)
+	Widths       []int    // fixed column widths, empty to use a separator
+	FixedWidth   bool     // infer fixed-width columns from whitespace alignment
+	Index        bool     // browse the file from disk through a line index
+	Follow       bool     // keep reading rows appended to the file or pipe
+	MaxRows      int      // keep only the newest rows (0 = all)
+	Encoding     string   // text encoding of the input, empty to detect it
+	Pattern      string   // regular expression or preset splitting log lines
+	Unmatched    string   // what to do with lines the pattern does not match
+	Query        string   // SQL query to run against a SQLite database
+	Concat       bool     // load every file into one table, aligned by header
+	SourceColumn bool     // add a _source column naming the file of each row
+	Print        bool     // print the table to stdout instead of opening the viewer
+	Border       string   // border style of the printed table
+	YankFile     string   // write copied text to this file instead of the clipboard
+	YankCommand  string   // pipe copied text to this shell command instead of the clipboard
+	SQL          string   // SQL query over the loaded table whose result is shown
 }
 
 func (args *Args) setDefault() {
 	args.Sep = ""
+	args.Quote = `"`
+	args.Escape = ""
 	args.SkipSymbol = []string{}
 	args.SkipNum = 0
-	args.ShowNum = []int{}
-	args.HideNum = []int{}
+	args.Columns = []string{}
+	args.HideColumns = []string{}
 	args.Header = 0
 	args.NLine = 0
 	args.Strict = false
 	args.AsyncLoad = true // default to async loading
 	args.MemoryMB = 0     // Unlimited by default
+	args.Widths = []int{}
+	args.FixedWidth = false
+	args.Index = false
+	args.Follow = false
+	args.MaxRows = 0
+	args.Encoding = ""
+	args.Pattern = ""
+	args.Unmatched = unmatchedSkip
+	args.Query = ""
+	args.Concat = false
+	args.SourceColumn = false
+	args.Print = false
+	args.Border = borderBox
+	args.YankFile = ""
+	args.YankCommand = ""
+	args.SQL = ""
 }
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+)
+
+// arrowMagic starts (and ends) every Arrow IPC file, Feather v2 included
+const arrowMagic = "ARROW1"
+
+// arrowStreamMagic is the continuation marker in front of every message of
+// an Arrow IPC stream
+const arrowStreamMagic = "\xff\xff\xff\xff"
+
+// featherV1Magic starts files written by Feather before it became Arrow IPC
+const featherV1Magic = "FEA1"
+
+// isArrowFile checks the file name for an Arrow IPC or Feather extension
+func isArrowFile(fn string) bool {
+	lower := strings.ToLower(fn)
+	for _, ext := range []string{".arrow", ".arrows", ".feather", ".ipc"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// arrowFileReader walks the record batches of an Arrow IPC file in order, so
+// files and streams are both read as an array.RecordReader
+type arrowFileReader struct {
+	*ipc.FileReader
+	rec arrow.RecordBatch
+	err error
+}
+
+func (r *arrowFileReader) Next() bool {
+	rec, err := r.Read()
+	if err != nil {
+		if err != io.EOF {
+			r.err = err
+		}
+		r.rec = nil
+		return false
+	}
+	r.rec = rec
+	return true
+}
+
+func (r *arrowFileReader) RecordBatch() arrow.RecordBatch { return r.rec }
+func (r *arrowFileReader) Record() arrow.Record           { return r.rec }
+func (r *arrowFileReader) Err() error                     { return r.err }
+func (r *arrowFileReader) Retain()                        {}
+func (r *arrowFileReader) Release()                       { r.Close() }
+
+// countingFile counts the bytes read from a file, for the progress bar
+type countingFile struct {
+	*os.File
+	read int64
+}
+
+func (cf *countingFile) Read(p []byte) (int, error) {
+	n, err := cf.File.Read(p)
+	cf.read += int64(n)
+	return n, err
+}
+
+func (cf *countingFile) ReadAt(p []byte, off int64) (int, error) {
+	n, err := cf.File.ReadAt(p, off)
+	cf.read += int64(n)
+	return n, err
+}
+
+// arrowRecords is an open Arrow IPC file or stream read as record batches
+type arrowRecords struct {
+	records array.RecordReader
+	file    *countingFile // nil for pipes
+	size    int64
+	last    int64 // bytes read when progress was last reported
+}
+
+// openArrowRecords picks the file or stream reader from the leading bytes
+func openArrowRecords(head []byte, r io.Reader, ra ipc.ReadAtSeeker) (array.RecordReader, error) {
+	switch {
+	case bytes.HasPrefix(head, []byte(featherV1Magic)):
+		return nil, errors.New("this is a Feather v1 file, which is not supported: save it again with Feather v2, the default since pyarrow 0.17")
+	case bytes.HasPrefix(head, []byte(arrowMagic)):
+		if ra == nil {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			ra = bytes.NewReader(data)
+		}
+		fr, err := ipc.NewFileReader(ra)
+		if err != nil {
+			return nil, err
+		}
+		return &arrowFileReader{FileReader: fr}, nil
+	}
+	return ipc.NewReader(r)
+}
+
+// openArrowInput opens an Arrow IPC file or stream. Streams are read as they
+// arrive, piped files are buffered in memory since they are read from the footer.
+func openArrowInput(fn string, stdin io.Reader) (*arrowRecords, error) {
+	if stdin != nil {
+		br := bufio.NewReader(stdin)
+		head, _ := br.Peek(len(arrowMagic))
+		rr, err := openArrowRecords(head, br, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &arrowRecords{records: rr}, nil
+	}
+
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	head := make([]byte, len(arrowMagic))
+	n, _ := f.ReadAt(head, 0)
+	cf := &countingFile{File: f}
+	rr, err := openArrowRecords(head[:n], cf, cf)
+	if err != nil {
+		f.Close()
+		return nil, errors.New(fn + ": " + err.Error())
+	}
+	return &arrowRecords{records: rr, file: cf, size: info.Size()}, nil
+}
+
+// advance reports the bytes read since the last batch, pipes count rows
+func (ar *arrowRecords) advance() func(rows int64) int64 {
+	if ar.file == nil {
+		return nil
+	}
+	return func(int64) int64 {
+		n := ar.file.read - ar.last
+		ar.last = ar.file.read
+		return n
+	}
+}
+
+// close releases the record reader and the file
+func (ar *arrowRecords) close() {
+	ar.records.Release()
+	if ar.file != nil {
+		ar.file.Close()
+	}
+}
+
+// load Arrow IPC file to buffer (async version for progressive rendering)
+func loadArrowFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+	ar, err := openArrowInput(fn, nil)
+	if err != nil {
+		doneChan <- err
+		return
+	}
+	defer ar.close()
+	loadArrowRecordsAsync(ar.records, ar.size, ar.advance(), b, updateChan, doneChan)
+}
+
+// load Arrow IPC file to buffer (synchronous version)
+func loadArrowFileToBuffer(fn string, b *Buffer) error {
+	ar, err := openArrowInput(fn, nil)
+	if err != nil {
+		return err
+	}
+	defer ar.close()
+	return loadArrowRecordsSync(ar.records, ar.size, ar.advance(), b)
+}
+
+// load Arrow IPC data from console pipe to buffer (async version)
+func loadArrowPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+	ar, err := openArrowInput("", stdin)
+	if err != nil {
+		doneChan <- err
+		return
+	}
+	defer ar.close()
+	loadArrowRecordsAsync(ar.records, 0, nil, b, updateChan, doneChan)
+}
+
+// load Arrow IPC data from console pipe to buffer (synchronous version)
+func loadArrowPipeToBuffer(stdin io.Reader, b *Buffer) error {
+	ar, err := openArrowInput("", stdin)
+	if err != nil {
+		return err
+	}
+	defer ar.close()
+	return loadArrowRecordsSync(ar.records, 0, nil, b)
+}
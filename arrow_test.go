@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// writeTestArrow writes two record batches with a dictionary-encoded column
+// in the Arrow IPC file format (stream=false) or stream format
+func writeTestArrow(t *testing.T, stream bool) []byte {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "city", Type: &arrow.DictionaryType{IndexType: arrow.PrimitiveTypes.Int8, ValueType: arrow.BinaryTypes.String}, Nullable: true},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32},
+	}, nil)
+
+	var buf bytes.Buffer
+	var w interface {
+		Write(arrow.RecordBatch) error
+		Close() error
+	}
+	if stream {
+		w = ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	} else {
+		fw, err := ipc.NewFileWriter(&buf, ipc.WithSchema(schema), ipc.WithLZ4())
+		if err != nil {
+			t.Fatal(err)
+		}
+		w = fw
+	}
+
+	cities := []string{"Oslo", "Lima", "", "Oslo"}
+	bld := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer bld.Release()
+	for batch := 0; batch < 2; batch++ {
+		for i, city := range cities {
+			bld.Field(0).(*array.Int32Builder).Append(int32(batch*len(cities) + i))
+			if city == "" {
+				bld.Field(1).AppendNull()
+			} else {
+				bld.Field(1).(*array.BinaryDictionaryBuilder).AppendString(city)
+			}
+			bld.Field(2).(*array.Date32Builder).Append(arrow.Date32(19800 + i))
+		}
+		rec := bld.NewRecordBatch()
+		if err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+		rec.Release()
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestLoadArrow(t *testing.T) {
+	want := [][]string{
+		{"id", "city", "day"},
+		{"0", "Oslo", "2024-03-18"}, {"1", "Lima", "2024-03-19"}, {"2", "", "2024-03-20"}, {"3", "Oslo", "2024-03-21"},
+		{"4", "Oslo", "2024-03-18"}, {"5", "Lima", "2024-03-19"}, {"6", "", "2024-03-20"}, {"7", "Oslo", "2024-03-21"},
+	}
+	dir := t.TempDir()
+	for _, stream := range []bool{false, true} {
+		data := writeTestArrow(t, stream)
+		fn := filepath.Join(dir, "frame.feather")
+		if err := os.WriteFile(fn, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if format, err := detectFileFormat(fn); err != nil || format != formatArrow {
+			t.Fatalf("stream=%v: detectFileFormat() = %d, %v", stream, format, err)
+		}
+		if format := detectPipeFormat(bufio.NewReader(bytes.NewReader(data))); format != formatArrow {
+			t.Errorf("stream=%v: detectPipeFormat() = %d, want %d", stream, format, formatArrow)
+		}
+
+		loads := map[string]func(*Buffer) error{
+			"file": func(b *Buffer) error { return loadArrowFileToBuffer(fn, b) },
+			"pipe": func(b *Buffer) error { return loadArrowPipeToBuffer(bytes.NewReader(data), b) },
+			"async": func(b *Buffer) error {
+				updateChan := make(chan bool, 10)
+				doneChan := make(chan error, 1)
+				go loadArrowFileToBufferAsync(fn, b, updateChan, doneChan)
+				return <-doneChan
+			},
+		}
+		for name, load := range loads {
+			b := createNewBuffer()
+			if err := load(b); err != nil {
+				t.Fatalf("stream=%v %s: load error = %v", stream, name, err)
+			}
+			b.mu.RLock()
+			if !reflect.DeepEqual(b.cont, want) {
+				t.Errorf("stream=%v %s: buffer = %q, want %q", stream, name, b.cont, want)
+			}
+			for i, typ := range []int{colTypeFloat, colTypeStr, colTypeDate} {
+				if b.colType[i] != typ {
+					t.Errorf("stream=%v %s: column %d type = %d, want %d", stream, name, i, b.colType[i], typ)
+				}
+			}
+			// Dictionary values share one string across rows and batches
+			if !b.internCols[1] || unsafe.StringData(b.cont[1][1]) != unsafe.StringData(b.cont[8][1]) {
+				t.Errorf("stream=%v %s: dictionary column is not interned", stream, name)
+			}
+			b.mu.RUnlock()
+		}
+	}
+}
+
+func TestLoadFeatherV1(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "old.feather")
+	if err := os.WriteFile(fn, []byte("FEA1\x00\x00\x00\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadArrowFileToBuffer(fn, createNewBuffer()); err == nil {
+		t.Error("Feather v1 files should be rejected")
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -61,7 +62,9 @@ func shouldInternColumn(values []string, threshold float64) bool {
 
 // Buffer represents a table data structure with concurrent access support
 type Buffer struct {
-	sep          rune              // Column separator character
+	dialect                        // Column separator, quote and escape characters
+	colStarts    []int             // Column start offsets in fixed-width mode (nil otherwise)
+	pattern      *linePattern      // Regular expression splitting log lines (nil otherwise)
 	cont         [][]string        // Table content (rows x columns)
 	colType      []int             // Column data types (colTypeStr or colTypeFloat)
 	rowLen       int               // Number of rows
@@ -74,6 +77,14 @@ type Buffer struct {
 	internCols   []bool            // Track which columns use interning
 	memoryUsage  int64             // Current estimated memory usage in bytes
 	maxMemory    int64             // Maximum allowed memory in bytes (0 = no limit)
+	index        *lineIndex        // Rows on disk in indexed mode, cont then holds a sample
+	maxRows      int               // Keep at most this many rows below the header (0 = no cap)
+	droppedRows  int               // Number of old rows dropped because of maxRows
+	visCol       []int             // Input columns picked by --columns/--hide-columns, in display order (nil = all)
+	visColDone   bool              // visCol has been resolved from the header row
+	computed     []*computedColumn // Columns computed from expressions, always the last columns
+	pinnedRows   int               // Rows pinned below the data, like pivot totals, left out of sorting, filters and stats
+	progress     LoadProgress      // Progress of loading into this buffer, each tab shows its own
 }
 
 const (
@@ -90,7 +101,7 @@ const (
 // createNewBuffer initializes and returns a new empty Buffer
 func createNewBuffer() *Buffer {
 	return &Buffer{
-		sep:          0,
+		dialect:      defaultDialect,
 		cont:         [][]string{},
 		colType:      []int{},
 		rowLen:       0,
@@ -139,11 +150,17 @@ func (b *Buffer) contAppendSli(s []string, strict bool) error {
 			formatBytes(b.maxMemory) + ", current: " + formatBytes(b.memoryUsage) + ")")
 	}
 
-	// Strict mode: enforce column count
-	if strict && len(s) != b.colLen {
+	// Strict mode: enforce column count, computed columns are added below
+	if strict && len(s) != b.colLen-len(b.computed) {
 		return errors.New("Row " + I2S(b.rowLen+b.rowFreeze) + " lacks some columns")
 	}
 
+	// Computed columns are filled in for rows appended while loading
+	if len(b.computed) > 0 && b.rowLen >= b.rowFreeze {
+		s = b.withComputedUnsafe(s)
+		rowSize = b.estimateRowSize(s)
+	}
+
 	b.cont = append(b.cont, s)
 	b.memoryUsage += rowSize
 
@@ -153,9 +170,104 @@ func (b *Buffer) contAppendSli(s []string, strict bool) error {
 	}
 	b.rowLen++
 
+	// Drop the oldest row once the row cap is exceeded
+	if b.maxRows > 0 && b.rowLen-b.rowFreeze > b.maxRows {
+		b.dropOldestRowUnsafe()
+	}
+
 	return nil
 }
 
+// dropOldestRowUnsafe removes the first row below the frozen header. The
+// header moves up into the freed slot and the slice start advances, so no
+// rows are copied (caller holds the lock).
+func (b *Buffer) dropOldestRowUnsafe() {
+	keep := b.rowFreeze
+	if keep >= b.rowLen {
+		return
+	}
+	b.memoryUsage -= b.estimateRowSize(b.cont[keep])
+	for i := keep; i > 0; i-- {
+		b.cont[i] = b.cont[i-1]
+	}
+	b.cont[0] = nil
+	b.cont = b.cont[1:]
+	b.rowLen--
+	b.droppedRows++
+}
+
+// rowCount returns the number of rows to display, which in indexed mode is
+// the number of rows on disk rather than the sample in cont
+func (b *Buffer) rowCount() int {
+	if b.index != nil {
+		return b.index.rowCount()
+	}
+	return b.rowLen
+}
+
+// dataRowCount returns rowCount without the pinned rows, loops over the data
+// stop there so totals are not counted as data
+func (b *Buffer) dataRowCount() int {
+	return b.rowCount() - b.pinnedRows
+}
+
+// rowAt returns row r, read through the line index in indexed mode. Callers
+// hold the read lock.
+func (b *Buffer) rowAt(r int) []string {
+	if b.index != nil {
+		return b.index.row(r)
+	}
+	return b.cont[r]
+}
+
+// close releases the file an indexed buffer reads its rows from
+func (b *Buffer) close() {
+	if b.index != nil {
+		b.index.Close()
+	}
+}
+
+// appendedRows returns the number of rows added so far, including rows that
+// were dropped again because of the row cap
+func (b *Buffer) appendedRows() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.rowLen + b.droppedRows
+}
+
+// appendColumn adds a column after the last one, the first row gets header
+// and every other row gets fill (used when the schema grows while loading).
+// Computed columns stay last, the new column goes before them.
+func (b *Buffer) appendColumn(header string, fill string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.appendColumnUnsafe(header, fill)
+}
+
+// appendColumnUnsafe is appendColumn for callers holding the lock
+func (b *Buffer) appendColumnUnsafe(header string, fill string) {
+	at := b.colLen - len(b.computed)
+	for i := range b.cont {
+		value := fill
+		if i == 0 {
+			value = header
+		}
+		b.cont[i] = slices.Insert(b.cont[i], min(at, len(b.cont[i])), value)
+		b.memoryUsage += int64(len(value)) + stringOverheadBytes
+	}
+	b.colLen++
+
+	// colType keeps one spare slot past the last column
+	for len(b.colType) < b.colLen+1 {
+		b.colType = append(b.colType, colTypeStr)
+	}
+	copy(b.colType[at+1:b.colLen], b.colType[at:b.colLen-1])
+	b.colType[at] = colTypeStr
+	for _, cc := range b.computed {
+		cc.root.remap(at)
+	}
+}
+
 // estimateRowSize estimates memory usage for a row in bytes
 func (b *Buffer) estimateRowSize(row []string) int64 {
 	size := int64(len(row) * 8) // Slice overhead (pointers)
@@ -269,40 +381,29 @@ func (b *Buffer) resizeCol(n int) {
 // colIndex: column to sort by
 // rev: true for descending, false for ascending
 func (b *Buffer) sortByStr(colIndex int, rev bool) {
-	hasHeader := I2B(b.rowFreeze)
-
+	dataRows := b.dataRows()
 	if rev {
 		// Descending sort
-		if hasHeader {
-			sort.SliceStable(b.cont[1:], func(i, j int) bool {
-				return b.cont[1:][i][colIndex] > b.cont[1:][j][colIndex]
-			})
-		} else {
-			sort.SliceStable(b.cont, func(i, j int) bool {
-				return b.cont[i][colIndex] > b.cont[j][colIndex]
-			})
-		}
+		sort.SliceStable(dataRows, func(i, j int) bool {
+			return dataRows[i][colIndex] > dataRows[j][colIndex]
+		})
 	} else {
 		// Ascending sort
-		if hasHeader {
-			sort.SliceStable(b.cont[1:], func(i, j int) bool {
-				return b.cont[1:][i][colIndex] < b.cont[1:][j][colIndex]
-			})
-		} else {
-			sort.SliceStable(b.cont, func(i, j int) bool {
-				return b.cont[i][colIndex] < b.cont[j][colIndex]
-			})
-		}
+		sort.SliceStable(dataRows, func(i, j int) bool {
+			return dataRows[i][colIndex] < dataRows[j][colIndex]
+		})
 	}
 }
 
+// dataRows returns the rows sorting reorders, those below the header and
+// above the pinned rows
+func (b *Buffer) dataRows() [][]string {
+	return b.cont[b.rowFreeze : b.rowLen-b.pinnedRows]
+}
+
 // sortByNum sorts column by number format with optimized numeric conversion
 func (b *Buffer) sortByNum(colIndex int, rev bool) {
-	hasHeader := I2B(b.rowFreeze)
-	dataRows := b.cont
-	if hasHeader {
-		dataRows = b.cont[1:]
-	}
+	dataRows := b.dataRows()
 
 	// Create index-value pairs to sort
 	type numRow struct {
@@ -337,11 +438,7 @@ func (b *Buffer) sortByNum(colIndex int, rev bool) {
 
 // sortByDate sorts column by date format with optimized date parsing
 func (b *Buffer) sortByDate(colIndex int, rev bool) {
-	hasHeader := I2B(b.rowFreeze)
-	dataRows := b.cont
-	if hasHeader {
-		dataRows = b.cont[1:]
-	}
+	dataRows := b.dataRows()
 
 	// Create index-value pairs to sort
 	type dateRow struct {
@@ -393,6 +490,15 @@ func parseNumericValueFast(s string) float64 {
 	return val
 }
 
+// isMissing reports whether a cell holds no value
+func isMissing(s string) bool {
+	switch strings.TrimSpace(s) {
+	case "", "NA", "N/A", "NaN", "null":
+		return true
+	}
+	return false
+}
+
 // parseDateValueFast quickly parses a date string to unix timestamp
 // Returns 0 for invalid dates with fast pre-checks
 func parseDateValueFast(s string) int64 {
@@ -470,6 +576,9 @@ func (b *Buffer) setColType(i int, t int) {
 
 // get ith column data type
 func (b *Buffer) getColType(i int) int {
+	if i < 0 || i >= len(b.colType) {
+		return colTypeStr // e.g. an empty sheet has no columns yet
+	}
 	return b.colType[i]
 }
 
@@ -485,7 +594,7 @@ func (b *Buffer) autoDetectColumnType(colIndex int) int {
 
 	// Sample size for type detection
 	startRow := b.rowFreeze
-	endRow := b.rowLen
+	endRow := b.rowLen - b.pinnedRows
 
 	// For large datasets, sample smartly (first N rows + some middle + last N)
 	sampleSize := 100
@@ -699,14 +808,13 @@ func (b *Buffer) enableStringInterning() {
 		return // Too small to benefit
 	}
 
-	// Initialize interning structures
-	b.interners = make([]*stringInterner, b.colLen)
-	b.internCols = make([]bool, b.colLen)
+	// Initialize interning structures, keeping columns interned while loading
+	b.growInterners()
 
 	// Analyze each column
 	for col := 0; col < b.colLen; col++ {
-		// Skip non-string columns
-		if b.colType[col] != colTypeStr {
+		// Skip non-string and already interned columns
+		if b.colType[col] != colTypeStr || b.internCols[col] {
 			continue
 		}
 
@@ -733,6 +841,26 @@ func (b *Buffer) enableStringInterning() {
 	}
 }
 
+// growInterners sizes the interning structures to the column count
+func (b *Buffer) growInterners() {
+	for len(b.interners) < b.colLen {
+		b.interners = append(b.interners, nil)
+		b.internCols = append(b.internCols, false)
+	}
+}
+
+// internColumn enables interning for a column known to be categorical, like
+// a dictionary-encoded Arrow column, before its values are added
+func (b *Buffer) internColumn(col int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.growInterners()
+	if b.interners[col] == nil {
+		b.interners[col] = newStringInterner()
+	}
+	b.internCols[col] = true
+}
+
 // internValue interns a string value for a specific column if interning is enabled
 func (b *Buffer) internValue(col int, value string) string {
 	if col < len(b.internCols) && b.internCols[col] && b.interners[col] != nil {
@@ -788,16 +916,32 @@ type FilterOptions struct {
 	Query         string
 	Operator      string
 	CaseSensitive bool
+	// Rows too short to have the column are compared as empty instead of
+	// skipped, drill downs match them like groupBuffer keys them
+	MissingAsEmpty bool
 }
 
 // filterByColumn filters rows based on column value using the provided options.
 // It returns a new buffer containing the filtered rows.
 func (b *Buffer) filterByColumn(colIndex int, options FilterOptions) *Buffer {
+	// Get column type for numeric comparisons
+	colType := b.getColType(colIndex)
+	return b.filterRows(func(row []string) bool {
+		if colIndex >= len(row) {
+			return options.MissingAsEmpty && evaluateFilter("", options, colType)
+		}
+		return evaluateFilter(row[colIndex], options, colType)
+	})
+}
+
+// filterRows returns a new buffer with the header row and the data rows keep
+// accepts
+func (b *Buffer) filterRows(keep func(row []string) bool) *Buffer {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
 	filtered := createNewBuffer()
-	filtered.sep = b.sep
+	filtered.dialect = b.dialect
 	filtered.colLen = b.colLen
 	filtered.rowFreeze = b.rowFreeze
 	filtered.colFreeze = b.colFreeze
@@ -817,28 +961,9 @@ func (b *Buffer) filterByColumn(colIndex int, options FilterOptions) *Buffer {
 		filtered.rowLen = 1
 	}
 
-	// Early exit if column index is invalid - but still return buffer with header
-	if colIndex >= b.colLen {
-		return filtered
-	}
-
-	// Get column type for numeric comparisons
-	colType := colTypeStr
-	if colIndex < len(b.colType) {
-		colType = b.colType[colIndex]
-	}
-
-	// Filter data rows
-	startRow := b.rowFreeze
-	for i := startRow; i < b.rowLen; i++ {
-		if colIndex >= len(b.cont[i]) {
-			continue
-		}
-
-		cellValue := b.cont[i][colIndex]
-
-		// Evaluate filter condition
-		if evaluateFilter(cellValue, options, colType) {
+	// Filter data rows, pinned rows like totals would not add up any more
+	for i := b.rowFreeze; i < b.rowLen-b.pinnedRows; i++ {
+		if keep(b.cont[i]) {
 			filtered.cont = append(filtered.cont, b.cont[i])
 			filtered.rowLen++
 		}
@@ -5,6 +5,20 @@ import (
 	"testing"
 )
 
+// newTestBuffer builds a buffer with rows[0] as its header and types as the
+// types of the first columns, without replacing the global b
+func newTestBuffer(t *testing.T, rows [][]string, types ...int) *Buffer {
+	t.Helper()
+	nb := createNewBuffer()
+	for _, row := range rows {
+		if err := nb.contAppendSli(row, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	copy(nb.colType, types)
+	return nb
+}
+
 // ========================================
 // Buffer Creation Tests
 // ========================================
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/hex"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// Layout for timestamp cells, parseDateValueFast accepts the optional fraction
+const arrowTimestampLayout = "2006-01-02 15:04:05.999999999"
+
+// arrowColType maps an Arrow data type onto a column type
+func arrowColType(dt arrow.DataType) int {
+	switch dt.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64,
+		arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64,
+		arrow.FLOAT16, arrow.FLOAT32, arrow.FLOAT64,
+		arrow.DECIMAL128, arrow.DECIMAL256:
+		return colTypeFloat
+	case arrow.DATE32, arrow.DATE64, arrow.TIMESTAMP:
+		return colTypeDate
+	case arrow.DICTIONARY:
+		return arrowColType(dt.(*arrow.DictionaryType).ValueType)
+	default:
+		return colTypeStr
+	}
+}
+
+// arrowValueString renders the ith value of an Arrow array as cell text
+func arrowValueString(arr arrow.Array, i int) string {
+	if arr.IsNull(i) {
+		return ""
+	}
+	switch a := arr.(type) {
+	case *array.String:
+		return a.Value(i)
+	case *array.LargeString:
+		return a.Value(i)
+	case *array.Float32:
+		return strconv.FormatFloat(float64(a.Value(i)), 'f', -1, 32)
+	case *array.Float64:
+		return strconv.FormatFloat(a.Value(i), 'f', -1, 64)
+	case *array.Timestamp:
+		unit := a.DataType().(*arrow.TimestampType).Unit
+		return a.Value(i).ToTime(unit).UTC().Format(arrowTimestampLayout)
+	case *array.Date32:
+		return a.Value(i).ToTime().UTC().Format(time.DateOnly)
+	case *array.Date64:
+		return a.Value(i).ToTime().UTC().Format(time.DateOnly)
+	case *array.Binary:
+		return bytesString(a.Value(i))
+	case *array.Dictionary:
+		return arrowValueString(a.Dictionary(), a.GetValueIndex(i))
+	default:
+		return arr.ValueStr(i)
+	}
+}
+
+// bytesString shows binary values as text when they are valid UTF-8, hex otherwise
+func bytesString(v []byte) string {
+	if utf8.Valid(v) {
+		return string(v)
+	}
+	return hex.EncodeToString(v)
+}
+
+// arrowLoader appends Arrow record batches to a buffer
+type arrowLoader struct {
+	b      *Buffer
+	schema *arrow.Schema
+	visCol []int // schema field indices that are displayed
+	rows   int   // rows added, including the header
+}
+
+// newArrowLoader creates a loader for schema, honouring the column selection
+func newArrowLoader(b *Buffer, schema *arrow.Schema) (*arrowLoader, error) {
+	names := make([]string, schema.NumFields())
+	for i, f := range schema.Fields() {
+		names[i] = f.Name
+	}
+	visCol, err := selectColumns(names)
+	if err != nil {
+		return nil, err
+	}
+	return &arrowLoader{b: b, schema: schema, visCol: visCol}, nil
+}
+
+// addHeader appends the field names and pre-sets the column types from the schema
+func (al *arrowLoader) addHeader() error {
+	header := make([]string, len(al.visCol))
+	for i, f := range al.visCol {
+		header[i] = al.schema.Field(f).Name
+	}
+	if err := al.b.contAppendSli(header, false); err != nil {
+		return err
+	}
+	for i, f := range al.visCol {
+		dt := al.schema.Field(f).Type
+		al.b.setColType(i, arrowColType(dt))
+		// Dictionary encoding marks a column as categorical, no need to sample it
+		if dt.ID() == arrow.DICTIONARY && arrowColType(dt) == colTypeStr {
+			al.b.internColumn(i)
+		}
+	}
+	al.rows++
+	return nil
+}
+
+// addRecord appends the rows of one record batch, it returns false once the
+// --lines limit is reached
+func (al *arrowLoader) addRecord(rec arrow.RecordBatch) (bool, error) {
+	cols := make([]arrow.Array, len(al.visCol))
+	dicts := make([][]string, len(al.visCol)) // rendered dictionary values
+	for i, f := range al.visCol {
+		cols[i] = rec.Column(f)
+		if d, ok := cols[i].(*array.Dictionary); ok {
+			dicts[i] = make([]string, d.Dictionary().Len())
+			for k := range dicts[i] {
+				dicts[i][k] = al.b.internValue(i, arrowValueString(d.Dictionary(), k))
+			}
+		}
+	}
+	for r := 0; r < int(rec.NumRows()); r++ {
+		if al.rows >= args.NLine && args.NLine > 0 {
+			return false, nil
+		}
+		row := make([]string, len(cols))
+		for c, col := range cols {
+			if dicts[c] != nil {
+				if !col.IsNull(r) {
+					row[c] = dicts[c][col.(*array.Dictionary).GetValueIndex(r)]
+				}
+				continue
+			}
+			row[c] = arrowValueString(col, r)
+		}
+		if err := al.b.contAppendSli(row, args.Strict); err != nil {
+			return false, err
+		}
+		al.rows++
+	}
+	return true, nil
+}
+
+// loadArrowRecords reads every batch of rr into b, onBatch (optional) is
+// called after each batch with the number of data rows it held
+func loadArrowRecords(rr array.RecordReader, b *Buffer, onBatch func(rows int64)) error {
+	al, err := newArrowLoader(b, rr.Schema())
+	if err != nil {
+		return err
+	}
+	if err := al.addHeader(); err != nil {
+		return err
+	}
+	for rr.Next() {
+		rec := rr.RecordBatch()
+		more, err := al.addRecord(rec)
+		if err != nil {
+			return err
+		}
+		if onBatch != nil {
+			onBatch(rec.NumRows())
+		}
+		if !more {
+			return nil
+		}
+	}
+	return rr.Err()
+}
+
+// loadArrowRecordsAsync streams batches into b following the updateChan/doneChan
+// contract of the delimited loaders. Progress counts rows out of total, or
+// what advance (optional) returns for each batch, like bytes read.
+func loadArrowRecordsAsync(rr array.RecordReader, total int64, advance func(rows int64) int64, b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+	b.progress.TotalBytes = total
+	b.progress.LoadedBytes = 0
+	b.progress.CompressedBytes = nil
+	b.progress.IsComplete = false
+
+	initialSent := false
+	err := loadArrowRecords(rr, b, func(rows int64) {
+		if advance != nil {
+			b.progress.LoadedBytes += advance(rows)
+		} else {
+			b.progress.LoadedBytes += rows
+		}
+		if !initialSent {
+			// Signal that initial data is ready for rendering
+			updateChan <- true
+			initialSent = true
+			return
+		}
+		select {
+		case updateChan <- true:
+		default:
+			// Non-blocking - skip update if channel is full
+		}
+	})
+	if err != nil {
+		doneChan <- err
+		return
+	}
+	if !initialSent {
+		updateChan <- true
+	}
+
+	b.progress.IsComplete = true
+	// Column types come from the schema, only interning is left to do
+	go b.enableStringInterning()
+	doneChan <- nil
+}
+
+// loadArrowRecordsSync reads all batches into b, progress is counted like
+// loadArrowRecordsAsync does
+func loadArrowRecordsSync(rr array.RecordReader, total int64, advance func(rows int64) int64, b *Buffer) error {
+	progress := newProgressTracker(total, true)
+	err := loadArrowRecords(rr, b, func(rows int64) {
+		// increment counts one line, add the rest of the batch
+		progress.lineCount += int(rows) - 1
+		if advance != nil {
+			progress.increment(advance(rows))
+		} else {
+			progress.increment(rows)
+		}
+	})
+	progress.finish()
+	if err != nil {
+		return err
+	}
+	b.enableStringInterning()
+	return nil
+}
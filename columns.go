@@ -0,0 +1,260 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// prefix of --columns entries that are regular expressions
+const columnRegexPrefix = "re:"
+
+// columnSpec is one entry of --columns or --hide-columns: a column number, a
+// range of numbers, a header name or a regular expression, "!" negates it
+type columnSpec struct {
+	negate   bool
+	from, to int // 1-based inclusive range, 0 for an open end, both 0 otherwise
+	isRange  bool
+	name     string
+	re       *regexp.Regexp
+}
+
+// parseColumnSpec parses one list entry, numbers and ranges win over header
+// names that look the same (use re:^2024$ for those)
+func parseColumnSpec(text string) (columnSpec, error) {
+	var spec columnSpec
+	s := strings.TrimSpace(text)
+	if strings.HasPrefix(s, "!") {
+		spec.negate = true
+		s = s[1:]
+	}
+	if s == "" {
+		return spec, errors.New("empty column in " + strconv.Quote(text))
+	}
+	if strings.HasPrefix(s, columnRegexPrefix) {
+		re, err := regexp.Compile(s[len(columnRegexPrefix):])
+		if err != nil {
+			return spec, errors.New("invalid column pattern " + strconv.Quote(text) + ": " + err.Error())
+		}
+		spec.re = re
+		return spec, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && isDigits(s) {
+		if n == 0 {
+			return spec, errors.New("Column number " + I2S(n) + " does not exist")
+		}
+		spec.from, spec.to, spec.isRange = n, n, true
+		return spec, nil
+	}
+	if from, to, ok := strings.Cut(s, "-"); ok && s != "-" && isDigits(from) && isDigits(to) {
+		spec.isRange = true
+		spec.from, _ = strconv.Atoi(from)
+		spec.to, _ = strconv.Atoi(to)
+		if (from != "" && spec.from == 0) || (to != "" && spec.to == 0) || (spec.to > 0 && spec.from > spec.to) {
+			return spec, errors.New("invalid column range " + strconv.Quote(text))
+		}
+		return spec, nil
+	}
+	spec.name = s
+	return spec, nil
+}
+
+// isDigits checks that s holds only ASCII digits, it is true for ""
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// matches checks whether column i (0-based) with the given header name is
+// picked by the entry, ignoring negation
+func (spec columnSpec) matches(i int, name string) bool {
+	switch {
+	case spec.re != nil:
+		return spec.re.MatchString(name)
+	case spec.isRange:
+		return i+1 >= max(spec.from, 1) && (spec.to == 0 || i+1 <= spec.to)
+	}
+	return name == spec.name
+}
+
+// check makes sure numbers and names refer to columns of header, patterns
+// may match nothing
+func (spec columnSpec) check(header []string) error {
+	switch {
+	case spec.re != nil:
+		return nil
+	case spec.isRange:
+		for _, n := range []int{spec.from, spec.to} {
+			if n > len(header) {
+				return errors.New("Column number " + I2S(n) + " does not exist")
+			}
+		}
+		return nil
+	}
+	for _, name := range header {
+		if name == spec.name {
+			return nil
+		}
+	}
+	return errors.New("Column " + strconv.Quote(spec.name) + " is not in the header")
+}
+
+// columnSelection is the parsed form of --columns and --hide-columns
+type columnSelection struct {
+	show []columnSpec
+	hide []columnSpec
+}
+
+// newColumnSelection parses the entries of the show and hide lists
+func newColumnSelection(show, hide []string) (*columnSelection, error) {
+	cs := &columnSelection{}
+	for _, text := range show {
+		spec, err := parseColumnSpec(text)
+		if err != nil {
+			return nil, err
+		}
+		cs.show = append(cs.show, spec)
+	}
+	for _, text := range hide {
+		spec, err := parseColumnSpec(text)
+		if err != nil {
+			return nil, err
+		}
+		cs.hide = append(cs.hide, spec)
+	}
+	return cs, nil
+}
+
+// columnSelectionFromArgs parses --columns and --hide-columns, it returns nil
+// when neither is set
+func columnSelectionFromArgs() (*columnSelection, error) {
+	if len(args.Columns) == 0 && len(args.HideColumns) == 0 {
+		return nil, nil
+	}
+	return newColumnSelection(args.Columns, args.HideColumns)
+}
+
+// picksColumns reports whether --columns names any columns, a list of
+// negations only starts from all columns
+func (cs *columnSelection) picksColumns() bool {
+	for _, spec := range cs.show {
+		if !spec.negate {
+			return true
+		}
+	}
+	return false
+}
+
+// visible decides whether column i with the given header name is shown.
+// Entries are applied in order, so a later entry overrides an earlier one.
+func (cs *columnSelection) visible(i int, name string) bool {
+	shown := !cs.picksColumns()
+	for _, spec := range cs.show {
+		if spec.matches(i, name) {
+			shown = !spec.negate
+		}
+	}
+	hidden := false
+	for _, spec := range cs.hide {
+		if spec.matches(i, name) {
+			hidden = !spec.negate
+		}
+	}
+	return shown && !hidden
+}
+
+// resolve returns the header indices to display. Columns come in the order
+// --columns lists them, entries matching several columns keep header order.
+func (cs *columnSelection) resolve(header []string) ([]int, error) {
+	for _, spec := range append(append([]columnSpec{}, cs.show...), cs.hide...) {
+		if err := spec.check(header); err != nil {
+			return nil, err
+		}
+	}
+
+	var visCol []int
+	added := make([]bool, len(header))
+	add := func(i int) {
+		if !added[i] && cs.visible(i, header[i]) {
+			added[i] = true
+			visCol = append(visCol, i)
+		}
+	}
+	if cs.picksColumns() {
+		for _, spec := range cs.show {
+			if spec.negate {
+				continue
+			}
+			for i, name := range header {
+				if spec.matches(i, name) {
+					add(i)
+				}
+			}
+		}
+	} else {
+		for i := range header {
+			add(i)
+		}
+	}
+	if len(visCol) == 0 {
+		return nil, errors.New("the column selection leaves no columns to show")
+	}
+	return visCol, nil
+}
+
+// selectColumns returns the indices of header to display, all of them when
+// no columns are selected
+func selectColumns(header []string) ([]int, error) {
+	cs, err := columnSelectionFromArgs()
+	if err != nil {
+		return nil, err
+	}
+	if cs == nil {
+		visCol := make([]int, len(header))
+		for i := range visCol {
+			visCol[i] = i
+		}
+		return visCol, nil
+	}
+	return cs.resolve(header)
+}
+
+// pickColumns returns the fields at visCol, cells missing from short rows
+// are "NaN" like in the regular loaders
+func pickColumns(fields []string, visCol []int) []string {
+	row := make([]string, len(visCol))
+	for i, src := range visCol {
+		if src < len(fields) {
+			row[i] = fields[src]
+		} else {
+			row[i] = "NaN"
+		}
+	}
+	return row
+}
+
+// visibleFields applies the column selection to a row. The selection is
+// resolved against the first row, the header, and kept for the rest.
+func (b *Buffer) visibleFields(fields []string) ([]string, error) {
+	if !b.visColDone {
+		cs, err := columnSelectionFromArgs()
+		if err != nil {
+			return nil, err
+		}
+		if cs != nil {
+			if b.visCol, err = cs.resolve(fields); err != nil {
+				return nil, err
+			}
+		}
+		b.visColDone = true
+	}
+	if b.visCol == nil {
+		return fields, nil
+	}
+	return pickColumns(fields, b.visCol), nil
+}
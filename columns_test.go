@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestColumnSelectionResolve(t *testing.T) {
+	header := []string{"id", "name", "qc_depth", "qc_score", "city", "name"}
+	tests := []struct {
+		name    string
+		show    []string
+		hide    []string
+		want    []int
+		wantErr bool
+	}{
+		{"Show numbers", []string{"1", "2", "5"}, nil, []int{0, 1, 4}, false},
+		{"Hide numbers", nil, []string{"1", "2", "5"}, []int{2, 3, 5}, false},
+		{"Show then hide", []string{"1", "2", "3"}, []string{"2"}, []int{0, 2}, false},
+		{"Show column out of range", []string{"1", "7"}, nil, nil, true},
+		{"Hide column out of range", nil, []string{"7"}, nil, true},
+		{"Column zero", []string{"0"}, nil, nil, true},
+		{"Names keep the given order", []string{"city", "id"}, nil, []int{4, 0}, false},
+		{"Duplicate names pick every match", []string{"name"}, nil, []int{1, 5}, false},
+		{"Missing name", []string{"zip"}, nil, nil, true},
+		{"Range", []string{"2-4"}, nil, []int{1, 2, 3}, false},
+		{"Open ranges", []string{"5-", "-2"}, nil, []int{4, 5, 0, 1}, false},
+		{"Range beyond the header", []string{"3-9"}, nil, nil, true},
+		{"Backwards range", []string{"4-2"}, nil, nil, true},
+		{"Pattern", []string{"re:^qc_"}, nil, []int{2, 3}, false},
+		{"Pattern matching nothing", []string{"id", "re:^zz"}, nil, []int{0}, false},
+		{"Negation only starts from all", []string{"!re:^qc_", "!6"}, nil, []int{0, 1, 4}, false},
+		{"Negation after a pick", []string{"re:^qc_|city", "!qc_score"}, nil, []int{2, 4}, false},
+		{"Hide exception", nil, []string{"re:^qc_", "!qc_score"}, []int{0, 1, 3, 4, 5}, false},
+		{"Reorder and pick the rest", []string{"city", "1-"}, nil, []int{4, 0, 1, 2, 3, 5}, false},
+		{"Nothing left", []string{"1"}, []string{"id"}, nil, true},
+		{"Invalid pattern", []string{"re:("}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs, err := newColumnSelection(tt.show, tt.hide)
+			var got []int
+			if err == nil {
+				got, err = cs.resolve(header)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnSelectionVisible(t *testing.T) {
+	cs, err := newColumnSelection([]string{"re:^user\\.", "!user.email", "1"}, []string{"user.id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		i    int
+		name string
+		want bool
+	}{
+		{0, "ts", true},
+		{1, "user.name", true},
+		{2, "user.email", false},
+		{3, "user.id", false},
+		{4, "level", false},
+	}
+	for _, tt := range tests {
+		if got := cs.visible(tt.i, tt.name); got != tt.want {
+			t.Errorf("visible(%d, %q) = %v, want %v", tt.i, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadWithColumnNames(t *testing.T) {
+	defer args.setDefault()
+	args.Columns = []string{"score", "name"}
+	b := createNewBuffer()
+	b.sep = ","
+	data := "id,name,score\n1,ann,90\n2,bob\n"
+	if err := loadPipeToBuffer(strings.NewReader(data), b); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"score", "name"}, {"90", "ann"}, {"NaN", "bob"}}
+	if !reflect.DeepEqual(b.cont, want) {
+		t.Errorf("buffer = %q, want %q", b.cont, want)
+	}
+
+	args.Columns = []string{"re:^user"}
+	args.HideColumns = []string{"user.id"}
+	b = createNewBuffer()
+	if err := loadJSONLPipeToBuffer(strings.NewReader(`{"ts":1,"user":{"id":7,"name":"ann"}}`+"\n"), b); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"user.name"}, {"ann"}}; !reflect.DeepEqual(b.cont, want) {
+		t.Errorf("JSON Lines buffer = %q, want %q", b.cont, want)
+	}
+}
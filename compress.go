@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compression formats, recognised by their magic bytes
+const (
+	compressNone = iota
+	compressGzip
+	compressBzip2
+	compressXz
+	compressZstd
+)
+
+// number of leading bytes needed to recognise every compression format
+const compressMagicLen = 6
+
+// detectCompression recognises a compressed stream by its leading bytes
+func detectCompression(head []byte) int {
+	switch {
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return compressGzip
+	case bytes.HasPrefix(head, []byte("BZh")):
+		return compressBzip2
+	case bytes.HasPrefix(head, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		return compressXz
+	case bytes.HasPrefix(head, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return compressZstd
+	}
+	return compressNone
+}
+
+// plainFileName strips a compression suffix, so "data.csv.zst" is seen as a csv file
+func plainFileName(fn string) string {
+	for _, ext := range []string{".gz", ".bz2", ".xz", ".zst"} {
+		if strings.HasSuffix(strings.ToLower(fn), ext) {
+			return fn[:len(fn)-len(ext)]
+		}
+	}
+	return fn
+}
+
+// countingReader counts the bytes read through it, the count may be read
+// from another goroutine
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n.Add(int64(n))
+	return n, err
+}
+
+// decompressedReader is the decompressed content of a stream, raw counts the
+// compressed bytes consumed so far
+type decompressedReader struct {
+	io.Reader
+	raw   *countingReader
+	close func()
+}
+
+// Close releases the decoder, the underlying stream is left open
+func (dr *decompressedReader) Close() error {
+	if dr.close != nil {
+		dr.close()
+	}
+	return nil
+}
+
+// newDecompressor wraps r with a decoder for the given compression format
+func newDecompressor(kind int, r io.Reader) (*decompressedReader, error) {
+	dr := &decompressedReader{raw: &countingReader{r: r}}
+	switch kind {
+	case compressGzip:
+		gz, err := gzip.NewReader(dr.raw)
+		if err != nil {
+			return nil, err
+		}
+		dr.Reader = gz
+	case compressBzip2:
+		dr.Reader = bzip2.NewReader(dr.raw)
+	case compressXz:
+		xr, err := xz.NewReader(dr.raw)
+		if err != nil {
+			return nil, err
+		}
+		dr.Reader = xr
+	case compressZstd:
+		zr, err := zstd.NewReader(dr.raw)
+		if err != nil {
+			return nil, err
+		}
+		dr.Reader = zr
+		dr.close = zr.Close
+	default:
+		dr.Reader = dr.raw
+	}
+	return dr, nil
+}
+
+// decompressPipe returns a reader over the decompressed content of a pipe
+// when it starts with a known compression magic, or r itself otherwise
+func decompressPipe(r *bufio.Reader) (*bufio.Reader, error) {
+	// Wait for the first bytes, then look only at what is buffered
+	if _, err := r.Peek(1); err != nil {
+		return r, nil
+	}
+	head, _ := r.Peek(compressMagicLen)
+	kind := detectCompression(head)
+	if kind == compressNone {
+		return r, nil
+	}
+	dr, err := newDecompressor(kind, r)
+	if err != nil {
+		return nil, err
+	}
+	return bufio.NewReader(dr), nil
+}
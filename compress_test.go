@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compressTestData compresses data with the given format
+func compressTestData(t *testing.T, kind int, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch kind {
+	case compressGzip:
+		w = gzip.NewWriter(&buf)
+	case compressXz:
+		w, err = xz.NewWriter(&buf)
+	case compressZstd:
+		w, err = zstd.NewWriter(&buf)
+	default:
+		t.Fatalf("cannot compress format %d", kind)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectCompression(t *testing.T) {
+	bz2, err := os.ReadFile("./data/test/numeric_data.csv.bz2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		head []byte
+		want int
+	}{
+		{"gzip", compressTestData(t, compressGzip, []byte("a,b\n")), compressGzip},
+		{"bzip2", bz2, compressBzip2},
+		{"xz", compressTestData(t, compressXz, []byte("a,b\n")), compressXz},
+		{"zstd", compressTestData(t, compressZstd, []byte("a,b\n")), compressZstd},
+		{"plain text", []byte("a,b\n1,2\n"), compressNone},
+		{"empty", nil, compressNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectCompression(tt.head); got != tt.want {
+				t.Errorf("detectCompression() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainFileName(t *testing.T) {
+	tests := map[string]string{
+		"data.csv.gz":  "data.csv",
+		"data.tsv.ZST": "data.tsv",
+		"data.csv.bz2": "data.csv",
+		"logs.jsonl":   "logs.jsonl",
+	}
+	for fn, want := range tests {
+		if got := plainFileName(fn); got != want {
+			t.Errorf("plainFileName(%q) = %q, want %q", fn, got, want)
+		}
+	}
+}
+
+func TestLoadCompressedFileBySniffing(t *testing.T) {
+	plain, err := os.ReadFile("./data/test/numeric_data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := createNewBuffer()
+	if err := loadFileToBuffer("./data/test/numeric_data.csv", want); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"gzip_without_suffix": compressTestData(t, compressGzip, plain),
+		"data.csv.xz":         compressTestData(t, compressXz, plain),
+		"data.csv.zst":        compressTestData(t, compressZstd, plain),
+	}
+	paths := []string{"./data/test/numeric_data.csv.bz2"}
+	for name, data := range files {
+		fn := filepath.Join(dir, name)
+		if err := os.WriteFile(fn, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, fn)
+	}
+
+	for _, fn := range paths {
+		t.Run(filepath.Base(fn), func(t *testing.T) {
+			b := createNewBuffer()
+			updateChan := make(chan bool, 10)
+			doneChan := make(chan error, 1)
+			go loadFileToBufferAsync(fn, b, updateChan, doneChan)
+			if err := <-doneChan; err != nil {
+				t.Fatalf("loadFileToBufferAsync() error = %v", err)
+			}
+			if b.rowLen != want.rowLen || b.colLen != want.colLen {
+				t.Fatalf("Expected %dx%d buffer, got %dx%d", want.rowLen, want.colLen, b.rowLen, b.colLen)
+			}
+			if b.cont[1][1] != want.cont[1][1] {
+				t.Errorf("cell [1][1] = %q, want %q", b.cont[1][1], want.cont[1][1])
+			}
+			if b.progress.CompressedBytes == nil {
+				t.Fatal("Expected progress to count compressed bytes")
+			}
+			if got := b.progress.GetPercentage(); got != 100 {
+				t.Errorf("GetPercentage() = %.1f, want 100", got)
+			}
+		})
+	}
+}
+
+func TestDecompressPipe(t *testing.T) {
+	plain := []byte("name,score\nalice,1\nbob,2\n")
+	for _, kind := range []int{compressGzip, compressXz, compressZstd} {
+		r, err := decompressPipe(bufio.NewReader(bytes.NewReader(compressTestData(t, kind, plain))))
+		if err != nil {
+			t.Fatalf("decompressPipe(%d) error = %v", kind, err)
+		}
+		b := createNewBuffer()
+		if err := loadPipeToBuffer(r, b); err != nil {
+			t.Fatalf("loadPipeToBuffer() error = %v", err)
+		}
+		if b.rowLen != 3 || b.cont[2][0] != "bob" {
+			t.Errorf("format %d: unexpected buffer %v", kind, b.cont)
+		}
+	}
+
+	// Uncompressed input passes through untouched
+	r, err := decompressPipe(bufio.NewReader(bytes.NewReader(plain)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(r)
+	if !bytes.Equal(got, plain) {
+		t.Errorf("decompressPipe() changed plain input: %q", got)
+	}
+}
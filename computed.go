@@ -0,0 +1,600 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/tview"
+)
+
+// computedColumn is a column whose cells are computed from the other cells
+// of their row, like price * qty
+type computedColumn struct {
+	name string
+	text string // the expression as typed
+	root calcNode
+}
+
+// calcNode is a part of a column expression
+type calcNode interface {
+	eval(row []string) string
+	// remap moves references to columns from at on one to the right
+	remap(at int)
+}
+
+type calcLit struct{ value string }
+type calcCol struct{ col int }
+type calcNeg struct{ x calcNode }
+type calcBinary struct {
+	op          string // + - * / % or & to join text
+	left, right calcNode
+}
+type calcCall struct {
+	fn   func(args []string) string
+	args []calcNode
+}
+
+func (n calcLit) eval(row []string) string { return n.value }
+func (n calcLit) remap(at int)             {}
+
+func (n *calcCol) eval(row []string) string {
+	if n.col < len(row) {
+		return row[n.col]
+	}
+	return ""
+}
+
+func (n *calcCol) remap(at int) {
+	if n.col >= at {
+		n.col++
+	}
+}
+
+func (n calcNeg) eval(row []string) string {
+	if x, ok := calcNumber(n.x.eval(row)); ok {
+		return formatComputed(-x)
+	}
+	return ""
+}
+
+func (n calcNeg) remap(at int) { n.x.remap(at) }
+
+func (n calcBinary) eval(row []string) string {
+	l, r := n.left.eval(row), n.right.eval(row)
+	if n.op == "&" {
+		return l + r
+	}
+	x, ok1 := calcNumber(l)
+	y, ok2 := calcNumber(r)
+	if !ok1 || !ok2 {
+		return ""
+	}
+	switch n.op {
+	case "+":
+		return formatComputed(x + y)
+	case "-":
+		return formatComputed(x - y)
+	case "*":
+		return formatComputed(x * y)
+	}
+	// Division by zero leaves the cell empty
+	if y == 0 {
+		return ""
+	}
+	if n.op == "%" {
+		return formatComputed(math.Mod(x, y))
+	}
+	return formatComputed(x / y)
+}
+
+func (n calcBinary) remap(at int) {
+	n.left.remap(at)
+	n.right.remap(at)
+}
+
+func (n calcCall) eval(row []string) string {
+	args := make([]string, len(n.args))
+	for i, a := range n.args {
+		args[i] = a.eval(row)
+	}
+	return n.fn(args)
+}
+
+func (n calcCall) remap(at int) {
+	for _, a := range n.args {
+		a.remap(at)
+	}
+}
+
+// calcNumber parses a cell as a number, missing values and text are not
+func calcNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if isMissing(s) || !isNumericValue(s) {
+		return 0, false
+	}
+	return parseNumericValueFast(s), true
+}
+
+// formatComputed renders a computed number without the noise of binary
+// fractions, 0.1 + 0.2 is 0.3
+func formatComputed(v float64) string {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return ""
+	}
+	v, _ = strconv.ParseFloat(strconv.FormatFloat(v, 'g', 12, 64), 64)
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// calcFunc is a function of column expressions, parse checks the arguments
+// and returns the function evaluating them
+type calcFunc struct {
+	minArgs, maxArgs int // maxArgs is -1 for any number
+	parse            func(p *calcParser, name filterToken, args []calcNode) (func([]string) string, error)
+}
+
+// simpleFunc makes a calcFunc of fn, which takes its arguments as they are
+func simpleFunc(minArgs, maxArgs int, fn func([]string) string) calcFunc {
+	return calcFunc{minArgs, maxArgs, func(*calcParser, filterToken, []calcNode) (func([]string) string, error) {
+		return fn, nil
+	}}
+}
+
+// numberFunc makes a calcFunc of fn over a number, text gives ""
+func numberFunc(fn func(x float64, args []string) string) calcFunc {
+	return calcFunc{1, 2, func(p *calcParser, name filterToken, args []calcNode) (func([]string) string, error) {
+		return func(args []string) string {
+			x, ok := calcNumber(args[0])
+			if !ok {
+				return ""
+			}
+			return fn(x, args[1:])
+		}, nil
+	}}
+}
+
+// dateUnits are the units of date_diff in seconds
+var dateUnits = map[string]float64{"seconds": 1, "minutes": 60, "hours": 3600, "days": 86400, "weeks": 7 * 86400}
+
+// calcFuncs are the functions of column expressions
+var calcFuncs = map[string]calcFunc{
+	"upper": simpleFunc(1, 1, func(a []string) string { return strings.ToUpper(a[0]) }),
+	"lower": simpleFunc(1, 1, func(a []string) string { return strings.ToLower(a[0]) }),
+	"trim":  simpleFunc(1, 1, func(a []string) string { return strings.TrimSpace(a[0]) }),
+	"len":   simpleFunc(1, 1, func(a []string) string { return strconv.Itoa(utf8.RuneCountInString(a[0])) }),
+	"concat": simpleFunc(1, -1, func(a []string) string {
+		return strings.Join(a, "")
+	}),
+	"replace": simpleFunc(3, 3, func(a []string) string { return strings.ReplaceAll(a[0], a[1], a[2]) }),
+	"coalesce": simpleFunc(1, -1, func(a []string) string {
+		for _, s := range a {
+			if !isMissing(s) {
+				return s
+			}
+		}
+		return ""
+	}),
+	"substr": simpleFunc(2, 3, func(a []string) string {
+		// 1-based like SQL, a missing length runs to the end
+		runes := []rune(a[0])
+		start, err := strconv.Atoi(strings.TrimSpace(a[1]))
+		if err != nil || start < 1 || start > len(runes) {
+			return ""
+		}
+		end := len(runes)
+		if len(a) > 2 {
+			n, err := strconv.Atoi(strings.TrimSpace(a[2]))
+			if err != nil || n < 0 {
+				return ""
+			}
+			end = min(end, start-1+n)
+		}
+		return string(runes[start-1 : end])
+	}),
+	"abs": numberFunc(func(x float64, _ []string) string { return formatComputed(math.Abs(x)) }),
+	"round": numberFunc(func(x float64, a []string) string {
+		digits := 0
+		if len(a) > 0 {
+			d, err := strconv.Atoi(strings.TrimSpace(a[0]))
+			if err != nil {
+				return ""
+			}
+			digits = d
+		}
+		scale := math.Pow(10, float64(digits))
+		return formatComputed(math.Round(x*scale) / scale)
+	}),
+	"regex": {2, 3, func(p *calcParser, name filterToken, args []calcNode) (func([]string) string, error) {
+		lit, ok := args[1].(calcLit)
+		if !ok {
+			return nil, tokenError(name, "the pattern of regex is a quoted text, like regex(url, \"https?://([^/]+)\")")
+		}
+		re, err := regexp.Compile(lit.value)
+		if err != nil {
+			return nil, tokenError(name, "invalid regular expression: "+err.Error())
+		}
+		// The first group is the result when the pattern has one, or the given group
+		group := min(re.NumSubexp(), 1)
+		return func(a []string) string {
+			g := group
+			if len(a) > 2 {
+				n, err := strconv.Atoi(strings.TrimSpace(a[2]))
+				if err != nil || n < 0 || n > re.NumSubexp() {
+					return ""
+				}
+				g = n
+			}
+			m := re.FindStringSubmatch(a[0])
+			if m == nil {
+				return ""
+			}
+			return m[g]
+		}, nil
+	}},
+	"date_diff": {2, 3, func(p *calcParser, name filterToken, args []calcNode) (func([]string) string, error) {
+		unit := dateUnits["days"]
+		if len(args) > 2 {
+			lit, ok := args[2].(calcLit)
+			if ok {
+				unit, ok = dateUnits[strings.ToLower(lit.value)]
+			}
+			if !ok {
+				return nil, tokenError(name, "the unit of date_diff is \"days\", \"weeks\", \"hours\", \"minutes\" or \"seconds\"")
+			}
+		}
+		return func(a []string) string {
+			end, start := parseDateValueFast(a[0]), parseDateValueFast(a[1])
+			if end == 0 || start == 0 {
+				return ""
+			}
+			return formatComputed(float64(end-start) / unit)
+		}, nil
+	}},
+}
+
+// calcFuncNames lists the functions for messages
+func calcFuncNames() string {
+	names := make([]string, 0, len(calcFuncs))
+	for name := range calcFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// calcOpChars are the operators of column expressions
+const calcOpChars = "+-*/%&"
+
+// lexCalc splits a column expression into tokens. Words are names, numbers
+// and $N, "text" and 'text' are strings and `name` is a quoted column name.
+func lexCalc(text string) ([]filterToken, error) {
+	var toks []filterToken
+	pos := 1
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		start := pos
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			pos++
+			continue
+		case r == '(' || r == ')' || r == ',':
+			kind := map[rune]int{'(': tokLParen, ')': tokRParen, ',': tokComma}[r]
+			toks = append(toks, filterToken{kind, string(r), start})
+			i++
+			pos++
+			continue
+		case strings.ContainsRune(calcOpChars, r):
+			toks = append(toks, filterToken{tokOp, string(r), start})
+			i++
+			pos++
+			continue
+		case r == '"' || r == '\'' || r == '`':
+			var sb strings.Builder
+			j := i + 1
+			pos++
+			closed := false
+			for j < len(text) {
+				c, n := utf8.DecodeRuneInString(text[j:])
+				j += n
+				pos++
+				if c == r {
+					closed = true
+					break
+				}
+				if c == '\\' && r != '`' && j < len(text) {
+					if next, n := utf8.DecodeRuneInString(text[j:]); next == r || next == '\\' {
+						c = next
+						j += n
+						pos++
+					}
+				}
+				sb.WriteRune(c)
+			}
+			if !closed {
+				return nil, errors.New("position " + strconv.Itoa(start) + ": the quote is never closed")
+			}
+			kind := tokString
+			if r == '`' {
+				kind = tokName
+			}
+			toks = append(toks, filterToken{kind, sb.String(), start})
+			i = j
+			continue
+		}
+		j := i
+		for j < len(text) {
+			c, n := utf8.DecodeRuneInString(text[j:])
+			if unicode.IsSpace(c) || strings.ContainsRune("(),\"'`"+calcOpChars, c) {
+				break
+			}
+			j += n
+			pos++
+		}
+		toks = append(toks, filterToken{tokWord, text[i:j], start})
+		i = j
+	}
+	return append(toks, filterToken{tokEOF, "", pos}), nil
+}
+
+// calcParser builds a column expression from tokens, resolving columns
+// against the header of b
+type calcParser struct {
+	toks []filterToken
+	i    int
+	b    *Buffer
+}
+
+// parseCalcExpr parses a column expression over the columns of nb
+func parseCalcExpr(text string, nb *Buffer) (calcNode, error) {
+	toks, err := lexCalc(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &calcParser{toks: toks, b: nb}
+	if p.peek().kind == tokEOF {
+		return nil, errors.New("the expression is empty")
+	}
+	root, err := p.parseJoin()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		if t.kind == tokRParen {
+			return nil, tokenError(t, "\")\" has no matching \"(\"")
+		}
+		return nil, tokenError(t, "expected an operator or the end, found "+t.describe())
+	}
+	return root, nil
+}
+
+func (p *calcParser) peek() filterToken { return p.toks[p.i] }
+
+func (p *calcParser) next() filterToken {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// parseBinary parses: operand { op operand } for the operators in ops
+func (p *calcParser) parseBinary(ops string, operand func() (calcNode, error)) (calcNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.kind == tokOp && strings.Contains(ops, t.text); t = p.peek() {
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = calcBinary{t.text, left, right}
+	}
+	return left, nil
+}
+
+// parseJoin parses: sum { & sum }, & joins text and binds loosest
+func (p *calcParser) parseJoin() (calcNode, error) {
+	return p.parseBinary("&", p.parseSum)
+}
+
+// parseSum parses: product { (+ | -) product }
+func (p *calcParser) parseSum() (calcNode, error) {
+	return p.parseBinary("+-", p.parseProduct)
+}
+
+// parseProduct parses: unary { (* | / | %) unary }
+func (p *calcParser) parseProduct() (calcNode, error) {
+	return p.parseBinary("*/%", p.parseUnary)
+}
+
+// parseUnary parses: - unary | primary
+func (p *calcParser) parseUnary() (calcNode, error) {
+	if t := p.peek(); t.kind == tokOp && t.text == "-" {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return calcNeg{x}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses: number | string | column | function "(" args ")" | "(" join ")"
+func (p *calcParser) parsePrimary() (calcNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return calcLit{t.text}, nil
+	case tokName:
+		col, err := exprColumn(p.b, filterToken{tokString, t.text, t.pos})
+		if err != nil {
+			return nil, err
+		}
+		return &calcCol{col}, nil
+	case tokLParen:
+		x, err := p.parseJoin()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, tokenError(closing, "expected \")\" to close the \"(\" at position "+strconv.Itoa(t.pos)+", found "+closing.describe())
+		}
+		return x, nil
+	case tokWord:
+		if p.peek().kind == tokLParen {
+			return p.parseCall(t)
+		}
+		if _, err := strconv.ParseFloat(t.text, 64); err == nil {
+			return calcLit{t.text}, nil
+		}
+		col, err := exprColumn(p.b, t)
+		if err != nil {
+			return nil, err
+		}
+		return &calcCol{col}, nil
+	}
+	return nil, tokenError(t, "expected a column, number, text or function, found "+t.describe())
+}
+
+// parseCall parses the arguments of the function named by t
+func (p *calcParser) parseCall(t filterToken) (calcNode, error) {
+	name := strings.ToLower(t.text)
+	f, ok := calcFuncs[name]
+	if !ok {
+		return nil, tokenError(t, "unknown function "+t.describe()+", use "+calcFuncNames())
+	}
+	open := p.next()
+	var args []calcNode
+	if p.peek().kind != tokRParen {
+		for {
+			arg, err := p.parseJoin()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if closing := p.next(); closing.kind != tokRParen {
+		return nil, tokenError(closing, "expected \",\" or \")\" to close the \"(\" at position "+strconv.Itoa(open.pos)+", found "+closing.describe())
+	}
+	if len(args) < f.minArgs || (f.maxArgs >= 0 && len(args) > f.maxArgs) {
+		want := strconv.Itoa(f.minArgs)
+		switch {
+		case f.maxArgs < 0:
+			want += " or more"
+		case f.maxArgs > f.minArgs:
+			want += " to " + strconv.Itoa(f.maxArgs)
+		}
+		return nil, tokenError(t, name+" takes "+want+" arguments, found "+strconv.Itoa(len(args)))
+	}
+	fn, err := f.parse(p, t, args)
+	if err != nil {
+		return nil, err
+	}
+	return calcCall{fn, args}, nil
+}
+
+// withComputedUnsafe returns a row appended while loading with the computed
+// columns filled in, so they keep up with new rows. A wider row adds columns
+// before the computed ones, like a wider row grows a table without them
+// (caller holds the lock).
+func (b *Buffer) withComputedUnsafe(s []string) []string {
+	for len(s) > b.colLen-len(b.computed) {
+		b.appendColumnUnsafe("NaN", "NaN")
+	}
+	base := b.colLen - len(b.computed)
+	row := make([]string, base, b.colLen)
+	copy(row, s)
+	for i := len(s); i < base; i++ {
+		row[i] = "NaN"
+	}
+	for _, cc := range b.computed {
+		row = append(row, cc.root.eval(row))
+	}
+	return row
+}
+
+// addComputedColumn parses text and appends its values to every row of b as
+// a new last column named name, with the type detected from the values
+func (b *Buffer) addComputedColumn(name, text string) error {
+	if b.index != nil {
+		return errors.New(indexedUnsupportedMsg)
+	}
+	root, err := parseCalcExpr(text, b)
+	if err != nil {
+		return err
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		name = strings.Join(strings.Fields(text), " ")
+	}
+
+	b.mu.Lock()
+	for r := range b.cont {
+		value := name
+		if r >= b.rowFreeze {
+			value = root.eval(b.cont[r])
+		}
+		b.cont[r] = append(b.cont[r], value)
+		b.memoryUsage += int64(len(value)) + stringOverheadBytes
+	}
+	b.colLen++
+	for len(b.colType) < b.colLen+1 {
+		b.colType = append(b.colType, colTypeStr)
+	}
+	b.computed = append(b.computed, &computedColumn{name: name, text: strings.TrimSpace(text), root: root})
+	b.mu.Unlock()
+
+	b.setColType(b.colLen-1, b.autoDetectColumnType(b.colLen-1))
+	return nil
+}
+
+// showComputedForm asks for a name and an expression and adds the column to
+// the displayed table, filters are applied again so it shows right away
+func showComputedForm() {
+	form := tview.NewForm()
+	closeForm := func() { closeModalForm("computedModal") }
+
+	hint := "[gray]price * qty,  round(total / 1000, 1),  upper(name),  first & \" \" & last\n" +
+		"date_diff(end, start, \"days\"),  regex(url, \"https?://([^/]+)\")\n" +
+		"Functions: " + calcFuncNames() + "[-]"
+	form.AddInputField("Name:", "", 40, nil, nil)
+	form.AddInputField("Expression:", "", 70, nil, nil)
+	form.AddTextView("", hint, 90, 4, true, false)
+	nameField := form.GetFormItem(0).(*tview.InputField)
+	exprField := form.GetFormItem(1).(*tview.InputField)
+	message := form.GetFormItem(2).(*tview.TextView)
+
+	add := func() {
+		source := displayedSource()
+		if err := source.addComputedColumn(nameField.GetText(), exprField.GetText()); err != nil {
+			message.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+		closeForm()
+		if isFiltered && originalBuffer != nil {
+			b = applyFilters()
+		}
+		col := b.colLen - 1
+		drawBuffer(b, bufferTable)
+		row, _ := bufferTable.GetSelection()
+		bufferTable.Select(row, col)
+		currentCursorColumn = col
+		cursorPosStr = buildCursorPosStr(row, col)
+		updateFooterWithStatus("Added column " + strconv.Quote(source.computed[len(source.computed)-1].name) + " [" + type2name(b.getColType(col)) + "]")
+	}
+
+	showModalForm("computedModal", form, " ➕ Add Column - Enter to add, Esc to cancel ", "Add", add, 110, 13)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newComputedTestBuffer(t *testing.T) *Buffer {
+	t.Helper()
+	return newTestBuffer(t, [][]string{
+		{"first", "last", "price", "qty", "start", "end", "url", "unit price"},
+		{"ann", "lee", "2.5", "4", "2024-01-01", "2024-01-31", "https://example.com/a", "0.1"},
+		{"Bob", " kim ", "1,200", "0", "2024-03-01", "2024-03-02 12:00:00", "ftp://files", "0.2"},
+		{"cid", "", "NA", "3", "", "2024-01-01", "http://go.dev", "x"},
+	}, colTypeStr, colTypeStr, colTypeFloat, colTypeFloat, colTypeDate, colTypeDate, colTypeStr, colTypeFloat)
+}
+
+func TestComputedColumnValues(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"price * qty", []string{"10", "0", ""}},
+		{"price / qty", []string{"0.625", "", ""}},
+		{"qty % 3 + 1", []string{"2", "1", "1"}},
+		{"-qty + 2 * (qty - 1)", []string{"2", "-2", "1"}},
+		{"`unit price` + 0.2", []string{"0.3", "0.4", ""}},
+		{"$3 - $4", []string{"-1.5", "1200", ""}},
+		{`first & " " & upper(trim(last))`, []string{"ann LEE", "Bob KIM", "cid "}},
+		{"concat(lower(First), '-', len(last))", []string{"ann-3", "bob-5", "cid-0"}},
+		{"substr(url, 1, 3) & substr(first, 2)", []string{"httnn", "ftpob", "httid"}},
+		{`replace(url, "/", "|")`, []string{"https:||example.com|a", "ftp:||files", "http:||go.dev"}},
+		{`regex(url, "https?://([^/]+)")`, []string{"example.com", "", "go.dev"}},
+		{`regex(url, "[a-z]+")`, []string{"https", "ftp", "http"}},
+		{`regex(url, "(\w+)://(\w+)", 2)`, []string{"example", "files", "go"}},
+		{"date_diff(end, start)", []string{"30", "1.5", ""}},
+		{`date_diff(end, start, "hours")`, []string{"720", "36", ""}},
+		{"round(price / 3, 2)", []string{"0.83", "400", ""}},
+		{"round(price * 1.5) & abs(-qty)", []string{"44", "18000", "3"}},
+		{"coalesce(last, price, first)", []string{"lee", " kim ", "cid"}},
+		{"qty + first", []string{"", "", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			nb := newComputedTestBuffer(t)
+			if err := nb.addComputedColumn("", tt.expr); err != nil {
+				t.Fatalf("addComputedColumn() error = %v", err)
+			}
+			var got []string
+			for _, row := range nb.cont[1:] {
+				got = append(got, row[len(row)-1])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("values = %q, want %q", got, tt.want)
+			}
+			if name := nb.cont[0][nb.colLen-1]; name != tt.expr {
+				t.Errorf("header = %q, want the expression", name)
+			}
+		})
+	}
+}
+
+func TestComputedColumnErrors(t *testing.T) {
+	nb := newComputedTestBuffer(t)
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "the expression is empty"},
+		{"price *", "position 8: expected a column, number, text or function"},
+		{"price qty", `position 7: expected an operator or the end, found "qty"`},
+		{"(price + 1", `position 11: expected ")" to close the "(" at position 1`},
+		{"price)", `position 6: ")" has no matching "("`},
+		{"total * 2", `position 1: unknown column "total"`},
+		{"$12", "column $12 does not exist, the table has 8 columns"},
+		{"sum(price)", `position 1: unknown function "sum", use abs, coalesce`},
+		{"upper(first, last)", "position 1: upper takes 1 arguments, found 2"},
+		{"substr(first)", "substr takes 2 to 3 arguments, found 1"},
+		{"regex(url, first)", "the pattern of regex is a quoted text"},
+		{`regex(url, "(")`, "invalid regular expression"},
+		{`date_diff(end, start, "years")`, "the unit of date_diff is"},
+		{`first & "open`, "position 9: the quote is never closed"},
+	}
+	for _, tt := range tests {
+		if err := nb.addComputedColumn("x", tt.expr); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("addComputedColumn(%q) error = %v, want it to mention %q", tt.expr, err, tt.want)
+		}
+	}
+	if nb.colLen != 8 {
+		t.Errorf("colLen = %d after errors, want 8", nb.colLen)
+	}
+}
+
+func TestComputedColumnFollowsData(t *testing.T) {
+	nb := newTestBuffer(t, [][]string{{"a", "b"}, {"1", "2"}})
+	if err := nb.addComputedColumn("sum", "a + b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := nb.addComputedColumn("twice", "sum * 2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := nb.getColType(2); got != colTypeFloat {
+		t.Errorf("type = %v, want colTypeFloat", got)
+	}
+
+	// Rows loaded later are computed, short rows padded as missing
+	for _, row := range [][]string{{"3", "4"}, {"5"}} {
+		if err := nb.contAppendSli(row, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A column found later while loading goes before the computed ones
+	nb.appendColumn("c", "")
+	if err := nb.contAppendSli([]string{"1", "1", "7"}, false); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"a", "b", "c", "sum", "twice"},
+		{"1", "2", "", "3", "6"},
+		{"3", "4", "", "7", "14"},
+		{"5", "NaN", "", "", ""},
+		{"1", "1", "7", "2", "4"},
+	}
+	if !reflect.DeepEqual(nb.cont, want) {
+		t.Errorf("rows = %q, want %q", nb.cont, want)
+	}
+	if nb.colLen != 5 {
+		t.Errorf("colLen = %d, want 5", nb.colLen)
+	}
+
+	// Strict mode compares rows with the columns of the input
+	if err := nb.contAppendSli([]string{"2", "3", "4"}, true); err != nil {
+		t.Errorf("strict append error = %v", err)
+	}
+	if err := nb.contAppendSli([]string{"2", "3"}, true); err == nil {
+		t.Error("strict append of a short row succeeded")
+	}
+	if got := nb.cont[len(nb.cont)-1]; !reflect.DeepEqual(got, []string{"2", "3", "4", "5", "10"}) {
+		t.Errorf("strict row = %q", got)
+	}
+	// A wider row adds its columns before the computed ones
+	if err := nb.contAppendSli([]string{"1", "2", "3", "x"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := nb.cont[0]; !reflect.DeepEqual(got, []string{"a", "b", "c", "NaN", "sum", "twice"}) {
+		t.Errorf("header = %q", got)
+	}
+	if got := nb.cont[1]; !reflect.DeepEqual(got, []string{"1", "2", "", "NaN", "3", "6"}) {
+		t.Errorf("first row = %q", got)
+	}
+	if got := nb.cont[len(nb.cont)-1]; !reflect.DeepEqual(got, []string{"1", "2", "3", "x", "3", "6"}) {
+		t.Errorf("wide row = %q", got)
+	}
+	if nb.colLen != 6 || nb.getColType(4) != colTypeFloat {
+		t.Errorf("colLen = %d, sum type = %v", nb.colLen, nb.getColType(4))
+	}
+}
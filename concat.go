@@ -0,0 +1,311 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// sourceColumnName is the column --source-column adds to tell which shard a
+// row came from
+const sourceColumnName = "_source"
+
+// shardAligner lines up the columns of every shard with the header of the
+// first one, shards may list the same columns in another order
+type shardAligner struct {
+	names  []string
+	source bool // add the _source column
+}
+
+// header returns the header row of the combined table
+func (sa *shardAligner) header() []string {
+	header := append([]string{}, sa.names...)
+	if sa.source {
+		header = append(header, sourceColumnName)
+	}
+	return header
+}
+
+// order maps the columns of a shard header to their position in the combined
+// table, it fails unless the shard has exactly the same columns
+func (sa *shardAligner) order(fn string, header []string) ([]int, error) {
+	positions := make(map[string][]int)
+	for i, name := range sa.names {
+		positions[name] = append(positions[name], i)
+	}
+	order := make([]int, len(header))
+	var extra []string
+	for i, name := range header {
+		if len(positions[name]) == 0 {
+			extra = append(extra, name)
+			continue
+		}
+		order[i] = positions[name][0]
+		positions[name] = positions[name][1:]
+	}
+	var missing []string
+	for _, name := range sa.names {
+		if len(positions[name]) > 0 {
+			missing = append(missing, name)
+			positions[name] = positions[name][1:]
+		}
+	}
+	if len(extra) == 0 && len(missing) == 0 {
+		return order, nil
+	}
+	msg := fn + ": header does not match the first file"
+	if len(missing) > 0 {
+		msg += ", missing " + strings.Join(missing, ", ")
+	}
+	if len(extra) > 0 {
+		msg += ", unexpected " + strings.Join(extra, ", ")
+	}
+	return nil, errors.New(msg)
+}
+
+// row puts the fields of a shard row in the column order of the combined table
+func (sa *shardAligner) row(fn string, fields []string, order []int) ([]string, error) {
+	if args.Strict && len(fields) != len(order) {
+		return nil, errors.New(fn + ": row has " + strconv.Itoa(len(fields)) + " fields, the header has " + strconv.Itoa(len(order)))
+	}
+	width := len(sa.names)
+	if sa.source {
+		width++
+	}
+	row := make([]string, width)
+	for i, pos := range order {
+		if i < len(fields) {
+			row[pos] = fields[i]
+		}
+	}
+	if sa.source {
+		row[width-1] = fn
+	}
+	return row, nil
+}
+
+// load sharded files into one buffer (async version for progressive rendering)
+func loadShardsToBufferAsync(files []string, b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+	err := loadShards(files, b, updateChan)
+	if err == nil {
+		b.progress.IsComplete = true
+
+		// Types are set before done, as loadFileToBufferAsync does
+		b.detectAllColumnTypes()
+
+		// Enable string interning for categorical columns (async)
+		go b.enableStringInterning()
+	}
+	doneChan <- err
+}
+
+// load sharded files into one buffer (synchronous version)
+func loadShardsToBuffer(files []string, b *Buffer) error {
+	if err := loadShards(files, b, nil); err != nil {
+		return err
+	}
+	b.detectAllColumnTypes()
+	b.enableStringInterning()
+	return nil
+}
+
+// loadShards reads the shards one after another, the header of the first one
+// becomes the header of the table and the others must have the same columns.
+// updateChan is nil for synchronous loading.
+func loadShards(files []string, b *Buffer, updateChan chan<- bool) error {
+	var total int64
+	for _, fn := range files {
+		info, err := os.Stat(fn)
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+	}
+	b.progress.TotalBytes = total
+	b.progress.LoadedBytes = 0
+	b.progress.IsComplete = false
+	b.progress.CompressedBytes = nil
+	progress := newProgressTracker(total, updateChan == nil)
+	defer progress.finish()
+
+	totalAddedLN := 0 //the number of lines has been added into buffer
+	batchSize := 0
+	const updateInterval = 500 // Update UI every 500 lines
+	signaled := false
+	signal := func() {
+		if updateChan == nil {
+			return
+		}
+		if !signaled {
+			// The first rows are on their way to the screen
+			updateChan <- true
+			signaled = true
+			return
+		}
+		select {
+		case updateChan <- true:
+		default:
+			// Non-blocking - skip update if channel is full
+		}
+	}
+
+	var align *shardAligner
+	var loaded int64 // size of the shards read so far
+	for _, fn := range files {
+		reader, closer, err := openFileReader(fn)
+		if err != nil {
+			return err
+		}
+		records := newRecordScanner(newLineScanner(reader), b.dialect)
+		skipNum := args.SkipNum
+		// next returns the next record to show, each shard skips the same lines
+		next := func() (string, bool) {
+			for records.Scan() {
+				line := records.Text()
+				//skip empty line
+				if line == "\n" {
+					continue
+				}
+				//ignore first n lines
+				if skipNum > 0 {
+					skipNum--
+					continue
+				}
+				//ignore line with specified prefix
+				if skipLine(line, args.SkipSymbol) {
+					continue
+				}
+				return line, true
+			}
+			return "", false
+		}
+
+		//set separator from the first shard, if user does not provide it.
+		var detectLines []string
+		if b.sep == "" {
+			for len(detectLines) < 10 {
+				line, ok := next()
+				if !ok {
+					break
+				}
+				detectLines = append(detectLines, line)
+			}
+			if strings.HasSuffix(plainFileName(fn), ".csv") {
+				b.sep = ","
+			} else if strings.HasSuffix(plainFileName(fn), ".tsv") {
+				b.sep = "\t"
+			} else {
+				sd := sepDetecor{}
+				b.sep = sd.sepDetect(detectLines)
+			}
+			useFixedWidth(b, detectLines, args.FixedWidth)
+			if b.sep == "" {
+				closer.Close()
+				return errors.New("tv can't identify separator, you need to set it manual")
+			}
+		}
+		records.dialect = b.dialect
+		records.plain = b.plainRecords()
+
+		var order []int
+		for {
+			var line string
+			if len(detectLines) > 0 {
+				line, detectLines = detectLines[0], detectLines[1:]
+			} else if l, ok := next(); ok {
+				line = l
+			} else {
+				break
+			}
+			if totalAddedLN >= args.NLine && args.NLine > 0 {
+				break
+			}
+			fields, err := b.splitLine(line)
+			if err != nil {
+				closer.Close()
+				return err
+			}
+
+			switch {
+			case order == nil && align == nil:
+				// The first header is the header of the table
+				align = &shardAligner{names: fields, source: args.SourceColumn}
+				order = make([]int, len(fields))
+				for i := range order {
+					order[i] = i
+				}
+				err = appendVisible(b, align.header())
+				totalAddedLN++
+			case order == nil:
+				order, err = align.order(fn, fields)
+			default:
+				if fields, err = align.row(fn, fields, order); err == nil {
+					err = appendVisible(b, fields)
+					totalAddedLN++
+					batchSize++
+				}
+			}
+			if err != nil {
+				closer.Close()
+				return err
+			}
+
+			bytesRead := int64(len(line) + 1) // +1 for newline
+			b.progress.LoadedBytes += bytesRead
+			progress.increment(bytesRead)
+			if batchSize >= updateInterval || (!signaled && totalAddedLN >= 10) {
+				signal()
+				batchSize = 0
+			}
+		}
+		closer.Close()
+
+		// Compressed shards hold more text than their size, so progress
+		// catches up with the file sizes after each shard
+		info, err := os.Stat(fn)
+		if err == nil {
+			loaded += info.Size()
+		}
+		b.progress.LoadedBytes = loaded
+		signal()
+		if totalAddedLN >= args.NLine && args.NLine > 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// loadAndDisplayShards shows sharded files as one table, names are the file
+// arguments for the footer and files their expansion
+func loadAndDisplayShards(names, files []string, useAsync bool) error {
+	switch {
+	case args.Follow:
+		return errors.New("--follow cannot be combined with --concat")
+	case args.Pattern != "":
+		return errors.New("--concat needs a header line in every file, which --pattern input does not have")
+	}
+	for _, fn := range files {
+		format, err := detectFileFormat(fn)
+		if err != nil {
+			return err
+		}
+		if format != formatDelimited {
+			return errors.New(fn + ": --concat reads delimited text only")
+		}
+	}
+
+	args.FileName = files[0]
+	if len(names) == 1 {
+		args.FileName = names[0]
+	}
+	args.FileName += " (" + strconv.Itoa(len(files)) + " files)"
+	if useAsync {
+		return loadAndDisplayAsync(func(b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+			go loadShardsToBufferAsync(files, b, updateChan, doneChan)
+		}, "Files")
+	}
+	return loadAndDisplaySync(func(b *Buffer) error {
+		return loadShardsToBuffer(files, b)
+	}, "Files")
+}
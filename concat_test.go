@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShardAlignerOrder(t *testing.T) {
+	sa := &shardAligner{names: []string{"id", "name", "id"}}
+	order, err := sa.order("b.csv", []string{"name", "id", "id"})
+	if err != nil {
+		t.Fatalf("order() error = %v", err)
+	}
+	if !reflect.DeepEqual(order, []int{1, 0, 2}) {
+		t.Errorf("order() = %v, want [1 0 2]", order)
+	}
+
+	_, err = sa.order("c.csv", []string{"id", "title", "id"})
+	if err == nil || !strings.Contains(err.Error(), "missing name") || !strings.Contains(err.Error(), "unexpected title") {
+		t.Errorf("order() error = %v, want missing and unexpected columns", err)
+	}
+}
+
+func TestLoadShards(t *testing.T) {
+	defer args.setDefault()
+	files := expandFileArgs([]string{"./data/test/shards/part-*"})
+	want := [][]string{
+		{"id", "name", "score", "_source"},
+		{"1", "ann", "90", files[0]},
+		{"2", "bob", "85", files[0]},
+		{"3", "cid", "70", files[1]},
+		{"4", "dee", "65", files[1]},
+		{"5", "eve, jr", "99", files[2]},
+	}
+	args.SourceColumn = true
+	for _, async := range []bool{false, true} {
+		b := createNewBuffer()
+		var err error
+		if async {
+			updateChan := make(chan bool, 10)
+			doneChan := make(chan error, 1)
+			go loadShardsToBufferAsync(files, b, updateChan, doneChan)
+			err = <-doneChan
+		} else {
+			err = loadShardsToBuffer(files, b)
+		}
+		if err != nil {
+			t.Fatalf("async=%v: load error = %v", async, err)
+		}
+		b.mu.RLock()
+		if !reflect.DeepEqual(b.cont, want) {
+			t.Errorf("async=%v: buffer = %q, want %q", async, b.cont, want)
+		}
+		b.mu.RUnlock()
+	}
+
+	// Without the source column, and with the first line of each file skipped
+	args.SourceColumn = false
+	args.SkipNum = 1
+	dir := t.TempDir()
+	for i, data := range []string{"# run 1\na\tb\n1\t2\n", "# run 2\nb\ta\n4\t3\n"} {
+		if err := os.WriteFile(filepath.Join(dir, "part-"+string(rune('0'+i))+".tsv"), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := createNewBuffer()
+	if err := loadShardsToBuffer(expandFileArgs([]string{filepath.Join(dir, "*.tsv")}), b); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"a", "b"}, {"1", "2"}, {"3", "4"}}; !reflect.DeepEqual(b.cont, want) {
+		t.Errorf("buffer = %q, want %q", b.cont, want)
+	}
+}
+
+func TestLoadShardsHeaderMismatch(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.csv")
+	second := filepath.Join(dir, "b.csv")
+	os.WriteFile(first, []byte("id,name\n1,ann\n"), 0o644)
+	os.WriteFile(second, []byte("id,title\n2,dr\n"), 0o644)
+	err := loadShardsToBuffer([]string{first, second}, createNewBuffer())
+	if err == nil || !strings.HasPrefix(err.Error(), second) {
+		t.Errorf("loadShardsToBuffer() error = %v, want a header mismatch in %s", err, second)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// dialect describes how a line of delimited text splits into fields
+type dialect struct {
+	sep    string // field separator, one or more characters ("" = not known yet)
+	quote  rune   // quote character (0 = no quoting)
+	escape rune   // escape character (0 = quotes are escaped by doubling them)
+}
+
+// defaultDialect is RFC 4180 CSV with the separator still to be detected
+var defaultDialect = dialect{quote: '"'}
+
+// newDialect builds a dialect from the --separator, --quote and --escape values
+func newDialect(sep, quote, escape string) (dialect, error) {
+	d := dialect{sep: sep}
+	if strings.ContainsAny(sep, "\r\n") {
+		return d, errors.New("the separator cannot contain a line break")
+	}
+	if quote != "" {
+		if utf8.RuneCountInString(quote) != 1 {
+			return d, errors.New("--quote must be a single character, or empty to turn quoting off")
+		}
+		d.quote, _ = utf8.DecodeRuneInString(quote)
+	}
+	if escape != "" {
+		if utf8.RuneCountInString(escape) != 1 {
+			return d, errors.New("--escape must be a single character")
+		}
+		d.escape, _ = utf8.DecodeRuneInString(escape)
+	}
+	if d.quote != 0 && strings.ContainsRune(sep, d.quote) {
+		return d, errors.New("the separator cannot contain the quote character")
+	}
+	if d.escape != 0 && strings.ContainsRune(sep, d.escape) {
+		return d, errors.New("the separator cannot contain the escape character")
+	}
+	return d, nil
+}
+
+// standard reports whether the csv package can parse this dialect
+func (d dialect) standard() bool {
+	return utf8.RuneCountInString(d.sep) == 1 && d.quote == '"' && d.escape == 0
+}
+
+// unquoted reports whether line can be split at every separator, without
+// looking at quotes or escapes
+func (d dialect) unquoted(line string) bool {
+	return (d.quote == 0 || !strings.ContainsRune(line, d.quote)) &&
+		(d.escape == 0 || !strings.ContainsRune(line, d.escape))
+}
+
+// sepAt returns the length of the separator starting at line[i:], 0 if there
+// is none. Before the separator is known any of the common ones counts.
+func (d dialect) sepAt(line string, i int) int {
+	if d.sep != "" {
+		if strings.HasPrefix(line[i:], d.sep) {
+			return len(d.sep)
+		}
+		return 0
+	}
+	switch line[i] {
+	case ',', '\t', '|', ';':
+		return 1
+	}
+	return 0
+}
+
+// split breaks a record into fields. Quotes only open a field at its start
+// and a quote that is not followed by a separator is kept, like the csv
+// package does with LazyQuotes. The escape character keeps the next
+// character as it is, inside or outside quotes.
+func (d dialect) split(s string) []string {
+	var fields []string
+	var field strings.Builder
+	fieldStart, inQuotes := true, false
+	for i := 0; i < len(s); {
+		if !inQuotes && d.sep != "" && strings.HasPrefix(s[i:], d.sep) {
+			fields = append(fields, field.String())
+			field.Reset()
+			i += len(d.sep)
+			fieldStart = true
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == d.escape && d.escape != 0 && i < len(s):
+			r, size = utf8.DecodeRuneInString(s[i:])
+			i += size
+			field.WriteRune(r)
+		case r == d.quote && d.quote != 0 && fieldStart:
+			inQuotes = true
+		case r == d.quote && inQuotes:
+			if next, size := utf8.DecodeRuneInString(s[i:]); next == d.quote && i < len(s) {
+				i += size // doubled quote
+				field.WriteRune(r)
+			} else if i == len(s) || (d.sep != "" && strings.HasPrefix(s[i:], d.sep)) {
+				inQuotes = false
+			} else {
+				field.WriteRune(r) // stray quote inside a quoted field
+			}
+		default:
+			field.WriteRune(r)
+		}
+		fieldStart = false
+	}
+	return append(fields, field.String())
+}
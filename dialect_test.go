@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewDialect(t *testing.T) {
+	tests := []struct {
+		sep, quote, escape string
+		want               dialect
+		wantErr            bool
+	}{
+		{"", `"`, "", dialect{quote: '"'}, false},
+		{"^|^", `'`, `\`, dialect{sep: "^|^", quote: '\'', escape: '\\'}, false},
+		{"||", "", "", dialect{sep: "||"}, false},
+		{",", `''`, "", dialect{}, true},
+		{",", `"`, `\\`, dialect{}, true},
+		{`","`, `"`, "", dialect{}, true},
+		{"a\nb", `"`, "", dialect{}, true},
+	}
+	for _, tt := range tests {
+		got, err := newDialect(tt.sep, tt.quote, tt.escape)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("newDialect(%q, %q, %q) error = %v, wantErr %v", tt.sep, tt.quote, tt.escape, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("newDialect(%q, %q, %q) = %+v, want %+v", tt.sep, tt.quote, tt.escape, got, tt.want)
+		}
+	}
+}
+
+func TestLineCSVParseDialects(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		d    dialect
+		want []string
+	}{
+		{"Double pipe", "a||b||c", dialect{sep: "||", quote: '"'}, []string{"a", "b", "c"}},
+		{"Caret pipe caret", "1^|^two^|^", dialect{sep: "^|^", quote: '"'}, []string{"1", "two", ""}},
+		{"Quoted multi-char separator", `"a||b"||c`, dialect{sep: "||", quote: '"'}, []string{"a||b", "c"}},
+		{"Single quotes", `'O''Brien, Pat',42`, dialect{sep: ",", quote: '\''}, []string{"O'Brien, Pat", "42"}},
+		{"Double quotes are text with single quoting", `"a,b",c`, dialect{sep: ",", quote: '\''}, []string{`"a`, `b"`, "c"}},
+		{"Backslash escapes in quotes", `"say \"hi\"",x`, dialect{sep: ",", quote: '"', escape: '\\'}, []string{`say "hi"`, "x"}},
+		{"Escaped separator", `a\,b,c`, dialect{sep: ",", quote: '"', escape: '\\'}, []string{"a,b", "c"}},
+		{"No quoting", `"a",b`, dialect{sep: ","}, []string{`"a"`, "b"}},
+		{"Lazy quote with custom quote", `'5' screen',10`, dialect{sep: ",", quote: '\''}, []string{`5' screen`, "10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lineCSVParseFast(tt.line, tt.d)
+			if err != nil {
+				t.Fatalf("lineCSVParseFast() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lineCSVParseFast(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordScannerDialects(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		d     dialect
+		want  []string
+	}{
+		{"Single quote across lines", "a,'b\nc',d\ne,f\n", dialect{sep: ",", quote: '\''}, []string{"a,'b\nc',d", "e,f"}},
+		{"Escaped quote does not close", "1,\"x \\\"\ny\"\n2,w\n", dialect{sep: ",", quote: '"', escape: '\\'}, []string{"1,\"x \\\"\ny\"", "2,w"}},
+		{"Multi-char separator opens a field", "1||\"a\nb\"||2\n3||4\n", dialect{sep: "||", quote: '"'}, []string{"1||\"a\nb\"||2", "3||4"}},
+		{"No quoting", "\"a\n\"b\n", dialect{sep: ","}, []string{"\"a", "\"b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := newRecordScanner(bufio.NewScanner(strings.NewReader(tt.input)), tt.d)
+			var got []string
+			for rs.Scan() {
+				got = append(got, rs.Text())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSepDetectMultiChar(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"Double pipe", []string{"id||name||city", "1||ann||Oslo", "2||bob||Lima"}, "||"},
+		{"Caret pipe caret", []string{"id^|^name", "1^|^ann", "2^|^bob"}, "^|^"},
+		{"Double pipe with empty field", []string{"id||name||city", "1||||Oslo", "2||bob||"}, "||"},
+		{"Pipes alone", []string{"a|b|c", "1|2|3"}, "|"},
+		{"Empty CSV fields", []string{"a,,c", "1,,3"}, ","},
+		{"Quoted CSV", []string{`"a","b","c"`, `"1","2","3"`}, ","},
+		{"Comma with a symbol run in values", []string{"a,b->c", "1,2->3"}, ","},
+	}
+	sd := sepDetecor{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sd.sepDetect(tt.lines); got != tt.want {
+				t.Errorf("sepDetect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMultiCharSeparatedFile(t *testing.T) {
+	defer args.setDefault()
+	fn := filepath.Join(t.TempDir(), "dump.txt")
+	data := "id^|^note^|^qty\n1^|^'a | b'^|^3\n2^|^it\\'s^|^4\n"
+	if err := os.WriteFile(fn, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args.Quote = "'"
+	args.Escape = `\`
+	b := createNewBuffer()
+	if err := configureBuffer(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadFileToBuffer(fn, b); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"id", "note", "qty"}, {"1", "a | b", "3"}, {"2", "it's", "4"}}
+	if !reflect.DeepEqual(b.cont, want) {
+		t.Errorf("buffer = %q, want %q", b.cont, want)
+	}
+	if b.sep != "^|^" {
+		t.Errorf("detected separator = %q, want ^|^", b.sep)
+	}
+}
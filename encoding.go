@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// encodingSniffLen is how much of the input is looked at to guess its encoding
+const encodingSniffLen = 4096
+
+// utf8BOM is the byte order mark some Windows tools put before UTF-8 text
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// textReader is file content turned into UTF-8 text, raw counts the bytes read
+// from the file since they differ from the text (compressed or transcoded)
+type textReader struct {
+	io.Reader
+	raw *countingReader
+}
+
+// lookupEncoding resolves an --encoding name like utf-16le, windows-1252 or
+// latin1. UTF-8 and UTF-16 drop a byte order mark when there is one.
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "_", "-")) {
+	case "utf-8", "utf8":
+		return unicode.UTF8BOM, nil
+	case "utf-16", "utf16", "utf-16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	case "utf-16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM), nil
+	}
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, errors.New("unknown encoding " + name)
+	}
+	return enc, nil
+}
+
+// detectEncoding guesses the encoding of text from its first bytes, it returns
+// nil for UTF-8 without a byte order mark, which needs no transcoding
+func detectEncoding(head []byte) encoding.Encoding {
+	switch {
+	case bytes.HasPrefix(head, utf8BOM):
+		return unicode.UTF8BOM
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	}
+	if order, ok := guessUTF16(head); ok {
+		return unicode.UTF16(order, unicode.IgnoreBOM)
+	}
+	if utf8.Valid(trimPartialRune(head)) {
+		return nil
+	}
+	// Anything else most likely comes from Windows, which also covers Latin-1
+	return charmap.Windows1252
+}
+
+// guessUTF16 recognises UTF-16 without a byte order mark: mostly ASCII text
+// then has a zero in every other byte
+func guessUTF16(head []byte) (unicode.Endianness, bool) {
+	pairs := len(head) / 2
+	if pairs < 2 {
+		return unicode.LittleEndian, false
+	}
+	var zeroEven, zeroOdd int
+	for i := 0; i < pairs*2; i += 2 {
+		if head[i] == 0 {
+			zeroEven++
+		}
+		if head[i+1] == 0 {
+			zeroOdd++
+		}
+	}
+	switch {
+	case zeroOdd*2 >= pairs && zeroEven*10 < pairs:
+		return unicode.LittleEndian, true
+	case zeroEven*2 >= pairs && zeroOdd*10 < pairs:
+		return unicode.BigEndian, true
+	}
+	return unicode.LittleEndian, false
+}
+
+// trimPartialRune drops a multi-byte character cut off at the end of head
+func trimPartialRune(head []byte) []byte {
+	for i := len(head) - 1; i >= 0 && i >= len(head)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(head[i]) {
+			if !utf8.FullRune(head[i:]) {
+				return head[:i]
+			}
+			break
+		}
+	}
+	return head
+}
+
+// textEncoding returns the encoding to transcode br from, given by --encoding
+// or guessed from the data that has already arrived (nil for UTF-8)
+func textEncoding(br *bufio.Reader) (encoding.Encoding, error) {
+	if args.Encoding != "" {
+		return lookupEncoding(args.Encoding)
+	}
+	// Wait for the first bytes, then look only at what is buffered so a slow
+	// pipe does not block
+	if _, err := br.Peek(1); err != nil {
+		return nil, nil
+	}
+	n := br.Buffered()
+	if n > encodingSniffLen {
+		n = encodingSniffLen
+	}
+	head, _ := br.Peek(n)
+	return detectEncoding(head), nil
+}
+
+// decodePipe returns a reader over the pipe content as UTF-8, or r itself
+// when it is UTF-8 already
+func decodePipe(r *bufio.Reader) (*bufio.Reader, error) {
+	enc, err := textEncoding(r)
+	if err != nil || enc == nil {
+		return r, err
+	}
+	return bufio.NewReader(transform.NewReader(r, enc.NewDecoder())), nil
+}
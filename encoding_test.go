@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	utf16le, _ := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte("a,b\r\n1,2\r\n"))
+	utf16be, _ := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder().Bytes([]byte("a,b\r\n1,2\r\n"))
+	tests := []struct {
+		name string
+		head []byte
+		want encoding.Encoding
+	}{
+		{"plain ASCII", []byte("a,b\n1,2\n"), nil},
+		{"UTF-8", []byte("city\nZürich\n"), nil},
+		{"UTF-8 cut inside a character", []byte("city\nZ\xc3"), nil},
+		{"UTF-8 BOM", []byte("\xef\xbb\xbfa,b\n"), unicode.UTF8BOM},
+		{"UTF-16LE BOM", append([]byte{0xFF, 0xFE}, utf16le...), unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)},
+		{"UTF-16BE BOM", append([]byte{0xFE, 0xFF}, utf16be...), unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)},
+		{"UTF-16LE without BOM", utf16le, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+		{"UTF-16BE without BOM", utf16be, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+		{"Windows-1252", []byte("city\nK\xf6ln\n"), charmap.Windows1252},
+		{"empty", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectEncoding(tt.head); got != tt.want {
+				t.Errorf("detectEncoding() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookupEncoding(t *testing.T) {
+	for _, name := range []string{"utf-8", "UTF-16LE", "utf_16be", "windows-1252", "latin1", "shift_jis"} {
+		if enc, err := lookupEncoding(name); err != nil || enc == nil {
+			t.Errorf("lookupEncoding(%q) = %v, %v", name, enc, err)
+		}
+	}
+	if _, err := lookupEncoding("klingon"); err == nil {
+		t.Error("lookupEncoding() should reject an unknown encoding")
+	}
+}
+
+func TestLoadEncodedFiles(t *testing.T) {
+	tests := []struct {
+		fn   string
+		want []string
+	}{
+		{"./data/test/cities_utf8bom.csv", []string{"Zürich", "Málaga", "Kraków"}},
+		{"./data/test/cities_utf16le.csv", []string{"Zürich", "Málaga", "Kraków"}},
+		{"./data/test/cities_cp1252.csv", []string{"Zürich", "Málaga", "Köln"}},
+	}
+	for _, tt := range tests {
+		t.Run(filepath.Base(tt.fn), func(t *testing.T) {
+			format, err := detectFileFormat(tt.fn)
+			if err != nil || format != formatDelimited {
+				t.Fatalf("detectFileFormat() = %d, %v", format, err)
+			}
+			b := createNewBuffer()
+			if err := loadFileToBuffer(tt.fn, b); err != nil {
+				t.Fatalf("loadFileToBuffer() error = %v", err)
+			}
+			if b.cont[0][0] != "city" {
+				t.Errorf("First header cell = %q, want \"city\"", b.cont[0][0])
+			}
+			if got := b.getCol(0)[1:]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cities = %q, want %q", got, tt.want)
+			}
+			if b.cont[3][2] == "" || b.colLen != 3 {
+				t.Errorf("Unexpected last row %q", b.cont[3])
+			}
+		})
+	}
+
+	// Progress is measured in file bytes
+	b := createNewBuffer()
+	updateChan := make(chan bool, 10)
+	doneChan := make(chan error, 1)
+	go loadFileToBufferAsync("./data/test/cities_utf16le.csv", b, updateChan, doneChan)
+	if err := <-doneChan; err != nil {
+		t.Fatal(err)
+	}
+	if got := b.progress.GetPercentage(); got != 100 {
+		t.Errorf("GetPercentage() = %.1f, want 100", got)
+	}
+}
+
+func TestDecodePipe(t *testing.T) {
+	data, err := os.ReadFile("./data/test/cities_utf16le.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := decodePipe(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format := detectPipeFormat(r); format != formatDelimited {
+		t.Errorf("detectPipeFormat() = %d, want delimited", format)
+	}
+	b := createNewBuffer()
+	if err := loadPipeToBuffer(r, b); err != nil {
+		t.Fatalf("loadPipeToBuffer() error = %v", err)
+	}
+	if b.rowLen != 4 || b.cont[1][0] != "Zürich" {
+		t.Errorf("Unexpected buffer %q", b.cont)
+	}
+}
+
+func TestEncodingOverride(t *testing.T) {
+	defer args.setDefault()
+	args.Encoding = "iso-8859-7"
+	r, err := decodePipe(bufio.NewReader(bytes.NewReader([]byte("name\n\xe1\xe2\xe3\n"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := createNewBuffer()
+	b.sep = ","
+	if err := loadPipeToBuffer(r, b); err != nil {
+		t.Fatal(err)
+	}
+	if b.cont[1][0] != "αβγ" {
+		t.Errorf("Decoded %q, want \"αβγ\"", b.cont[1][0])
+	}
+}
+
+func TestIndexedEncodings(t *testing.T) {
+	defer args.setDefault()
+	args.Index = true
+
+	b := createNewBuffer()
+	if err := loadIndexedFileToBuffer("./data/test/cities_utf8bom.csv", b); err != nil {
+		t.Fatalf("loadIndexedFileToBuffer() error = %v", err)
+	}
+	if got := b.index.row(0)[0]; got != "city" {
+		t.Errorf("First header cell = %q, want \"city\"", got)
+	}
+
+	for _, fn := range []string{"./data/test/cities_utf16le.csv", "./data/test/cities_cp1252.csv"} {
+		if err := loadIndexedFileToBuffer(fn, createNewBuffer()); err == nil {
+			t.Errorf("Indexing %s should fail", fn)
+		}
+	}
+}
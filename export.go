@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// export formats, in the order the export form lists them
+const (
+	exportCSV      = "CSV"
+	exportTSV      = "TSV"
+	exportJSON     = "JSON"
+	exportJSONL    = "JSON Lines"
+	exportMarkdown = "Markdown"
+	exportHTML     = "HTML"
+)
+
+var exportFormats = []string{exportCSV, exportTSV, exportJSON, exportJSONL, exportMarkdown, exportHTML}
+
+// exportFormatFromPath picks the export format from the extension of path
+func exportFormatFromPath(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return exportCSV, true
+	case ".tsv", ".tab":
+		return exportTSV, true
+	case ".json":
+		return exportJSON, true
+	case ".jsonl", ".ndjson":
+		return exportJSONL, true
+	case ".md", ".markdown":
+		return exportMarkdown, true
+	case ".html", ".htm":
+		return exportHTML, true
+	}
+	return "", false
+}
+
+// exportExtension returns the file extension written for format
+func exportExtension(format string) string {
+	switch format {
+	case exportTSV:
+		return ".tsv"
+	case exportJSON:
+		return ".json"
+	case exportJSONL:
+		return ".jsonl"
+	case exportMarkdown:
+		return ".md"
+	case exportHTML:
+		return ".html"
+	}
+	return ".csv"
+}
+
+// defaultExportPath suggests a file in the working directory named after the
+// input, like sales-view.csv for sales.csv.gz
+func defaultExportPath(format string) string {
+	name := "ftv"
+	if args.FileName != "" && args.FileName != "From Shell Pipe" {
+		name = filepath.Base(args.FileName)
+		for ext := filepath.Ext(name); ext != "" && ext != name; ext = filepath.Ext(name) {
+			name = strings.TrimSuffix(name, ext)
+		}
+	}
+	return name + "-view" + exportExtension(format)
+}
+
+// exportHeader returns the column names of nb, nil when its first row is not
+// a frozen header and every row is data
+func exportHeader(nb *Buffer) []string {
+	if nb.rowFreeze == 0 || nb.rowCount() == 0 {
+		return nil
+	}
+	header := make([]string, nb.colLen)
+	copy(header, nb.rowAt(0))
+	return header
+}
+
+// exportRow pads short rows to the width of the table
+func exportRow(nb *Buffer, r int) []string {
+	row := nb.rowAt(r)
+	if len(row) >= nb.colLen {
+		return row[:nb.colLen]
+	}
+	return append(append(make([]string, 0, nb.colLen), row...), make([]string, nb.colLen-len(row))...)
+}
+
+// writeExport writes the rows of nb in the given format. A frozen header row
+// names the columns, otherwise every row is written as data.
+func writeExport(w io.Writer, nb *Buffer, format string) error {
+	nb.mu.RLock()
+	defer nb.mu.RUnlock()
+
+	header := exportHeader(nb)
+	first := 0
+	if header != nil {
+		first = 1
+	}
+	rows := nb.rowCount()
+	out := bufio.NewWriter(w)
+
+	switch format {
+	case exportCSV, exportTSV:
+		cw := csv.NewWriter(out)
+		if format == exportTSV {
+			cw.Comma = '\t'
+		}
+		for r := 0; r < rows; r++ {
+			if err := cw.Write(exportRow(nb, r)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+
+	case exportJSON, exportJSONL:
+		if format == exportJSON {
+			out.WriteString("[")
+		}
+		for r := first; r < rows; r++ {
+			if format == exportJSON {
+				if r > first {
+					out.WriteString(",")
+				}
+				out.WriteString("\n  ")
+			}
+			out.WriteString(jsonRecord(nb, header, exportRow(nb, r)))
+			if format == exportJSONL {
+				out.WriteString("\n")
+			}
+		}
+		if format == exportJSON {
+			if rows > first {
+				out.WriteString("\n")
+			}
+			out.WriteString("]\n")
+		}
+
+	case exportMarkdown:
+		// Markdown tables need a header, numbered columns stand in for a missing one
+		names := header
+		if names == nil {
+			names = make([]string, nb.colLen)
+			for c := range names {
+				names[c] = strconv.Itoa(c + 1)
+			}
+		}
+		markdownRow(out, names)
+		out.WriteString("|" + strings.Repeat(" --- |", nb.colLen) + "\n")
+		for r := first; r < rows; r++ {
+			markdownRow(out, exportRow(nb, r))
+		}
+
+	case exportHTML:
+		out.WriteString("<table>\n")
+		if header != nil {
+			out.WriteString("<thead>\n")
+			htmlRow(out, "th", header)
+			out.WriteString("</thead>\n")
+		}
+		out.WriteString("<tbody>\n")
+		for r := first; r < rows; r++ {
+			htmlRow(out, "td", exportRow(nb, r))
+		}
+		out.WriteString("</tbody>\n</table>\n")
+
+	default:
+		return errors.New("unknown export format " + strconv.Quote(format))
+	}
+	return out.Flush()
+}
+
+// jsonRecord encodes a row as an object keyed by header, or as an array when
+// there is no header. Number columns become JSON numbers, their missing values null.
+func jsonRecord(nb *Buffer, header, row []string) string {
+	var sb strings.Builder
+	start, end := "{", "}"
+	if header == nil {
+		start, end = "[", "]"
+	}
+	sb.WriteString(start)
+	for c, cell := range row {
+		if c > 0 {
+			sb.WriteString(",")
+		}
+		if header != nil {
+			key, _ := json.Marshal(header[c])
+			sb.Write(key)
+			sb.WriteString(":")
+		}
+		sb.WriteString(jsonValue(cell, nb.getColType(c)))
+	}
+	sb.WriteString(end)
+	return sb.String()
+}
+
+// jsonValue encodes a cell, keeping numbers of number columns as numbers
+func jsonValue(cell string, colType int) string {
+	if colType == colTypeFloat {
+		s := strings.TrimSpace(cell)
+		if s == "" || strings.EqualFold(s, "NaN") || strings.EqualFold(s, "NA") {
+			return "null"
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	value, _ := json.Marshal(cell)
+	return string(value)
+}
+
+// markdownCell escapes the characters that would break a table row
+var markdownCell = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+func markdownRow(w *bufio.Writer, cells []string) {
+	w.WriteString("|")
+	for _, cell := range cells {
+		w.WriteString(" " + markdownCell.Replace(cell) + " |")
+	}
+	w.WriteString("\n")
+}
+
+func htmlRow(w *bufio.Writer, tag string, cells []string) {
+	w.WriteString("<tr>")
+	for _, cell := range cells {
+		w.WriteString("<" + tag + ">" + html.EscapeString(cell) + "</" + tag + ">")
+	}
+	w.WriteString("</tr>\n")
+}
+
+// exportBuffer writes nb to the file at path, through a temporary file so a
+// failed export does not leave half a file behind
+func exportBuffer(nb *Buffer, path, format string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := writeExport(tmp, nb, format); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// showExportForm asks for a path and format and writes the displayed table,
+// with its filters and sort order, to that file
+func showExportForm() {
+	form := tview.NewForm()
+	closeForm := func() { closeModalForm("exportModal") }
+
+	formatIndex := 0
+	form.AddInputField("Path:", defaultExportPath(exportFormats[formatIndex]), 50, nil, nil)
+	pathField := form.GetFormItem(0).(*tview.InputField)
+	// Picking a format follows the extension of the path and the other way round
+	form.AddDropDown("Format:", exportFormats, formatIndex, func(option string, optionIndex int) {
+		if optionIndex == formatIndex {
+			return
+		}
+		formatIndex = optionIndex
+		path := pathField.GetText()
+		if _, ok := exportFormatFromPath(path); ok {
+			pathField.SetText(strings.TrimSuffix(path, filepath.Ext(path)) + exportExtension(option))
+		}
+	})
+	formatDropDown := form.GetFormItem(1).(*tview.DropDown)
+	pathField.SetChangedFunc(func(text string) {
+		if format, ok := exportFormatFromPath(text); ok && format != exportFormats[formatIndex] {
+			for i, f := range exportFormats {
+				if f == format {
+					formatIndex = i
+					formatDropDown.SetCurrentOption(i)
+				}
+			}
+		}
+	})
+
+	// An existing file is only replaced after a second Enter
+	overwrite := ""
+	export := func() {
+		path := strings.TrimSpace(pathField.GetText())
+		if path == "" {
+			form.SetTitle(" 💾 Export - enter a file path ")
+			return
+		}
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		if _, err := os.Stat(path); err == nil && overwrite != path {
+			overwrite = path
+			form.SetTitle(" 💾 " + filepath.Base(path) + " exists - Enter again to overwrite ")
+			return
+		}
+		format := exportFormats[formatIndex]
+		closeForm()
+		updateFooterWithStatus("Exporting...")
+		app.ForceDraw()
+		if err := exportBuffer(b, path, format); err != nil {
+			updateFooterWithStatus("Export failed: " + err.Error())
+			return
+		}
+		updateFooterWithStatus(fmt.Sprintf("Exported %d rows to %s", b.rowCount()-b.rowFreeze, path))
+	}
+
+	showModalForm("exportModal", form, " 💾 Export View - Enter to save, Esc to cancel ", "Export", export, 72, 9)
+}
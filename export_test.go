@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteExport(t *testing.T) {
+	data := [][]string{{"name", "score"}, {"ann", "90"}, {"b|o\"b", "NaN"}}
+	tests := []struct {
+		format    string
+		rowFreeze int
+		want      string
+	}{
+		{exportCSV, 1, "name,score\nann,90\n\"b|o\"\"b\",NaN\n"},
+		{exportTSV, 1, "name\tscore\nann\t90\n\"b|o\"\"b\"\tNaN\n"},
+		{exportJSON, 1, "[\n  {\"name\":\"ann\",\"score\":90},\n  {\"name\":\"b|o\\\"b\",\"score\":null}\n]\n"},
+		{exportJSONL, 1, "{\"name\":\"ann\",\"score\":90}\n{\"name\":\"b|o\\\"b\",\"score\":null}\n"},
+		{exportJSONL, 0, "[\"name\",\"score\"]\n[\"ann\",90]\n[\"b|o\\\"b\",null]\n"},
+		{exportMarkdown, 1, "| name | score |\n| --- | --- |\n| ann | 90 |\n| b\\|o\"b | NaN |\n"},
+		{exportMarkdown, 0, "| 1 | 2 |\n| --- | --- |\n| name | score |\n| ann | 90 |\n| b\\|o\"b | NaN |\n"},
+		{exportHTML, 1, "<table>\n<thead>\n<tr><th>name</th><th>score</th></tr>\n</thead>\n<tbody>\n" +
+			"<tr><td>ann</td><td>90</td></tr>\n<tr><td>b|o&#34;b</td><td>NaN</td></tr>\n</tbody>\n</table>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			b, err := createNewBufferWithData(data, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.rowFreeze = tt.rowFreeze
+			b.setColType(1, colTypeFloat)
+			var sb strings.Builder
+			if err := writeExport(&sb, b, tt.format); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("writeExport(%s) =\n%s\nwant\n%s", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportFilteredView(t *testing.T) {
+	b, err := createNewBufferWithData([][]string{{"city", "n"}, {"Oslo", "3"}, {"Lima", "1"}, {"Oslo", "2"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.rowFreeze = 1
+	view := b.filterByColumn(0, FilterOptions{Query: "Oslo", Operator: "equals"})
+	view.sortByNum(1, false)
+
+	fn := filepath.Join(t.TempDir(), "oslo.csv")
+	format, ok := exportFormatFromPath(fn)
+	if !ok || format != exportCSV {
+		t.Fatalf("exportFormatFromPath(%q) = %q, %v", fn, format, ok)
+	}
+	if err := exportBuffer(view, fn, format); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "city,n\nOslo,2\nOslo,3\n"; string(got) != want {
+		t.Errorf("exported file = %q, want %q", got, want)
+	}
+}
+
+func TestDefaultExportPath(t *testing.T) {
+	defer args.setDefault()
+	tests := []struct {
+		fileName, format, want string
+	}{
+		{"data/sales.csv.gz", exportCSV, "sales-view.csv"},
+		{"report.tsv", exportMarkdown, "report-view.md"},
+		{"From Shell Pipe", exportJSON, "ftv-view.json"},
+		{".hidden", exportHTML, ".hidden-view.html"},
+	}
+	for _, tt := range tests {
+		args.FileName = tt.fileName
+		if got := defaultExportPath(tt.format); got != tt.want {
+			t.Errorf("defaultExportPath() for %q = %q, want %q", tt.fileName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/tview"
+)
+
+// rowFilter is a parsed filter expression over all columns, like
+// (status == "failed" OR retries > 3) AND NOT region ~ "^eu"
+type rowFilter struct {
+	text          string
+	caseSensitive bool
+	root          filterNode
+}
+
+// filterNode is a part of a filter expression that accepts or rejects a row
+type filterNode interface {
+	match(row []string) bool
+}
+
+type filterAnd struct{ left, right filterNode }
+type filterOr struct{ left, right filterNode }
+type filterNot struct{ x filterNode }
+
+// filterCond compares one column with a value through evaluateFilter
+type filterCond struct {
+	col     int
+	opts    FilterOptions
+	colType int
+	re      *regexp.Regexp // compiled once for ~ and !~
+	day     int64          // the date >, <, >= and <= compare date columns with
+	negate  bool           // != and !~
+}
+
+func (n filterAnd) match(row []string) bool { return n.left.match(row) && n.right.match(row) }
+func (n filterOr) match(row []string) bool  { return n.left.match(row) || n.right.match(row) }
+func (n filterNot) match(row []string) bool { return !n.x.match(row) }
+
+func (n filterCond) match(row []string) bool {
+	cell := ""
+	if n.col < len(row) {
+		cell = row[n.col]
+	}
+	var ok bool
+	switch {
+	case n.re != nil:
+		ok = n.re.MatchString(cell)
+	case n.day != 0:
+		ok = compareDates(parseDateValueFast(cell), n.day, n.opts.Operator)
+	default:
+		ok = evaluateFilter(cell, n.opts, n.colType)
+	}
+	return ok != n.negate
+}
+
+// compareDates compares the day of a cell with the one of a condition,
+// cells that hold no date match nothing
+func compareDates(cell, day int64, operator string) bool {
+	if cell == 0 {
+		return false
+	}
+	switch operator {
+	case ">":
+		return cell > day
+	case "<":
+		return cell < day
+	case ">=":
+		return cell >= day
+	}
+	return cell <= day
+}
+
+// filterOperators maps the comparison operators of expressions to those of
+// evaluateFilter, "!" marks the negated ones
+var filterOperators = map[string]string{
+	"==": "equals", "=": "equals", "!=": "!equals",
+	"~": "regex", "!~": "!regex",
+	">": ">", "<": "<", ">=": ">=", "<=": "<=",
+	"contains": "contains", "startswith": "starts with", "endswith": "ends with",
+}
+
+// filterToken is a lexical token of a filter expression
+type filterToken struct {
+	kind int
+	text string
+	pos  int // 1-based character position for error messages
+}
+
+const (
+	tokEOF = iota
+	tokWord
+	tokString // quoted with ", ' or `
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma // only in column expressions
+	tokName  // `quoted` column name, only in column expressions
+)
+
+// describe names the token in error messages
+func (t filterToken) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "the end"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return "\"" + t.text + "\""
+}
+
+// isKeyword reports whether t is the word kw, in any case
+func (t filterToken) isKeyword(kw string) bool {
+	return t.kind == tokWord && strings.EqualFold(t.text, kw)
+}
+
+// filterOpChars are the characters operators are made of, they end words
+const filterOpChars = "=!<>~&|"
+
+// lexFilter splits an expression into tokens
+func lexFilter(text string) ([]filterToken, error) {
+	var toks []filterToken
+	pos := 1
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		start := pos
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+			pos++
+			continue
+		case r == '(' || r == ')':
+			kind := tokLParen
+			if r == ')' {
+				kind = tokRParen
+			}
+			toks = append(toks, filterToken{kind, string(r), start})
+			i++
+			pos++
+			continue
+		case r == '"' || r == '\'' || r == '`':
+			var sb strings.Builder
+			j := i + 1
+			pos++
+			closed := false
+			for j < len(text) {
+				c, n := utf8.DecodeRuneInString(text[j:])
+				j += n
+				pos++
+				if c == r {
+					closed = true
+					break
+				}
+				// Backslash escapes the quote and itself, except in `raw` names
+				if c == '\\' && r != '`' && j < len(text) {
+					if next, n := utf8.DecodeRuneInString(text[j:]); next == r || next == '\\' {
+						c = next
+						j += n
+						pos++
+					}
+				}
+				sb.WriteRune(c)
+			}
+			if !closed {
+				return nil, errors.New("position " + strconv.Itoa(start) + ": the quote is never closed")
+			}
+			toks = append(toks, filterToken{tokString, sb.String(), start})
+			i = j
+			continue
+		case strings.ContainsRune(filterOpChars, r):
+			op := text[i : i+1]
+			if i+1 < len(text) {
+				if two := text[i : i+2]; two == "==" || two == "!=" || two == "!~" || two == ">=" || two == "<=" || two == "&&" || two == "||" {
+					op = two
+				}
+			}
+			if op == "&" || op == "|" {
+				return nil, errors.New("position " + strconv.Itoa(start) + ": unknown operator \"" + op + "\", use AND (&&) or OR (||)")
+			}
+			toks = append(toks, filterToken{tokOp, op, start})
+			i += len(op)
+			pos += len(op)
+			continue
+		}
+		// A word runs to the next space, parenthesis, quote or operator
+		j := i
+		for j < len(text) {
+			c, n := utf8.DecodeRuneInString(text[j:])
+			if unicode.IsSpace(c) || strings.ContainsRune("()\"'`"+filterOpChars, c) {
+				break
+			}
+			j += n
+			pos++
+		}
+		toks = append(toks, filterToken{tokWord, text[i:j], start})
+		i = j
+	}
+	return append(toks, filterToken{tokEOF, "", pos}), nil
+}
+
+// filterParser builds filter nodes from tokens, resolving columns against the
+// header of b
+type filterParser struct {
+	toks          []filterToken
+	i             int
+	b             *Buffer
+	caseSensitive bool
+}
+
+// parseFilterExpr parses an expression over the columns of nb. Columns are
+// named by header or by number as $3, numbers start at 1 like --columns.
+func parseFilterExpr(text string, nb *Buffer, caseSensitive bool) (*rowFilter, error) {
+	toks, err := lexFilter(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{toks: toks, b: nb, caseSensitive: caseSensitive}
+	if p.peek().kind == tokEOF {
+		return nil, errors.New("the expression is empty")
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		if t.kind == tokRParen {
+			return nil, p.errorAt(t, "\")\" has no matching \"(\"")
+		}
+		return nil, p.errorAt(t, "expected AND, OR or the end, found "+t.describe())
+	}
+	return &rowFilter{text: strings.TrimSpace(text), caseSensitive: caseSensitive, root: root}, nil
+}
+
+func (p *filterParser) peek() filterToken { return p.toks[p.i] }
+
+func (p *filterParser) next() filterToken {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+func (p *filterParser) errorAt(t filterToken, msg string) error {
+	return tokenError(t, msg)
+}
+
+// tokenError returns msg as an error at the position of t
+func tokenError(t filterToken, msg string) error {
+	return errors.New("position " + strconv.Itoa(t.pos) + ": " + msg)
+}
+
+// parseOr parses: and { (OR | ||) and }
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.isKeyword("OR") || (t.kind == tokOp && t.text == "||"); t = p.peek() {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: not { (AND | &&) not }
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for t := p.peek(); t.isKeyword("AND") || (t.kind == tokOp && t.text == "&&"); t = p.peek() {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left, right}
+	}
+	return left, nil
+}
+
+// parseNot parses: (NOT | !) not | "(" or ")" | comparison
+func (p *filterParser) parseNot() (filterNode, error) {
+	t := p.peek()
+	switch {
+	case t.isKeyword("NOT") || (t.kind == tokOp && t.text == "!"):
+		p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{x}, nil
+	case t.kind == tokLParen:
+		p.next()
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorAt(closing, "expected \")\" to close the \"(\" at position "+strconv.Itoa(t.pos)+", found "+closing.describe())
+		}
+		return x, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses: column operator value
+func (p *filterParser) parseComparison() (filterNode, error) {
+	t := p.next()
+	if (t.kind != tokWord && t.kind != tokString) || t.isKeyword("AND") || t.isKeyword("OR") {
+		return nil, p.errorAt(t, "expected a column, found "+t.describe())
+	}
+	col, err := p.column(t)
+	if err != nil {
+		return nil, err
+	}
+
+	opTok := p.next()
+	op := strings.ToLower(opTok.text)
+	// "starts with" and "ends with" may be written as two words
+	if opTok.kind == tokWord && (op == "starts" || op == "ends") && p.peek().isKeyword("with") {
+		p.next()
+		op += "with"
+	}
+	name, ok := filterOperators[op]
+	if !ok || (opTok.kind != tokOp && opTok.kind != tokWord) {
+		return nil, p.errorAt(opTok, "expected an operator (== != ~ !~ > < >= <= contains startswith endswith) after "+t.describe()+", found "+opTok.describe())
+	}
+
+	valTok := p.next()
+	if valTok.kind != tokWord && valTok.kind != tokString {
+		return nil, p.errorAt(valTok, "expected a value after "+opTok.describe()+", found "+valTok.describe())
+	}
+
+	cond := filterCond{col: col, colType: p.b.getColType(col)}
+	cond.negate = strings.HasPrefix(name, "!")
+	cond.opts = FilterOptions{Query: valTok.text, Operator: strings.TrimPrefix(name, "!"), CaseSensitive: p.caseSensitive}
+	switch cond.opts.Operator {
+	case "regex":
+		pattern := valTok.text
+		if !p.caseSensitive {
+			pattern = "(?i)" + pattern
+		}
+		if cond.re, err = regexp.Compile(pattern); err != nil {
+			return nil, p.errorAt(valTok, "invalid regular expression: "+err.Error())
+		}
+	case ">", "<", ">=", "<=":
+		switch cond.colType {
+		case colTypeFloat:
+			if _, err := strconv.ParseFloat(strings.TrimSpace(valTok.text), 64); err != nil {
+				return nil, p.errorAt(valTok, opTok.text+" needs a number, found "+valTok.describe())
+			}
+		case colTypeDate:
+			if cond.day = parseDateValueFast(valTok.text); cond.day == 0 {
+				return nil, p.errorAt(valTok, opTok.text+" needs a date like 2024-01-31, found "+valTok.describe())
+			}
+		default:
+			return nil, p.errorAt(opTok, "column "+t.describe()+" holds text, "+opTok.text+" compares numbers and dates (t changes the column type)")
+		}
+	}
+	return cond, nil
+}
+
+// column resolves a column token: a header name, or $N for column N
+func (p *filterParser) column(t filterToken) (int, error) {
+	return exprColumn(p.b, t)
+}
+
+// exprColumn resolves a column named in an expression over nb: a header
+// name, in any case when that is not ambiguous, or $N for column N
+func exprColumn(nb *Buffer, t filterToken) (int, error) {
+	if t.kind == tokWord && strings.HasPrefix(t.text, "$") && isDigits(t.text[1:]) && len(t.text) > 1 {
+		n, err := strconv.Atoi(t.text[1:])
+		if err != nil || n < 1 || n > nb.colLen {
+			return 0, tokenError(t, "column "+t.text+" does not exist, the table has "+strconv.Itoa(nb.colLen)+" columns")
+		}
+		return n - 1, nil
+	}
+	if nb.rowFreeze == 0 || nb.rowLen == 0 {
+		return 0, tokenError(t, "the table has no header row, name columns by number like $1")
+	}
+	header := nb.cont[0]
+	for i, name := range header {
+		if name == t.text {
+			return i, nil
+		}
+	}
+	// Names are matched in any case when that is not ambiguous
+	found := -1
+	for i, name := range header {
+		if strings.EqualFold(name, t.text) {
+			if found >= 0 {
+				return 0, tokenError(t, "column "+t.describe()+" matches several columns, write it as in the header")
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return 0, tokenError(t, "unknown column "+t.describe()+", quote names with spaces like `unit price`")
+	}
+	return found, nil
+}
+
+// filterByExpr returns a new buffer with the rows of b that f accepts
+func (b *Buffer) filterByExpr(f *rowFilter) *Buffer {
+	return b.filterRows(f.root.match)
+}
+
+// activeFilterCount returns the number of column filters plus the expression
+func activeFilterCount() int {
+	n := len(activeFilters)
+	if filterExpr != nil {
+		n++
+	}
+	return n
+}
+
+// applyFilters filters originalBuffer by the column filters and then the
+// filter expression, the result is the buffer to display
+func applyFilters() *Buffer {
+	filtered := originalBuffer
+	for col, opts := range activeFilters {
+		filtered = filtered.filterByColumn(col, opts)
+	}
+	if filterExpr != nil {
+		filtered = filtered.filterByExpr(filterExpr)
+	}
+	return filtered
+}
+
+// showFilterExprForm edits the filter expression in a modal. Syntax errors
+// are shown in the form, an empty expression removes it.
+func showFilterExprForm() {
+	form := tview.NewForm()
+	closeForm := func() { closeModalForm("filterExprModal") }
+
+	text, caseSensitive := "", false
+	if filterExpr != nil {
+		text, caseSensitive = filterExpr.text, filterExpr.caseSensitive
+	}
+	const hint = "[gray]status == \"failed\" OR (retries > 3 AND NOT region ~ \"^eu\")[-]"
+	form.AddInputField("Expression:", text, 60, nil, nil)
+	form.AddCheckbox("Case Sensitive:", caseSensitive, func(checked bool) {
+		caseSensitive = checked
+	})
+	form.AddTextView("", hint, 60, 2, true, false)
+	exprField := form.GetFormItem(0).(*tview.InputField)
+	message := form.GetFormItem(2).(*tview.TextView)
+	showError := func(msg string) {
+		message.SetText("[red]" + tview.Escape(msg) + "[-]")
+	}
+
+	apply := func() {
+		text := strings.TrimSpace(exprField.GetText())
+		source := b
+		if originalBuffer != nil {
+			source = originalBuffer
+		}
+
+		var f *rowFilter
+		if text != "" {
+			var err error
+			if f, err = parseFilterExpr(text, source, caseSensitive); err != nil {
+				showError(err.Error())
+				return
+			}
+		} else if filterExpr == nil {
+			closeForm()
+			return
+		}
+
+		previous := filterExpr
+		filterExpr = f
+		if originalBuffer == nil {
+			originalBuffer = b // Save original buffer first time
+		}
+		_, column := bufferTable.GetSelection()
+		if activeFilterCount() == 0 {
+			b = originalBuffer
+			isFiltered = false
+			closeForm()
+			drawBuffer(b, bufferTable)
+			bufferTable.Select(0, column)
+			updateFooterWithStatus("All filters cleared - showing all rows")
+			return
+		}
+
+		updateFooterWithStatus("Filtering...")
+		app.ForceDraw()
+		filtered := applyFilters()
+		if filtered.rowLen <= filtered.rowFreeze {
+			filterExpr = previous
+			showError("No rows match, the expression was not applied")
+			return
+		}
+		b = filtered
+		isFiltered = true
+		closeForm()
+		drawBuffer(b, bufferTable)
+		bufferTable.Select(0, column)
+		updateFooterWithStatus(fmt.Sprintf("Filtered: %d rows match (%d filters active, & to edit)", b.rowLen-b.rowFreeze, activeFilterCount()))
+	}
+
+	title := " 🔎 Filter Expression - Enter to filter, Esc to cancel "
+	if filterExpr != nil {
+		title = " 🔎 Edit Filter Expression (empty to remove) - Enter to apply, Esc to cancel "
+	}
+	showModalForm("filterExprModal", form, title, "Filter", apply, 90, 11)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newFilterExprTestBuffer(t *testing.T) *Buffer {
+	t.Helper()
+	return newTestBuffer(t, [][]string{
+		{"id", "status", "retries", "region", "unit price", "day"},
+		{"1", "failed", "0", "eu-west", "3.5", "2024-01-05"},
+		{"2", "ok", "5", "us-east", "10", "2024-02-01"},
+		{"3", "FAILED", "4", "EU-north", "7", "2024-03-01"},
+		{"4", "ok", "1", "ap-south", "1", "2023-12-31"},
+		{"5", "failed", "2", "us-west", "2.25", "2024-01-20"},
+	}, colTypeFloat, colTypeStr, colTypeFloat, colTypeStr, colTypeFloat, colTypeDate)
+}
+
+func TestFilterExpr(t *testing.T) {
+	b := newFilterExprTestBuffer(t)
+	tests := []struct {
+		expr          string
+		caseSensitive bool
+		want          []string // ids of the matching rows
+	}{
+		{`status == "failed"`, false, []string{"1", "3", "5"}},
+		{`status == "failed"`, true, []string{"1", "5"}},
+		{`(status == "failed" OR retries > 3) AND NOT region ~ "^eu"`, false, []string{"2", "5"}},
+		{`status = failed || retries >= 5 && region contains east`, false, []string{"1", "2", "3", "5"}},
+		{`!(status != ok)`, false, []string{"2", "4"}},
+		{`region !~ "-(west|east)$"`, false, []string{"3", "4"}},
+		{"`unit price` < 3", false, []string{"4", "5"}},
+		{`$4 starts with us and $3 <= 2`, false, []string{"5"}},
+		{`REGION endswith "south"`, false, []string{"4"}},
+		{`day >= 2024-01-20 and day < 2024-03-01`, false, []string{"2", "5"}},
+		{`not not id == 1`, false, []string{"1"}},
+		{`status == 'it\'s'`, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := parseFilterExpr(tt.expr, b, tt.caseSensitive)
+			if err != nil {
+				t.Fatalf("parseFilterExpr() error = %v", err)
+			}
+			filtered := b.filterByExpr(f)
+			var got []string
+			for _, row := range filtered.cont[1:] {
+				got = append(got, row[0])
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterExprErrors(t *testing.T) {
+	b := newFilterExprTestBuffer(t)
+	tests := []struct {
+		expr string
+		want string // part of the error message
+	}{
+		{``, "empty"},
+		{`status ==`, `position 10: expected a value after "=="`},
+		{`status failed`, `expected an operator`},
+		{`(status == ok`, `expected ")" to close the "(" at position 1`},
+		{`status == ok)`, `")" has no matching "("`},
+		{`status == ok region == eu`, `expected AND, OR or the end, found "region"`},
+		{`city == Oslo`, `unknown column "city"`},
+		{`$9 == 1`, `column $9 does not exist`},
+		{`status > 3`, `holds text`},
+		{`retries > many`, `needs a number`},
+		{`day > soon`, `needs a date`},
+		{`region ~ "("`, `invalid regular expression`},
+		{`status == "open`, `position 11: the quote is never closed`},
+		{`status == ok & retries > 1`, `use AND (&&) or OR (||)`},
+		{`AND status == ok`, `expected a column, found "AND"`},
+	}
+	for _, tt := range tests {
+		_, err := parseFilterExpr(tt.expr, b, false)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseFilterExpr(%q) error = %v, want it to mention %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestFilterExprWithColumnFilters(t *testing.T) {
+	defer func() { originalBuffer, activeFilters, filterExpr = nil, nil, nil }()
+	originalBuffer = newFilterExprTestBuffer(t)
+	activeFilters = map[int]FilterOptions{3: {Query: "us", Operator: "starts with"}}
+	var err error
+	if filterExpr, err = parseFilterExpr(`retries > 3 or id == 5`, originalBuffer, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := applyFilters(); got.rowLen != 3 || got.cont[1][0] != "2" || got.cont[2][0] != "5" {
+		t.Errorf("applyFilters() = %q", got.cont)
+	}
+	if n := activeFilterCount(); n != 2 {
+		t.Errorf("activeFilterCount() = %d, want 2", n)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// widthsToStarts turns the --widths column widths into column start offsets
+func widthsToStarts(widths []int) ([]int, error) {
+	starts := make([]int, len(widths))
+	pos := 0
+	for i, w := range widths {
+		if w <= 0 {
+			return nil, errors.New("column widths must be positive")
+		}
+		starts[i] = pos
+		pos += w
+	}
+	return starts, nil
+}
+
+// inferColumnStarts finds column boundaries in whitespace-aligned text, like
+// the output of ps or kubectl. A column is a run of character positions that
+// hold text in at least one line; runs with nothing in the header (first line)
+// belong to the column before them, so spaces inside a last free-text column
+// do not split it.
+func inferColumnStarts(lines []string) []int {
+	if len(lines) == 0 {
+		return nil
+	}
+	var occupied []bool
+	for _, line := range lines {
+		for i, r := range []rune(line) {
+			if i >= len(occupied) {
+				occupied = append(occupied, make([]bool, i-len(occupied)+1)...)
+			}
+			if !unicode.IsSpace(r) {
+				occupied[i] = true
+			}
+		}
+	}
+
+	header := []rune(lines[0])
+	var starts []int
+	for i := 0; i < len(occupied); i++ {
+		if !occupied[i] || (i > 0 && occupied[i-1]) {
+			continue
+		}
+		end := i
+		for end < len(occupied) && occupied[end] {
+			end++
+		}
+		switch {
+		case len(starts) == 0:
+			// Keep leading spaces of right-aligned first columns
+			starts = append(starts, 0)
+		case hasText(header, i, end):
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// hasText checks whether runes[start:end] holds anything but spaces
+func hasText(runes []rune, start, end int) bool {
+	for i := start; i < end && i < len(runes); i++ {
+		if !unicode.IsSpace(runes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// looksAligned checks whether sample lines are padded with runs of spaces,
+// which a single-space separator would turn into empty columns
+func looksAligned(lines []string) bool {
+	for _, line := range lines {
+		if strings.Contains(strings.TrimSpace(line), "  ") {
+			return true
+		}
+	}
+	return false
+}
+
+// useFixedWidth switches b to fixed-width parsing when force is set or when
+// separator detection failed or picked a space on whitespace-aligned lines
+func useFixedWidth(b *Buffer, lines []string, force bool) {
+	if !force && b.sep != "" && (b.sep != " " || !looksAligned(lines)) {
+		return
+	}
+	starts := inferColumnStarts(lines)
+	if !force && len(starts) < 2 {
+		return
+	}
+	if len(starts) == 0 {
+		starts = []int{0}
+	}
+	b.colStarts = starts
+	b.sep = " "
+}
+
+// splitFixedWidth cuts a line at the given column start offsets (in runes)
+// and trims the padding, the last column runs to the end of the line
+func splitFixedWidth(line string, starts []int) []string {
+	fields := make([]string, len(starts))
+	if !isASCII(line) {
+		runes := []rune(line)
+		for i, start := range starts {
+			if start >= len(runes) {
+				break
+			}
+			end := len(runes)
+			if i+1 < len(starts) && starts[i+1] < end {
+				end = starts[i+1]
+			}
+			fields[i] = strings.TrimSpace(string(runes[start:end]))
+		}
+		return fields
+	}
+	for i, start := range starts {
+		if start >= len(line) {
+			break
+		}
+		end := len(line)
+		if i+1 < len(starts) && starts[i+1] < end {
+			end = starts[i+1]
+		}
+		fields[i] = strings.TrimSpace(line[start:end])
+	}
+	return fields
+}
+
+// isASCII reports whether byte offsets and rune offsets of s are the same
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
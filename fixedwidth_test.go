@@ -0,0 +1,169 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInferColumnStarts(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []int
+	}{
+		{
+			"left aligned",
+			[]string{"NAME   READY  AGE", "web-1  1/1    3d", "db     0/1    12d"},
+			[]int{0, 7, 14},
+		},
+		{
+			"right aligned numbers",
+			[]string{"USER   PID CMD", "root     1 init", "bob  12412 sh"},
+			[]int{0, 5, 11},
+		},
+		{
+			"spaces inside last column",
+			[]string{"ID  COMMAND", "1   sleep 10", "2   ls -la /tmp"},
+			[]int{0, 4},
+		},
+		{
+			"header with a space",
+			[]string{"CONTAINER ID   IMAGE", "a1b2c3d4e5f6   nginx"},
+			[]int{0, 15},
+		},
+		{"empty", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inferColumnStarts(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("inferColumnStarts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitFixedWidth(t *testing.T) {
+	tests := []struct {
+		line   string
+		starts []int
+		want   []string
+	}{
+		{"web-1  1/1    3d", []int{0, 7, 14}, []string{"web-1", "1/1", "3d"}},
+		{"web-1  1/1", []int{0, 7, 14}, []string{"web-1", "1/1", ""}},
+		{"1   ls -la /tmp", []int{0, 4}, []string{"1", "ls -la /tmp"}},
+		{"zürich 12", []int{0, 7}, []string{"zürich", "12"}},
+		{"", []int{0, 5}, []string{"", ""}},
+	}
+	for _, tt := range tests {
+		if got := splitFixedWidth(tt.line, tt.starts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitFixedWidth(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestWidthsToStarts(t *testing.T) {
+	starts, err := widthsToStarts([]int{4, 2, 6})
+	if err != nil || !reflect.DeepEqual(starts, []int{0, 4, 6}) {
+		t.Errorf("widthsToStarts() = %v, %v, want [0 4 6]", starts, err)
+	}
+	if _, err := widthsToStarts([]int{4, 0}); err == nil {
+		t.Error("widthsToStarts() should reject a zero width")
+	}
+}
+
+func TestUseFixedWidth(t *testing.T) {
+	aligned := []string{"NAME   READY  AGE", "web-1  1/1    3d"}
+
+	b := createNewBuffer()
+	b.sep = ","
+	useFixedWidth(b, aligned, false)
+	if b.colStarts != nil {
+		t.Error("A detected separator should be kept")
+	}
+
+	b = createNewBuffer()
+	b.sep = " "
+	useFixedWidth(b, []string{"a b c", "1 2 3"}, false)
+	if b.colStarts != nil {
+		t.Error("Single-space separated lines should keep the space separator")
+	}
+
+	b = createNewBuffer()
+	b.sep = ","
+	useFixedWidth(b, aligned, true)
+	if !reflect.DeepEqual(b.colStarts, []int{0, 7, 14}) {
+		t.Errorf("Forced fixed width: colStarts = %v", b.colStarts)
+	}
+}
+
+func TestLoadFixedWidthFiles(t *testing.T) {
+	tests := []struct {
+		fn      string
+		header  []string
+		lastRow []string
+	}{
+		{
+			"./data/test/kubectl_pods.txt",
+			[]string{"NAME", "READY", "STATUS", "RESTARTS", "AGE"},
+			[]string{"redis-0", "1/1", "Running", "0", "12d"},
+		},
+		{
+			"./data/test/ps_aux.txt",
+			[]string{"USER", "PID", "%CPU", "%MEM", "VSZ", "RSS", "TTY", "STAT", "START", "TIME", "COMMAND"},
+			[]string{"alice", "23817", "1.2", "3.4", "2841220", "278516", "pts/1", "Sl+", "09:14", "2:31", "python train.py --epochs 10"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fn, func(t *testing.T) {
+			b := createNewBuffer()
+			if err := loadFileToBuffer(tt.fn, b); err != nil {
+				t.Fatalf("loadFileToBuffer() error = %v", err)
+			}
+			if !reflect.DeepEqual(b.cont[0], tt.header) {
+				t.Errorf("header = %q, want %q", b.cont[0], tt.header)
+			}
+			if last := b.cont[b.rowLen-1]; !reflect.DeepEqual(last, tt.lastRow) {
+				t.Errorf("last row = %q, want %q", last, tt.lastRow)
+			}
+		})
+	}
+
+	// kubectl get pods | ftv
+	data, err := os.ReadFile("./data/test/kubectl_pods.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := createNewBuffer()
+	updateChan := make(chan bool, 10)
+	doneChan := make(chan error, 1)
+	go loadPipeToBufferAsync(strings.NewReader(string(data)), b, updateChan, doneChan)
+	if err := <-doneChan; err != nil {
+		t.Fatalf("loadPipeToBufferAsync() error = %v", err)
+	}
+	if b.rowLen != 5 || b.colLen != 5 || b.cont[2][3] != "1 (2d ago)" {
+		t.Errorf("Unexpected pipe buffer %q", b.cont)
+	}
+}
+
+func TestLoadFileToBuffer_Widths(t *testing.T) {
+	fn := t.TempDir() + "/extract.dat"
+	if err := os.WriteFile(fn, []byte("ID  NAME  AMT\n0001ALICE 00120\n0002BOB   00075\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	starts, err := widthsToStarts([]int{4, 6, 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := createNewBuffer()
+	b.colStarts = starts
+	b.sep = " "
+	if err := loadFileToBuffer(fn, b); err != nil {
+		t.Fatalf("loadFileToBuffer() error = %v", err)
+	}
+	want := [][]string{{"ID", "NAME", "AMT"}, {"0001", "ALICE", "00120"}, {"0002", "BOB", "00075"}}
+	if !reflect.DeepEqual(b.cont, want) {
+		t.Errorf("buffer = %q, want %q", b.cont, want)
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// followPollInterval is how often a followed file is checked for new data
+const followPollInterval = 250 * time.Millisecond
+
+// followSampleWait is how long separator detection waits for the next line
+// of a followed pipe before it goes ahead with the lines it has
+const followSampleWait = 500 * time.Millisecond
+
+// followReader reads a file like tail -F does: at the end of the file it waits
+// for more data instead of returning io.EOF, and it starts over when the file
+// is truncated or replaced by a new one (log rotation)
+type followReader struct {
+	fn     string
+	file   *os.File
+	info   os.FileInfo // identity of the open file, to notice rotation
+	offset int64
+	poll   time.Duration
+	stop   chan struct{}
+
+	header     []byte // first line of the file, left out when a new file repeats it
+	headerDone bool
+	restarted  bool   // reading the first line of a truncated or rotated file
+	held       []byte // data of a restarted file not returned yet
+}
+
+// newFollowReader opens fn for following, compressed files cannot grow line by
+// line and are refused
+func newFollowReader(fn string) (*followReader, error) {
+	head, err := readFileHead(fn, compressMagicLen)
+	if err != nil {
+		return nil, err
+	}
+	if detectCompression(head) != compressNone {
+		return nil, errors.New("follow mode needs an uncompressed file")
+	}
+	file, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &followReader{fn: fn, file: file, info: info, poll: followPollInterval, stop: make(chan struct{})}, nil
+}
+
+// Read blocks until data is available or the reader is closed
+func (fr *followReader) Read(p []byte) (int, error) {
+	for {
+		if len(fr.held) > 0 && !fr.restarted {
+			n := copy(p, fr.held)
+			fr.held = fr.held[n:]
+			return n, nil
+		}
+
+		n, err := fr.file.Read(p)
+		fr.offset += int64(n)
+		if n > 0 {
+			if fr.restarted {
+				fr.held = append(fr.held, p[:n]...)
+				fr.dropRepeatedHeader()
+				continue
+			}
+			fr.noteHeader(p[:n])
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		if replaced, err := fr.reopenIfReplaced(); err != nil {
+			return 0, err
+		} else if replaced {
+			continue
+		}
+		select {
+		case <-fr.stop:
+			fr.file.Close()
+			return 0, io.EOF
+		case <-time.After(fr.poll):
+		}
+	}
+}
+
+// Close makes a waiting Read return io.EOF, which ends the loader
+func (fr *followReader) Close() error {
+	close(fr.stop)
+	return nil
+}
+
+// noteHeader records the first line as it passes through
+func (fr *followReader) noteHeader(data []byte) {
+	if fr.headerDone {
+		return
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		fr.header = append(fr.header, data[:i+1]...)
+		fr.headerDone = true
+		return
+	}
+	fr.header = append(fr.header, data...)
+}
+
+// dropRepeatedHeader leaves out the first line of a restarted file when it is
+// the header again, which is decided as soon as that line is complete or
+// differs from the header
+func (fr *followReader) dropRepeatedHeader() {
+	if i := bytes.IndexByte(fr.held, '\n'); i >= 0 {
+		if fr.headerDone && bytes.Equal(fr.held[:i+1], fr.header) {
+			fr.held = fr.held[i+1:]
+		}
+		fr.restarted = false
+	} else if !bytes.HasPrefix(fr.header, fr.held) {
+		fr.restarted = false
+	}
+}
+
+// reopenIfReplaced starts over at the beginning when the file was truncated,
+// or opens the new file when the path now points to another one
+func (fr *followReader) reopenIfReplaced() (bool, error) {
+	info, err := os.Stat(fr.fn)
+	if err != nil {
+		// Moved away and not recreated yet, keep waiting
+		return false, nil
+	}
+	if !os.SameFile(info, fr.info) {
+		file, err := os.Open(fr.fn)
+		if err != nil {
+			return false, nil
+		}
+		fr.file.Close()
+		fr.file, fr.info = file, info
+		fr.offset = 0
+		fr.restarted = true
+		return true, nil
+	}
+	if info.Size() < fr.offset {
+		if _, err := fr.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		fr.offset = 0
+		fr.restarted = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// detectFollowSeparator picks the separator from the lines already in the
+// file, so that a short file does not keep the loader waiting for ten lines
+func detectFollowSeparator(fn string, b *Buffer) error {
+	if b.sep != "" {
+		return nil
+	}
+	file, err := os.Open(fn)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	rr := newOffsetRecordReader(file, 0, dialect{}, true)
+	for i := 0; i < args.SkipNum; i++ {
+		if _, _, err := rr.next(); err != nil {
+			break
+		}
+	}
+	if _, err := rr.r.Peek(1); err != nil {
+		// Nothing to look at yet, the suffix is all there is
+		switch {
+		case strings.HasSuffix(plainFileName(fn), ".csv"):
+			b.sep = ","
+		case strings.HasSuffix(plainFileName(fn), ".tsv"):
+			b.sep = "\t"
+		}
+		return nil
+	}
+	return detectIndexedSeparator(fn, file, rr.offset, b)
+}
+
+// scannedLine is a line read for separator detection, ok is false at the end
+// of the input
+type scannedLine struct {
+	text string
+	ok   bool
+}
+
+// sampleFollowedLines reads up to n lines with next for separator detection.
+// A followed pipe writes lines when it likes, so once a line has come and no
+// other follows within followSampleWait, detection goes ahead with the lines
+// it has. The line still being read then arrives on pending, and next must
+// not be called before it has; pending is nil when nothing is being read.
+func sampleFollowedLines(next func() (string, bool), n int) (lines []string, pending <-chan scannedLine) {
+	read := make(chan scannedLine, 1)
+	for len(lines) < n {
+		go func() {
+			text, ok := next()
+			read <- scannedLine{text, ok}
+		}()
+		var wait <-chan time.Time
+		if len(lines) > 0 {
+			wait = time.After(followSampleWait)
+		}
+		select {
+		case l := <-read:
+			if !l.ok {
+				return lines, nil
+			}
+			lines = append(lines, l.text)
+		case <-wait:
+			return lines, read
+		}
+	}
+	return lines, nil
+}
+
+// followFile shows fn and keeps adding the rows appended to it
+func followFile(fn string, format int) error {
+	var loader func(io.Reader, *Buffer, chan<- bool, chan<- error)
+	switch format {
+	case formatDelimited:
+		if err := detectFollowSeparator(fn, b); err != nil {
+			return err
+		}
+		loader = loadPipeToBufferAsync
+	case formatJSONLines:
+		loader = loadJSONLPipeToBufferAsync
+	default:
+		return errors.New("follow mode needs a delimited or JSON Lines file")
+	}
+
+	fr, err := newFollowReader(fn)
+	if err != nil {
+		return err
+	}
+	// Ends the loader waiting for more data once the UI is closed
+	defer fr.Close()
+	return loadAndDisplayAsync(func(b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+		go func() {
+			// Guessing the encoding waits for the first data, so it happens here
+			r, err := decodePipe(bufio.NewReader(fr))
+			if err != nil {
+				doneChan <- err
+				return
+			}
+			loader(r, b, updateChan, doneChan)
+		}()
+	}, "File")
+}
+
+// followView tracks the table between redraws in follow mode
+type followView struct {
+	dropped int // dropped rows already accounted for in the selection
+	started bool
+}
+
+// update redraws the table with the new rows. The newest row stays in view
+// while auto-scroll is on and the cursor is on the last row; moving away from
+// it pauses scrolling until G brings the cursor back.
+func (fv *followView) update() {
+	// A filtered view is a copy, new rows show up once the filter is removed
+	if isFiltered {
+		return
+	}
+	row, col := bufferTable.GetSelection()
+	atBottom := !fv.started || row >= bufferTable.GetRowCount()-1
+
+	appended := b.appendedRows()
+	b.mu.RLock()
+	shift := b.droppedRows - fv.dropped
+	fv.dropped = b.droppedRows
+	b.mu.RUnlock()
+
+	// Types are guessed from the first rows, keep guessing until there are enough
+	if appended-b.rowFreeze <= 100 {
+		b.detectAllColumnTypes()
+	}
+
+	drawBuffer(b, bufferTable)
+	last := b.rowCount() - 1
+	switch {
+	case followScroll && atBottom:
+		bufferTable.Select(last, col)
+		bufferTable.ScrollToEnd()
+	case shift > 0:
+		// Keep the cursor on the same record while old rows are dropped
+		row -= shift
+		if row < b.rowFreeze {
+			row = b.rowFreeze
+		}
+		bufferTable.Select(row, col)
+	}
+	fv.started = true
+	updateFooterWithStatus(followStatus(followScroll && atBottom))
+}
+
+// followStatus is the footer status in follow mode
+func followStatus(scrolling bool) string {
+	status := "Following... " + strconv.Itoa(b.rowCount()) + " rows"
+	switch {
+	case !followScroll:
+		status += " (auto-scroll off, F to turn on)"
+	case !scrolling:
+		status += " (paused, G to resume)"
+	}
+	return status
+}
+
+// toggleFollowScroll turns auto-scroll on or off, turning it on jumps to the
+// newest row
+func toggleFollowScroll() {
+	if !args.Follow {
+		updateFooterWithStatus("Not following, start ftv with -F to follow a file")
+		return
+	}
+	followScroll = !followScroll
+	if followScroll {
+		_, col := bufferTable.GetSelection()
+		bufferTable.Select(b.rowCount()-1, col)
+		bufferTable.ScrollToEnd()
+	}
+	updateFooterWithStatus(followStatus(followScroll))
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// appendToFile appends data to fn like a program writing a log would
+func appendToFile(t *testing.T, fn, data string) {
+	t.Helper()
+	f, err := os.OpenFile(fn, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readFollowLine reads the next line from a follow scanner, failing the test
+// when nothing arrives in time
+func readFollowLine(t *testing.T, lines <-chan string) string {
+	t.Helper()
+	select {
+	case line := <-lines:
+		return line
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for a followed line")
+		return ""
+	}
+}
+
+func TestFollowReader(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "app.log")
+	appendToFile(t, fn, "time,msg\n1,start\n")
+
+	fr, err := newFollowReader(fn)
+	if err != nil {
+		t.Fatalf("newFollowReader() error = %v", err)
+	}
+	fr.poll = 5 * time.Millisecond
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(fr)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	want := func(expected string) {
+		t.Helper()
+		if got := readFollowLine(t, lines); got != expected {
+			t.Errorf("Read line %q, want %q", got, expected)
+		}
+	}
+	want("time,msg")
+	want("1,start")
+
+	// Appended data
+	appendToFile(t, fn, "2,running\n")
+	want("2,running")
+
+	// Truncated and rewritten, the repeated header is left out
+	time.Sleep(20 * time.Millisecond)
+	if err := os.WriteFile(fn, []byte("time,msg\n3,truncated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want("3,truncated")
+
+	// Rotated: the file is moved away and a new one takes its place
+	if err := os.Rename(fn, fn+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendToFile(t, fn, "time,msg\n4,rotated\n")
+	want("4,rotated")
+
+	// A new file without the header keeps its first line
+	os.Remove(fn)
+	appendToFile(t, fn, "5,no header\n")
+	want("5,no header")
+
+	fr.Close()
+	if _, ok := <-lines; ok {
+		t.Error("Expected the reader to end after Close")
+	}
+}
+
+func TestNewFollowReaderCompressed(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "app.log.gz")
+	if err := os.WriteFile(fn, compressTestData(t, compressGzip, []byte("a,b\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newFollowReader(fn); err == nil {
+		t.Error("Following a compressed file should fail")
+	}
+}
+
+func TestBufferMaxRows(t *testing.T) {
+	b := createNewBuffer()
+	b.maxRows = 3
+	for _, row := range [][]string{{"n"}, {"1"}, {"2"}, {"3"}, {"4"}, {"5"}} {
+		if err := b.contAppendSli(row, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b.rowLen != 4 || len(b.cont) != 4 {
+		t.Fatalf("Expected header and 3 rows, got %d rows", b.rowLen)
+	}
+	for i, want := range []string{"n", "3", "4", "5"} {
+		if b.cont[i][0] != want {
+			t.Errorf("row %d = %q, want %q", i, b.cont[i][0], want)
+		}
+	}
+	if b.droppedRows != 2 || b.appendedRows() != 6 {
+		t.Errorf("droppedRows = %d, appendedRows() = %d, want 2 and 6", b.droppedRows, b.appendedRows())
+	}
+	if want := 4 * b.estimateRowSize([]string{"n"}); b.getMemoryUsage() != want {
+		t.Errorf("Memory usage = %d, want %d", b.getMemoryUsage(), want)
+	}
+
+	// Without a frozen header every row counts
+	b = createNewBuffer()
+	b.rowFreeze = 0
+	b.maxRows = 2
+	for _, row := range [][]string{{"1"}, {"2"}, {"3"}} {
+		b.contAppendSli(row, false)
+	}
+	if b.rowLen != 2 || b.cont[0][0] != "2" {
+		t.Errorf("Unexpected rows %q", b.cont)
+	}
+}
+
+func TestFollowLoader(t *testing.T) {
+	defer args.setDefault()
+	fn := filepath.Join(t.TempDir(), "metrics")
+	appendToFile(t, fn, "host\tcpu\nweb-1\t0.5\n")
+
+	b := createNewBuffer()
+	if err := detectFollowSeparator(fn, b); err != nil {
+		t.Fatalf("detectFollowSeparator() error = %v", err)
+	}
+	if b.sep != "\t" {
+		t.Fatalf("Detected separator %q from two lines, want tab", b.sep)
+	}
+	b.maxRows = 2
+
+	fr, err := newFollowReader(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fr.poll = 5 * time.Millisecond
+	updateChan := make(chan bool, 10)
+	doneChan := make(chan error, 1)
+	go loadPipeToBufferAsync(fr, b, updateChan, doneChan)
+
+	waitRows := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for b.appendedRows() < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out with %d rows, want %d", b.appendedRows(), n)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	waitRows(2)
+	appendToFile(t, fn, "web-2\t0.7\nweb-3\t0.1\n")
+	waitRows(4)
+
+	fr.Close()
+	if err := <-doneChan; err != nil {
+		t.Fatalf("loader error = %v", err)
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.rowLen != 3 || b.cont[0][0] != "host" || b.cont[1][0] != "web-2" || b.cont[2][0] != "web-3" {
+		t.Errorf("Unexpected buffer %q", b.cont)
+	}
+}
+
+func TestFollowPipeShortSample(t *testing.T) {
+	defer args.setDefault()
+	args.Follow = true
+	r, w := io.Pipe()
+	defer w.Close()
+	b := createNewBuffer()
+	updateChan := make(chan bool, 10)
+	doneChan := make(chan error, 1)
+	go loadPipeToBufferAsync(r, b, updateChan, doneChan)
+
+	// Two lines and a quiet pipe are enough to show the table
+	if _, err := io.WriteString(w, "host,cpu\nweb-1,0.5\n"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-updateChan:
+	case err := <-doneChan:
+		t.Fatalf("loader ended with %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for ten lines")
+	}
+	if b.sep != "," || b.appendedRows() != 2 {
+		t.Fatalf("separator %q with %d rows, want ',' with 2", b.sep, b.appendedRows())
+	}
+
+	// The line read while detection gave up is not lost
+	if _, err := io.WriteString(w, "web-2,0.7\n"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if err := <-doneChan; err != nil {
+		t.Fatalf("loader error = %v", err)
+	}
+	if b.rowLen != 3 || b.cont[2][0] != "web-2" {
+		t.Errorf("Unexpected buffer %q", b.cont)
+	}
+}
+
+func TestDetectFollowSeparatorEmpty(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "new.csv")
+	appendToFile(t, fn, "")
+	b := createNewBuffer()
+	if err := detectFollowSeparator(fn, b); err != nil {
+		t.Fatal(err)
+	}
+	if b.sep != "," {
+		t.Errorf("Empty .csv file: separator = %q, want ','", b.sep)
+	}
+}
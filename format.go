@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+)
+
+// input formats, chosen from the file name or by sniffing the content
+const (
+	formatDelimited = iota
+	formatJSONLines
+	formatParquet
+	formatXLSX
+	formatArchive
+	formatSQLite
+	formatArrow
+)
+
+// number of non-empty lines looked at when sniffing content
+const sniffLineCount = 10
+
+// detectFileFormat picks the input format of a file, by magic bytes and
+// extension first and then by looking at the first lines
+func detectFileFormat(fn string) (int, error) {
+	head, err := readFileHead(fn, len(sqliteMagic))
+	if err != nil {
+		return formatDelimited, err
+	}
+	if format, ok := sniffMagic(head); ok {
+		return format, nil
+	}
+	// Databases need random access, so they are recognised for files only
+	if bytes.HasPrefix(head, []byte(sqliteMagic)) {
+		return formatSQLite, nil
+	}
+	if isParquetFile(fn) {
+		return formatParquet, nil
+	}
+	if isArrowFile(fn) {
+		return formatArrow, nil
+	}
+	if isXLSXFile(fn) || (bytes.HasPrefix(head, []byte(zipMagic)) && isXLSXZipFile(fn)) {
+		return formatXLSX, nil
+	}
+	if isArchiveFile(fn, head) {
+		return formatArchive, nil
+	}
+	if isJSONLinesFile(fn) {
+		return formatJSONLines, nil
+	}
+	// An explicit separator, column layout or pattern means the user wants line parsing
+	if args.Sep != "" || len(args.Widths) > 0 || args.FixedWidth || args.Pattern != "" {
+		return formatDelimited, nil
+	}
+
+	reader, closer, err := openFileReader(fn)
+	if err != nil {
+		return formatDelimited, err
+	}
+	defer closer.Close()
+
+	var lines []string
+	scanner := newLineScanner(reader)
+	for scanner.Scan() && len(lines) < sniffLineCount {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			lines = append(lines, scanner.Text())
+		}
+	}
+	return sniffFormat(lines), nil
+}
+
+// detectPipeFormat picks the input format of a pipe from the data that has
+// already arrived, without consuming it
+func detectPipeFormat(r *bufio.Reader) int {
+	if args.Sep != "" || len(args.Widths) > 0 || args.FixedWidth || args.Pattern != "" {
+		return formatDelimited
+	}
+	// Peek only what is buffered so a slow pipe does not block
+	if _, err := r.Peek(1); err != nil {
+		return formatDelimited
+	}
+	head, _ := r.Peek(r.Buffered())
+	if format, ok := sniffMagic(head); ok {
+		return format
+	}
+	// A zip stream cannot be inspected without reading it all, openZipPipe
+	// tells workbooks from archives
+	if bytes.HasPrefix(head, []byte(zipMagic)) {
+		return formatArchive
+	}
+
+	var lines []string
+	for _, line := range bytes.Split(head, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		lines = append(lines, string(line))
+		if len(lines) >= sniffLineCount {
+			break
+		}
+	}
+	// The last line may be cut off unless the whole input is buffered
+	if len(lines) > 1 && !bytes.HasSuffix(head, []byte("\n")) {
+		lines = lines[:len(lines)-1]
+	}
+	return sniffFormat(lines)
+}
+
+// readFileHead returns up to n bytes from the start of a file
+func readFileHead(fn string, n int) ([]byte, error) {
+	file, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	head := make([]byte, n)
+	read, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return head[:read], nil
+}
+
+// sniffMagic recognises binary formats by their leading magic bytes
+func sniffMagic(head []byte) (int, bool) {
+	if bytes.HasPrefix(head, []byte(parquetMagic)) {
+		return formatParquet, true
+	}
+	if bytes.HasPrefix(head, []byte(arrowMagic)) || bytes.HasPrefix(head, []byte(arrowStreamMagic)) || bytes.HasPrefix(head, []byte(featherV1Magic)) {
+		return formatArrow, true
+	}
+	return formatDelimited, false
+}
+
+// sniffFormat guesses the format from sample lines
+func sniffFormat(lines []string) int {
+	if looksLikeJSONLines(lines) {
+		return formatJSONLines
+	}
+	return formatDelimited
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showModalForm styles form like the other dialogs, adds a submit and a
+// Cancel button and shows it centred over the table as page. Enter submits
+// the form unless a dropdown or checkbox takes it, Esc and Cancel close it.
+func showModalForm(page string, form *tview.Form, title, submitLabel string, submit func(), width, height int) {
+	form.AddButton(submitLabel, submit)
+	form.AddButton("Cancel", func() { closeModalForm(page) })
+	form.SetButtonsAlign(tview.AlignCenter)
+	form.SetBorder(true)
+	form.SetTitle(title)
+	form.SetTitleAlign(tview.AlignCenter)
+	form.SetBorderColor(tcell.NewRGBColor(0, 200, 255)) // Bright Blue
+	form.SetBackgroundColor(tcell.NewRGBColor(20, 30, 40))
+	form.SetLabelColor(tcell.NewRGBColor(180, 220, 220))
+	form.SetFieldBackgroundColor(tcell.NewRGBColor(30, 40, 50))
+	form.SetFieldTextColor(tcell.ColorWhite)
+	form.SetButtonBackgroundColor(tcell.NewRGBColor(0, 200, 255))
+	form.SetButtonTextColor(tcell.ColorBlack)
+	for i := 0; i < form.GetFormItemCount(); i++ {
+		switch item := form.GetFormItem(i).(type) {
+		case *tview.TextView:
+			item.SetBackgroundColor(tcell.NewRGBColor(20, 30, 40))
+		case *tview.Checkbox:
+			item.SetFieldBackgroundColor(tcell.NewRGBColor(80, 80, 100)).SetFieldTextColor(tcell.NewRGBColor(0, 255, 255))
+		}
+	}
+	for i := 0; i < form.GetButtonCount(); i++ {
+		form.GetButton(i).SetActivatedStyle(tcell.Style{}.
+			Background(tcell.NewRGBColor(80, 120, 160)).
+			Foreground(tcell.ColorWhite))
+	}
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			closeModalForm(page)
+			return nil
+		}
+		if event.Key() == tcell.KeyEnter {
+			itemIndex, buttonIndex := form.GetFocusedItemIndex()
+			// Dropdowns open and pick with Enter, checkboxes toggle
+			if itemIndex >= 0 {
+				switch form.GetFormItem(itemIndex).(type) {
+				case *tview.DropDown, *tview.Checkbox:
+					return event
+				}
+			}
+			if _, ok := app.GetFocus().(*tview.List); ok {
+				return event
+			}
+			if buttonIndex == 1 {
+				closeModalForm(page)
+				return nil
+			}
+			submit()
+			return nil
+		}
+		return event
+	})
+
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(form, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	UI.AddPage(page, modal, true, true)
+	app.SetFocus(form)
+}
+
+// closeModalForm removes the form shown as page and focuses the table again
+func closeModalForm(page string) {
+	UI.RemovePage(page)
+	app.SetFocus(bufferTable)
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -41,50 +45,74 @@ func validateDataNotEmpty(b *Buffer, source string) error {
 // startAsyncUpdateHandler manages UI updates during async loading
 func startAsyncUpdateHandler(updateChan <-chan bool, doneChan <-chan error) {
 	go func() {
-		ticker := time.NewTicker(20 * time.Millisecond)
-		defer ticker.Stop()
-
-		loadComplete := false
-		for !loadComplete {
-			select {
-			case <-updateChan:
-				// Update available - will be handled by ticker
-			case err := <-doneChan:
-				loadComplete = true
-				if err != nil {
-					fatalError(err)
+		fatalError(runAsyncUpdates(b, updateChan, doneChan))
+	}()
+}
+
+// runAsyncUpdates redraws the view while target loads and returns the loading
+// error when it is done, nothing is drawn while another tab is displayed
+func runAsyncUpdates(target *Buffer, updateChan <-chan bool, doneChan <-chan error) error {
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+
+	loadComplete := false
+	var follow followView
+	seen := 0
+	for !loadComplete {
+		select {
+		case <-updateChan:
+			// Update available - will be handled by ticker
+		case err := <-doneChan:
+			loadComplete = true
+			if err != nil {
+				return err
+			}
+			// Final update
+			app.QueueUpdateDraw(func() {
+				if displayedSource() != target {
+					return
 				}
-				// Final update
-				app.QueueUpdateDraw(func() {
-					drawBuffer(b, bufferTable)
-					updateFooterWithStatus("Loaded " + strconv.Itoa(b.rowLen) + " rows")
-				})
-			case <-ticker.C:
-				// Periodic UI update
-				app.QueueUpdateDraw(func() {
-					drawBuffer(b, bufferTable)
-
-					// Keep cursor on first row if user hasn't moved it
-					if !userMovedCursor {
-						row, col := bufferTable.GetSelection()
-						if row != 0 {
-							bufferTable.Select(0, col)
-						}
-					}
+				drawBuffer(b, bufferTable)
+				updateFooterWithStatus("Loaded " + strconv.Itoa(b.rowCount()) + " rows")
+			})
+		case <-ticker.C:
+			if args.Follow {
+				// Redraw only when rows arrived, the input may stay open for hours
+				if n := target.appendedRows(); n != seen {
+					seen = n
+					app.QueueUpdateDraw(follow.update)
+				}
+				continue
+			}
 
-					if loadProgress.TotalBytes > 0 {
-						// Show progress bar for files
-						percent := loadProgress.GetPercentage()
-						progressBar := makeProgressBar(percent, 15)
-						updateFooterWithStatus(fmt.Sprintf("Loading... %s", progressBar))
-					} else {
-						// Show row count for pipes (no file size)
-						updateFooterWithStatus("Loading... " + strconv.Itoa(b.rowLen) + " rows")
+			// Periodic UI update
+			app.QueueUpdateDraw(func() {
+				if displayedSource() != target {
+					return
+				}
+				drawBuffer(b, bufferTable)
+
+				// Keep cursor on first row if user hasn't moved it
+				if !userMovedCursor {
+					row, col := bufferTable.GetSelection()
+					if row != 0 {
+						bufferTable.Select(0, col)
 					}
-				})
-			}
+				}
+
+				if target.progress.TotalBytes > 0 {
+					// Show progress bar for files
+					percent := target.progress.GetPercentage()
+					progressBar := makeProgressBar(percent, 15)
+					updateFooterWithStatus(fmt.Sprintf("Loading... %s", progressBar))
+				} else {
+					// Show row count for pipes (no file size)
+					updateFooterWithStatus("Loading... " + strconv.Itoa(b.rowCount()) + " rows")
+				}
+			})
 		}
-	}()
+	}
+	return nil
 }
 
 // loadDataAsync starts async loading and waits for initial data
@@ -106,8 +134,45 @@ func loadDataAsync(loader func(*Buffer, chan<- bool, chan<- error), b *Buffer) (
 	}
 }
 
+// loadAndDisplaySources loads the named table of a multi-table input, or the
+// first one with the picker opened on startup when there is more than one to
+// choose from
+func loadAndDisplaySources(set *sourceSet, name string, source string) error {
+	subSources = set
+	pick := name == "" && len(set.names) > 1 && args.SQL == ""
+	if name == "" {
+		name = set.names[0]
+	}
+	if err := set.loadInto(name, b); err != nil {
+		return err
+	}
+
+	setupFreezeMode(b)
+	if !pick {
+		if err := validateDataNotEmpty(b, source); err != nil {
+			return err
+		}
+	}
+	if args.SQL != "" {
+		return displaySQLArg()
+	}
+	// Printing has no picker, the first table is printed
+	if args.Print {
+		return printBuffer(b)
+	}
+
+	if err := drawUI(b); err != nil {
+		return err
+	}
+	if pick {
+		showSourcePicker()
+	}
+	return runApp()
+}
+
 // runApp starts the UI application if not in debug mode
 func runApp() error {
+	defer closeBuffers()
 	if !debug {
 		if err := app.SetRoot(UI, true).SetFocus(UI).Run(); err != nil {
 			return err
@@ -116,6 +181,17 @@ func runApp() error {
 	return nil
 }
 
+// closeBuffers releases the files the buffers of the tabs still read from
+func closeBuffers() {
+	if tabs == nil {
+		displayedSource().close()
+		return
+	}
+	for _, t := range tabs {
+		t.buf.close()
+	}
+}
+
 // loadAndDisplayAsync handles the complete async loading workflow
 func loadAndDisplayAsync(loader func(*Buffer, chan<- bool, chan<- error), source string) error {
 	updateChan, doneChan, err := loadDataAsync(loader, b)
@@ -124,8 +200,11 @@ func loadAndDisplayAsync(loader func(*Buffer, chan<- bool, chan<- error), source
 	}
 
 	setupFreezeMode(b)
-	if err := validateDataNotEmpty(b, source); err != nil {
-		return err
+	// A followed input may be empty until something is written to it
+	if !args.Follow {
+		if err := validateDataNotEmpty(b, source); err != nil {
+			return err
+		}
 	}
 
 	if err := drawUI(b); err != nil {
@@ -146,6 +225,12 @@ func loadAndDisplaySync(loader func(*Buffer) error, source string) error {
 	if err := validateDataNotEmpty(b, source); err != nil {
 		return err
 	}
+	if args.SQL != "" {
+		return displaySQLArg()
+	}
+	if args.Print {
+		return printBuffer(b)
+	}
 
 	if err := drawUI(b); err != nil {
 		return err
@@ -154,32 +239,100 @@ func loadAndDisplaySync(loader func(*Buffer) error, source string) error {
 	return runApp()
 }
 
+// configureBuffer applies the parsing options of the command line to nb
+func configureBuffer(nb *Buffer) error {
+	d, err := newDialect(args.Sep, args.Quote, args.Escape)
+	if err != nil {
+		return err
+	}
+	nb.dialect = d
+	// Explicit widths take precedence over any separator
+	if len(args.Widths) > 0 {
+		starts, err := widthsToStarts(args.Widths)
+		if err != nil {
+			return err
+		}
+		nb.colStarts = starts
+		nb.sep = " "
+	}
+	if args.Pattern != "" {
+		lp, err := newLinePattern(args.Pattern, args.Unmatched)
+		if err != nil {
+			return err
+		}
+		if err := usePattern(nb, lp); err != nil {
+			return err
+		}
+	}
+
+	// Configure memory limit
+	if args.MemoryMB > 0 {
+		nb.setMemoryLimit(int64(args.MemoryMB) * 1024 * 1024) // Convert MB to bytes
+	}
+	// else use default (unlimited - 0)
+	nb.maxRows = args.MaxRows
+	return nil
+}
+
+// fileLoaders returns the async and sync loaders for a file of the given
+// format, workbooks are not covered since they load through the picker
+func fileLoaders(fn string, format int) (func(string, *Buffer, chan<- bool, chan<- error), func(string, *Buffer) error) {
+	switch format {
+	case formatJSONLines:
+		return loadJSONLFileToBufferAsync, loadJSONLFileToBuffer
+	case formatParquet:
+		return loadParquetFileToBufferAsync, loadParquetFileToBuffer
+	case formatArrow:
+		return loadArrowFileToBufferAsync, loadArrowFileToBuffer
+	}
+	if useLineIndex(fn) {
+		return loadIndexedFileToBufferAsync, loadIndexedFileToBuffer
+	}
+	return loadFileToBufferAsync, loadFileToBuffer
+}
+
 func main() {
 	initView()
 	args.setDefault()
 	RootCmd := &cobra.Command{
-		Use:     "ftv {File_Name}",
+		Use:     "ftv {File_Name...}",
 		Version: "0.8.1",
 		Short:   "Fast table viewer for delimited file in terminal",
 		Run: func(cmd *cobra.Command, cmdargs []string) {
-			if args.Sep == "\\t" {
-				args.Sep = "	"
+			args.Sep = strings.ReplaceAll(args.Sep, "\\t", "\t")
+			if args.Encoding != "" {
+				_, err := lookupEncoding(args.Encoding)
+				fatalError(err)
 			}
-			if len([]rune(args.Sep)) > 0 {
-				b.sep = []rune(args.Sep)[0]
+			_, err := columnSelectionFromArgs()
+			fatalError(err)
+			fatalError(configureBuffer(b))
+			// Output that is not a terminal gets the table printed instead of the UI
+			if !stdoutIsTerminal() {
+				args.Print = true
 			}
-
-			// Configure memory limit
-			if args.MemoryMB > 0 {
-				b.setMemoryLimit(int64(args.MemoryMB) * 1024 * 1024) // Convert MB to bytes
+			if args.YankFile != "" && args.YankCommand != "" {
+				fatalError(errors.New("--yank-file and --yank-command cannot be combined"))
+			}
+			if args.Print {
+				_, err := newTableBorder(args.Border)
+				fatalError(err)
+				if args.Follow {
+					fatalError(errors.New("--follow needs the UI, it cannot be combined with --print"))
+				}
+			}
+			if args.SQL != "" && args.Follow {
+				fatalError(errors.New("--sql needs the whole input, it cannot be combined with --follow"))
 			}
-			// else use default (unlimited - 0)
 
 			info, err := os.Stdin.Stat()
 			fatalError(err)
 
 			// Determine if we should use async loading
-			useAsync := args.AsyncLoad
+			// Following never finishes loading, so it always renders progressively
+			// Printing waits for the whole table to size its columns
+			// A query waits for the whole input too
+			useAsync := (args.AsyncLoad || args.Follow) && !args.Print && args.SQL == ""
 
 			//check whether from a console pipe
 			if info.Mode()&os.ModeCharDevice != 0 {
@@ -189,43 +342,130 @@ func main() {
 					_ = cmd.Help()
 					return
 				}
-				//get file name form console
-				args.FileName = cmdargs[0]
+				//get file names form console, each one opens in its own tab
+				files := expandFileArgs(cmdargs)
 
-				// Check if file exists before attempting to load
-				if _, err := os.Stat(args.FileName); os.IsNotExist(err) {
-					stopView()
-					fmt.Printf("⚠️  File not found: %s\n", args.FileName)
-					os.Exit(1)
-				} else if err != nil {
-					stopView()
-					fmt.Printf("⚠️  Cannot access file: %s\n", err)
-					os.Exit(1)
+				// Check if files exist before attempting to load
+				for _, fn := range files {
+					if _, _, ok := splitArchiveMember(fn); ok {
+						continue
+					}
+					if _, err := os.Stat(fn); os.IsNotExist(err) {
+						stopView()
+						fmt.Printf("⚠️  File not found: %s\n", fn)
+						os.Exit(1)
+					} else if err != nil {
+						stopView()
+						fmt.Printf("⚠️  Cannot access file: %s\n", err)
+						os.Exit(1)
+					}
+				}
+
+				if args.Concat {
+					fatalError(loadAndDisplayShards(cmdargs, files, useAsync))
+					return
+				}
+				if len(files) > 1 {
+					if args.Follow {
+						fatalError(errors.New("--follow works with a single file"))
+					}
+					if args.SQL != "" {
+						fatalError(errors.New("--sql works with a single input, use --concat to query several files as one table"))
+					}
+					fatalError(loadAndDisplayTabs(files, useAsync))
+					return
+				}
+				args.FileName = files[0]
+
+				// bundle.zip:data/x.csv opens a member of an archive directly
+				if fn, member, ok := splitArchiveMember(args.FileName); ok {
+					args.FileName = fn
+					a, err := openArchive(fn)
+					fatalError(err)
+					fatalError(loadAndDisplaySources(newArchiveSourceSet(a), member, "File"))
+					return
 				}
 
+				format, err := detectFileFormat(args.FileName)
+				fatalError(err)
+
+				if args.Follow {
+					fatalError(followFile(args.FileName, format))
+					return
+				}
+
+				if args.Query != "" && format != formatSQLite {
+					fatalError(errors.New("--query needs a SQLite database file"))
+				}
+
+				// Workbooks, archives and databases hold several tables and load through the picker
+				if hasSources(format) {
+					set, err := openSourceSet(args.FileName, format)
+					fatalError(err)
+					first := ""
+					if args.Query != "" {
+						first = sqliteQuerySource
+					}
+					fatalError(loadAndDisplaySources(set, first, "File"))
+					return
+				}
+
+				asyncLoader, syncLoader := fileLoaders(args.FileName, format)
 				if useAsync {
 					err = loadAndDisplayAsync(func(b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
-						go loadFileToBufferAsync(args.FileName, b, updateChan, doneChan)
+						go asyncLoader(args.FileName, b, updateChan, doneChan)
 					}, "File")
 					fatalError(err)
 				} else {
 					err = loadAndDisplaySync(func(b *Buffer) error {
-						return loadFileToBuffer(args.FileName, b)
+						return syncLoader(args.FileName, b)
 					}, "File")
 					fatalError(err)
 				}
 			} else {
 				// PIPE MODE
 				args.FileName = "From Shell Pipe"
+				if args.Query != "" {
+					fatalError(errors.New("--query needs a SQLite database file"))
+				}
+				stdin, err := decompressPipe(bufio.NewReader(os.Stdin))
+				fatalError(err)
+				format := detectPipeFormat(stdin)
+				// Text is transcoded to UTF-8 before it is sniffed again and parsed
+				if format == formatDelimited || format == formatJSONLines {
+					stdin, err = decodePipe(stdin)
+					fatalError(err)
+					format = detectPipeFormat(stdin)
+				}
+
+				if format == formatArchive {
+					set, err := openZipPipe(stdin)
+					fatalError(err)
+					fatalError(loadAndDisplaySources(set, "", "Pipe"))
+					return
+				}
+
+				var asyncLoader func(io.Reader, *Buffer, chan<- bool, chan<- error)
+				var syncLoader func(io.Reader, *Buffer) error
+				switch format {
+				case formatJSONLines:
+					asyncLoader, syncLoader = loadJSONLPipeToBufferAsync, loadJSONLPipeToBuffer
+				case formatParquet:
+					asyncLoader, syncLoader = loadParquetPipeToBufferAsync, loadParquetPipeToBuffer
+				case formatArrow:
+					asyncLoader, syncLoader = loadArrowPipeToBufferAsync, loadArrowPipeToBuffer
+				default:
+					asyncLoader, syncLoader = loadPipeToBufferAsync, loadPipeToBuffer
+				}
 
 				if useAsync {
 					err = loadAndDisplayAsync(func(b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
-						go loadPipeToBufferAsync(os.Stdin, b, updateChan, doneChan)
+						go asyncLoader(stdin, b, updateChan, doneChan)
 					}, "Pipe")
 					fatalError(err)
 				} else {
 					err = loadAndDisplaySync(func(b *Buffer) error {
-						return loadPipeToBuffer(os.Stdin, b)
+						return syncLoader(stdin, b)
 					}, "Pipe")
 					fatalError(err)
 				}
@@ -233,15 +473,33 @@ func main() {
 		},
 	}
 
-	RootCmd.Flags().StringVarP(&args.Sep, "separator", "s", "", "Delimiter/separator character (use \\t for tab)")
+	RootCmd.Flags().StringVarP(&args.Sep, "separator", "s", "", "Delimiter/separator, one or more characters like | or ^|^ (use \\t for tab)")
+	RootCmd.Flags().StringVar(&args.Quote, "quote", `"`, "Quote character of delimited text, empty to turn quoting off")
+	RootCmd.Flags().StringVar(&args.Escape, "escape", "", "Escape character that keeps the next character as is, e.g. \\ (default: quotes are doubled)")
 	RootCmd.Flags().IntVarP(&args.NLine, "lines", "n", 0, "Display only first N lines")
 	RootCmd.Flags().StringSliceVar(&args.SkipSymbol, "skip-prefix", []string{}, "Skip lines starting with prefix (comma-separated)")
 	RootCmd.Flags().IntVar(&args.SkipNum, "skip-lines", 0, "Skip first N lines")
-	RootCmd.Flags().IntSliceVar(&args.ShowNum, "columns", []int{}, "Show only specified columns (comma-separated)")
-	RootCmd.Flags().IntSliceVar(&args.HideNum, "hide-columns", []int{}, "Hide specified columns (comma-separated)")
+	RootCmd.Flags().StringSliceVar(&args.Columns, "columns", []string{}, "Show only these columns, in this order: numbers, ranges (3-10), header names, re:patterns, !negations (comma-separated)")
+	RootCmd.Flags().StringSliceVar(&args.HideColumns, "hide-columns", []string{}, "Hide these columns: numbers, ranges, header names, re:patterns, !exceptions (comma-separated)")
 	RootCmd.Flags().IntVarP(&args.Header, "freeze", "f", 0, "Freeze mode: -1=none, 0=row+col, 1=row only, 2=col only")
 	RootCmd.Flags().BoolVar(&args.Strict, "strict", false, "Strict mode: fail on missing/inconsistent data")
 	RootCmd.Flags().BoolVar(&args.AsyncLoad, "async", true, "Progressive rendering while loading")
+	RootCmd.Flags().IntSliceVar(&args.Widths, "widths", []int{}, "Fixed column widths (comma-separated), the last column takes the rest of the line")
+	RootCmd.Flags().BoolVar(&args.FixedWidth, "fixed-width", false, "Split columns at whitespace-aligned boundaries instead of a separator")
+	RootCmd.Flags().BoolVar(&args.Index, "index", false, "Browse the file from disk through a line index instead of loading it (automatic when larger than --memory)")
+	RootCmd.Flags().StringVar(&args.Pattern, "pattern", "", "Regular expression with named groups that splits each line into columns, or a preset: common, combined, syslog")
+	RootCmd.Flags().StringVar(&args.Unmatched, "unmatched", unmatchedSkip, "Lines not matching --pattern: skip, or raw to keep them in a raw column")
+	RootCmd.Flags().StringVar(&args.Encoding, "encoding", "", "Text encoding of the input, e.g. utf-16le or windows-1252 (detected by default)")
+	RootCmd.Flags().StringVar(&args.Query, "query", "", "SQL query whose result is shown, for SQLite database files")
+	RootCmd.Flags().StringVar(&args.SQL, "sql", "", "SQL query over the loaded table, named t, whose result is shown, e.g. \"SELECT region, count(*) FROM t GROUP BY region\"")
+	RootCmd.Flags().BoolVar(&args.Concat, "concat", false, "Load all files into one table, lining up their columns by header name")
+	RootCmd.Flags().BoolVar(&args.SourceColumn, "source-column", false, "With --concat, add a _source column with the file each row came from")
+	RootCmd.Flags().BoolVarP(&args.Print, "print", "p", false, "Print the table to stdout instead of opening the viewer (automatic when stdout is not a terminal)")
+	RootCmd.Flags().StringVar(&args.Border, "border", borderBox, "Borders of the printed table: box or ascii")
+	RootCmd.Flags().StringVar(&args.YankFile, "yank-file", "", "Write text copied with y to this file instead of the clipboard")
+	RootCmd.Flags().StringVar(&args.YankCommand, "yank-command", "", "Pipe text copied with y to this command instead of the clipboard, e.g. pbcopy")
+	RootCmd.Flags().BoolVarP(&args.Follow, "follow", "F", false, "Keep reading rows appended to the file or pipe, like tail -F")
+	RootCmd.Flags().IntVar(&args.MaxRows, "max-rows", 0, "Keep only the newest N rows, dropping the oldest (0=all)")
 	RootCmd.Flags().IntVarP(&args.MemoryMB, "memory", "m", 0, "Memory limit in MB (0=unlimited/default, >0=set limit)")
 	RootCmd.Flags().SortFlags = false
 	err := RootCmd.Execute()
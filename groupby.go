@@ -0,0 +1,366 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// aggregate functions of the group-by view, avg is an alias of mean
+var groupFuncs = []string{"count", "sum", "mean", "min", "max", "median", "distinct"}
+
+// groupAgg is one result column: fn over column col, col is -1 for count
+// without a column
+type groupAgg struct {
+	fn   string
+	col  int
+	name string // header of the result column
+}
+
+// groupDrill remembers where the rows of a group-by tab came from, so Enter
+// can filter the source tab down to a group
+type groupDrill struct {
+	source int   // index of the source tab
+	keys   []int // key columns in the source, the first columns of the result
+}
+
+// resolveColumns returns the columns of names picked by a list of numbers,
+// ranges, header names or re:patterns like --columns takes them
+func resolveColumns(list []string, names []string) ([]int, error) {
+	cs, err := newColumnSelection(list, nil)
+	if err != nil {
+		return nil, err
+	}
+	return cs.resolve(names)
+}
+
+// splitList splits a comma-separated list and drops empty entries
+func splitList(text string) []string {
+	var list []string
+	for _, s := range strings.Split(text, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// parseGroupAggs parses aggregates like "count, sum(latency), mean(3)" over
+// the columns names of nb. An argument matching several columns, like a
+// range or re:pattern, aggregates each of them.
+func parseGroupAggs(text string, nb *Buffer, names []string) ([]groupAgg, error) {
+	var aggs []groupAgg
+	for _, item := range splitList(text) {
+		fn, arg, hasArg := strings.Cut(item, "(")
+		fn = strings.ToLower(strings.TrimSpace(fn))
+		if fn == "avg" {
+			fn = "mean"
+		}
+		known := false
+		for _, f := range groupFuncs {
+			known = known || f == fn
+		}
+		if !known {
+			return nil, errors.New("unknown function " + strconv.Quote(strings.TrimSpace(strings.SplitN(item, "(", 2)[0])) + ", use " + strings.Join(groupFuncs, ", "))
+		}
+		if !hasArg {
+			if fn != "count" {
+				return nil, errors.New(fn + " needs a column, like " + fn + "(" + names[0] + ")")
+			}
+			aggs = append(aggs, groupAgg{fn: fn, col: -1, name: fn})
+			continue
+		}
+		arg, ok := strings.CutSuffix(strings.TrimSpace(arg), ")")
+		if !ok || strings.TrimSpace(arg) == "" {
+			return nil, errors.New("expected a column between the parentheses of " + strconv.Quote(item))
+		}
+		cols, err := resolveColumns([]string{arg}, names)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cols {
+			if (fn == "sum" || fn == "mean" || fn == "median") && nb.getColType(c) != colTypeFloat {
+				return nil, errors.New(fn + " needs a number column, " + names[c] + " is a " + type2name(nb.getColType(c)) + " column (t changes the type)")
+			}
+			aggs = append(aggs, groupAgg{fn: fn, col: c, name: fn + "(" + names[c] + ")"})
+		}
+	}
+	if len(aggs) == 0 {
+		return nil, errors.New("no aggregates, e.g. count, sum(" + names[0] + ")")
+	}
+	return aggs, nil
+}
+
+// formatAggregate renders a computed number with at most 4 decimals
+func formatAggregate(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+}
+
+// aggregate computes agg over the rows of a group, values that are missing
+// or not numbers are skipped and a group without any gives ""
+func aggregate(nb *Buffer, agg groupAgg, rows [][]string) string {
+	if agg.col < 0 {
+		return strconv.Itoa(len(rows))
+	}
+	var values []string
+	for _, row := range rows {
+		if agg.col < len(row) && !isMissing(row[agg.col]) {
+			values = append(values, row[agg.col])
+		}
+	}
+	switch agg.fn {
+	case "count":
+		return strconv.Itoa(len(values))
+	case "distinct":
+		seen := make(map[string]bool, len(values))
+		for _, v := range values {
+			seen[v] = true
+		}
+		return strconv.Itoa(len(seen))
+	case "min", "max":
+		return extreme(values, nb.getColType(agg.col), agg.fn == "max")
+	}
+
+	var nums []float64
+	for _, v := range values {
+		if isNumericValue(strings.TrimSpace(v)) {
+			nums = append(nums, parseNumericValueFast(v))
+		}
+	}
+	if len(nums) == 0 {
+		return ""
+	}
+	sum := 0.0
+	for _, n := range nums {
+		sum += n
+	}
+	switch agg.fn {
+	case "sum":
+		return formatAggregate(sum)
+	case "mean":
+		return formatAggregate(sum / float64(len(nums)))
+	}
+	sort.Float64s(nums)
+	mid := len(nums) / 2
+	if len(nums)%2 == 0 {
+		return formatAggregate((nums[mid-1] + nums[mid]) / 2)
+	}
+	return formatAggregate(nums[mid])
+}
+
+// valueLess returns the order of the values of a column of type colType
+func valueLess(colType int) func(a, b string) bool {
+	switch colType {
+	case colTypeFloat:
+		return func(a, b string) bool { return parseNumericValueFast(a) < parseNumericValueFast(b) }
+	case colTypeDate:
+		return func(a, b string) bool { return parseDateValueFast(a) < parseDateValueFast(b) }
+	}
+	return func(a, b string) bool { return a < b }
+}
+
+// extreme returns the smallest or largest of values as written, compared as
+// numbers, dates or text after the column type
+func extreme(values []string, colType int, largest bool) string {
+	less := valueLess(colType)
+	best := ""
+	for i, v := range values {
+		if i == 0 || (largest && less(best, v)) || (!largest && less(v, best)) {
+			best = v
+		}
+	}
+	return best
+}
+
+// cellAt returns cell c of row, short rows read as empty past their end
+func cellAt(row []string, c int) string {
+	if c < len(row) {
+		return row[c]
+	}
+	return ""
+}
+
+// groupBuffer groups the data rows of nb by the key columns and returns a
+// table with the keys followed by the aggregates, one row per group in the
+// order the groups first appear
+func groupBuffer(nb *Buffer, keys []int, aggs []groupAgg) (*Buffer, error) {
+	// The groups of an indexed file would hold all of its rows in memory
+	if nb.index != nil {
+		return nil, errors.New(indexedUnsupportedMsg)
+	}
+	nb.mu.RLock()
+	names := sqlColumnNames(nb)
+	var order []string
+	groups := make(map[string][][]string)
+	for r := nb.rowFreeze; r < nb.dataRowCount(); r++ {
+		row := nb.rowAt(r)
+		key := make([]string, len(keys))
+		for i, c := range keys {
+			key[i] = cellAt(row, c)
+		}
+		// The separator cannot be typed into a cell
+		id := strings.Join(key, "\x00")
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], row)
+	}
+	nb.mu.RUnlock()
+
+	header := make([]string, 0, len(keys)+len(aggs))
+	for _, c := range keys {
+		header = append(header, names[c])
+	}
+	for _, agg := range aggs {
+		header = append(header, agg.name)
+	}
+	result := createNewBuffer()
+	result.colFreeze = min(nb.colFreeze, len(keys))
+	if err := result.contAppendSli(header, false); err != nil {
+		return nil, err
+	}
+	for _, id := range order {
+		row := strings.Split(id, "\x00")
+		for _, agg := range aggs {
+			row = append(row, aggregate(nb, agg, groups[id]))
+		}
+		if err := result.contAppendSli(row, false); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, c := range keys {
+		result.setColType(i, nb.getColType(c))
+	}
+	for i, agg := range aggs {
+		t := colTypeFloat
+		if agg.fn == "min" || agg.fn == "max" {
+			t = nb.getColType(agg.col)
+		}
+		result.setColType(len(keys)+i, t)
+	}
+	return result, nil
+}
+
+// showGroupResult opens a group-by table in a tab of its own, Enter on a
+// group filters the source tab down to it
+func showGroupResult(result *Buffer, keys []int, what string) {
+	source := currentTab
+	groupViewCount++
+	openTab("groups-"+strconv.Itoa(groupViewCount), result)
+	tabs[currentTab].drill = &groupDrill{source: source, keys: keys}
+	statusMessage = fmt.Sprintf("%d groups by %s, Enter shows the rows of a group", result.rowLen-result.rowFreeze, what)
+	updateFooterWithStatus(statusMessage)
+}
+
+// drillDown displays the source tab of the group-by tab filtered to the
+// group at row
+func drillDown(row int) {
+	d := tabs[currentTab].drill
+	if row < b.rowFreeze || row >= b.rowCount() {
+		return
+	}
+	b.mu.RLock()
+	cells := append([]string(nil), b.rowAt(row)...)
+	b.mu.RUnlock()
+
+	switchTab(d.source)
+	if b.index != nil {
+		updateFooterWithStatus(indexedUnsupportedMsg)
+		return
+	}
+	if originalBuffer == nil {
+		originalBuffer = b
+	}
+	names := sqlColumnNames(originalBuffer)
+	var parts, replaced []string
+	for i, c := range d.keys {
+		if old, ok := activeFilters[c]; ok {
+			replaced = append(replaced, names[c]+" "+old.Operator+" "+strconv.Quote(old.Query))
+		}
+		activeFilters[c] = FilterOptions{Query: cells[i], Operator: "equals", CaseSensitive: true, MissingAsEmpty: true}
+		parts = append(parts, names[c]+" = "+strconv.Quote(cells[i]))
+	}
+	b = applyFilters()
+	isFiltered = true
+	drawBuffer(b, bufferTable)
+	bufferTable.Select(0, d.keys[0])
+	currentCursorColumn = d.keys[0]
+	cursorPosStr = buildCursorPosStr(0, d.keys[0])
+	status := fmt.Sprintf("Filtered: %d rows where %s", b.rowLen-b.rowFreeze, strings.Join(parts, ", "))
+	if len(replaced) > 0 {
+		status += ", replacing " + strings.Join(replaced, ", ")
+	}
+	updateFooterWithStatus(status + " (r removes a filter)")
+}
+
+// showGroupForm asks for the key columns and aggregates of a group-by over
+// the displayed rows
+func showGroupForm() {
+	form := tview.NewForm()
+	closeForm := func() { closeModalForm("groupModal") }
+
+	b.mu.RLock()
+	names := sqlColumnNames(b)
+	b.mu.RUnlock()
+	_, col := bufferTable.GetSelection()
+	keyText := ""
+	if col < len(names) {
+		keyText = names[col]
+	}
+	hint := "[gray]Columns by name, number, range or re:pattern, comma-separated\n" +
+		"Aggregates: count, sum(x), mean(x), median(x), min(x), max(x), distinct(x)\n" +
+		"Columns: " + tview.Escape(strings.Join(names, ", ")) + "[-]"
+	form.AddInputField("Group by:", keyText, 60, nil, nil)
+	form.AddInputField("Aggregate:", lastGroupAggs, 60, nil, nil)
+	form.AddTextView("", hint, 80, 4, true, false)
+	keyField := form.GetFormItem(0).(*tview.InputField)
+	aggField := form.GetFormItem(1).(*tview.InputField)
+	message := form.GetFormItem(2).(*tview.TextView)
+	showError := func(msg string) {
+		message.SetText("[red]" + tview.Escape(msg) + "[-]")
+	}
+
+	run := func() {
+		keyList := splitList(keyField.GetText())
+		if len(keyList) == 0 {
+			showError("Name at least one column to group by")
+			return
+		}
+		keys, err := resolveColumns(keyList, names)
+		if err != nil {
+			showError(err.Error())
+			return
+		}
+		aggs, err := parseGroupAggs(aggField.GetText(), b, names)
+		if err != nil {
+			showError(err.Error())
+			return
+		}
+		lastGroupAggs = aggField.GetText()
+		message.SetText("[yellow]Grouping...[-]")
+		app.ForceDraw()
+		result, err := groupBuffer(b, keys, aggs)
+		if err != nil {
+			showError(err.Error())
+			return
+		}
+		if result.rowLen <= result.rowFreeze {
+			showError("There are no rows to group")
+			return
+		}
+		closeForm()
+		keyNames := make([]string, len(keys))
+		for i, c := range keys {
+			keyNames[i] = names[c]
+		}
+		showGroupResult(result, keys, strings.Join(keyNames, ", "))
+	}
+
+	showModalForm("groupModal", form, " Σ Group By - Enter to group, Esc to cancel ", "Group", run, 100, 13)
+}
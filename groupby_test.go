@@ -0,0 +1,155 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newGroupTestBuffer(t *testing.T) *Buffer {
+	t.Helper()
+	return newTestBuffer(t, [][]string{
+		{"region", "status", "latency", "day", "user"},
+		{"eu", "ok", "10", "2024-01-05", "ann"},
+		{"us", "ok", "1,200", "2024-02-01", "bob"},
+		{"eu", "failed", "30", "2024-03-01", "ann"},
+		{"us", "ok", "NA", "2023-01-01", "cid"},
+		{"eu", "ok", "5", "", "dan"},
+		{"ap", "failed", "7", "2023-12-31", ""},
+	}, colTypeStr, colTypeStr, colTypeFloat, colTypeDate, colTypeStr)
+}
+
+func TestGroupBuffer(t *testing.T) {
+	nb := newGroupTestBuffer(t)
+	names := sqlColumnNames(nb)
+	tests := []struct {
+		keys  string
+		aggs  string
+		want  [][]string
+		types []int
+	}{
+		{
+			"region", "count, sum(latency), mean(latency), median(latency), distinct(user)",
+			[][]string{
+				{"region", "count", "sum(latency)", "mean(latency)", "median(latency)", "distinct(user)"},
+				{"eu", "3", "45", "15", "10", "2"},
+				{"us", "2", "1200", "1200", "1200", "2"},
+				{"ap", "1", "7", "7", "7", "0"},
+			},
+			[]int{colTypeStr, colTypeFloat, colTypeFloat, colTypeFloat, colTypeFloat, colTypeFloat},
+		},
+		{
+			"region,2", "min(latency), max(3-4), avg(3)",
+			[][]string{
+				{"region", "status", "min(latency)", "max(latency)", "max(day)", "mean(latency)"},
+				{"eu", "ok", "5", "10", "2024-01-05", "7.5"},
+				{"us", "ok", "1,200", "1,200", "2024-02-01", "1200"},
+				{"eu", "failed", "30", "30", "2024-03-01", "30"},
+				{"ap", "failed", "7", "7", "2023-12-31", "7"},
+			},
+			[]int{colTypeStr, colTypeStr, colTypeFloat, colTypeFloat, colTypeDate, colTypeFloat},
+		},
+		{
+			"user", "count(latency), min(region)",
+			[][]string{
+				{"user", "count(latency)", "min(region)"},
+				{"ann", "2", "eu"},
+				{"bob", "1", "us"},
+				{"cid", "0", "us"},
+				{"dan", "1", "eu"},
+				{"", "1", "ap"},
+			},
+			[]int{colTypeStr, colTypeFloat, colTypeStr},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keys+" "+tt.aggs, func(t *testing.T) {
+			keys, err := resolveColumns(splitList(tt.keys), names)
+			if err != nil {
+				t.Fatal(err)
+			}
+			aggs, err := parseGroupAggs(tt.aggs, nb, names)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := groupBuffer(nb, keys, aggs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.cont, tt.want) {
+				t.Errorf("rows = %q, want %q", got.cont, tt.want)
+			}
+			if !reflect.DeepEqual(got.colType[:got.colLen], tt.types) {
+				t.Errorf("types = %v, want %v", got.colType[:got.colLen], tt.types)
+			}
+		})
+	}
+}
+
+func TestParseGroupAggsErrors(t *testing.T) {
+	nb := newGroupTestBuffer(t)
+	names := sqlColumnNames(nb)
+	tests := []struct {
+		aggs string
+		want string
+	}{
+		{"", "no aggregates"},
+		{"total(latency)", `unknown function "total"`},
+		{"sum", "sum needs a column"},
+		{"sum(region)", "sum needs a number column, region is a Str column"},
+		{"mean()", "expected a column between the parentheses"},
+		{"max(latency", "expected a column between the parentheses"},
+		{"min(city)", `Column "city" is not in the header`},
+	}
+	for _, tt := range tests {
+		if _, err := parseGroupAggs(tt.aggs, nb, names); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseGroupAggs(%q) error = %v, want it to mention %q", tt.aggs, err, tt.want)
+		}
+	}
+}
+
+func TestGroupBufferIndexed(t *testing.T) {
+	nb := newGroupTestBuffer(t)
+	aggs, err := parseGroupAggs("count", nb, sqlColumnNames(nb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb.index = &lineIndex{}
+	if _, err := groupBuffer(nb, []int{0}, aggs); err == nil || err.Error() != indexedUnsupportedMsg {
+		t.Errorf("groupBuffer() in indexed mode error = %v, want %q", err, indexedUnsupportedMsg)
+	}
+}
+
+func TestGroupOfShortRowsDrillsDown(t *testing.T) {
+	nb := newTestBuffer(t, [][]string{
+		{"region", "status", "user"},
+		{"eu", "ok", "ann"},
+		{"us", "", ""},
+		{"eu", "", "bob"},
+		{"us", "ok", ""},
+	})
+	// A ragged row, shorter than the header
+	nb.cont[2] = []string{"us"}
+	got, err := groupBuffer(nb, []int{1}, []groupAgg{{fn: "count", name: "count"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"status", "count"}, {"ok", "2"}, {"", "2"}}
+	if !reflect.DeepEqual(got.cont, want) {
+		t.Fatalf("rows = %q, want %q", got.cont, want)
+	}
+
+	// Drilling into a group filters its key cells the same way, so the
+	// group of empty and missing cells gets all of its rows
+	for _, group := range got.cont[1:] {
+		filtered := nb.filterByColumn(1, FilterOptions{Query: group[0], Operator: "equals", CaseSensitive: true, MissingAsEmpty: true})
+		if n := filtered.rowLen - filtered.rowFreeze; I2S(n) != group[1] {
+			t.Errorf("drilling into %q shows %d rows, want %s", group[0], n, group[1])
+		}
+	}
+	// Filters of the user keep skipping rows without the column
+	filtered := nb.filterByColumn(1, FilterOptions{Query: "", Operator: "equals"})
+	if n := filtered.rowLen - filtered.rowFreeze; n != 1 {
+		t.Errorf("equals \"\" kept %d rows, want 1 without the short row", n)
+	}
+}
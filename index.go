@@ -0,0 +1,523 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/encoding/unicode"
+)
+
+const (
+	// Rows between two saved offsets, rows are read back one block at a time
+	indexBlockRows = 1024
+	// Parsed blocks kept in memory, the window of rows around the cursor
+	indexCacheBlocks = 64
+	// Cap on the matches collected by a search over an indexed file
+	maxIndexedSearchResults = 100000
+)
+
+// Footer message for operations that need every row in memory
+const indexedUnsupportedMsg = "Not available in indexed mode, rows are read from disk"
+
+// useLineIndex decides whether fn is browsed from disk instead of loaded:
+// when asked with --index, or when it is larger than the --memory limit
+func useLineIndex(fn string) bool {
+	// Patterns build the header from group names, which the index cannot hold
+	if args.Pattern != "" {
+		return false
+	}
+	if args.Index {
+		return true
+	}
+	if args.MemoryMB <= 0 {
+		return false
+	}
+	info, err := os.Stat(fn)
+	if err != nil || info.Size() <= int64(args.MemoryMB)*1024*1024 {
+		return false
+	}
+	head, err := readFileHead(fn, encodingSniffLen)
+	return err == nil && checkIndexable(head) == nil
+}
+
+// checkIndexable checks that a file starting with head can be read at random
+// offsets, which rules out compressed files and encodings other than UTF-8
+func checkIndexable(head []byte) error {
+	if detectCompression(head) != compressNone {
+		return errors.New("indexed mode needs an uncompressed file, rows are read at random offsets")
+	}
+	enc := detectEncoding(head)
+	if args.Encoding != "" {
+		enc, _ = lookupEncoding(args.Encoding)
+	}
+	if enc != nil && enc != unicode.UTF8BOM {
+		return errors.New("indexed mode needs UTF-8 text, rows are read at random offsets")
+	}
+	return nil
+}
+
+// offsetRecordReader reads whole records (quoted fields may span lines)
+// along with the file offset each one starts at
+type offsetRecordReader struct {
+	r      *bufio.Reader
+	offset int64 // offset of the next unread byte
+	plain  bool  // no quoting, every line is a record
+	dialect
+}
+
+// newOffsetRecordReader reads records from r, which starts at offset
+func newOffsetRecordReader(r io.Reader, offset int64, d dialect, plain bool) *offsetRecordReader {
+	return &offsetRecordReader{r: bufio.NewReaderSize(r, 1024*1024), offset: offset, dialect: d, plain: plain}
+}
+
+// readLine returns the next line without its line break
+func (rr *offsetRecordReader) readLine() (string, error) {
+	line, err := rr.r.ReadString('\n')
+	rr.offset += int64(len(line))
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+// next returns the next record and its start offset, io.EOF at the end
+func (rr *offsetRecordReader) next() (string, int64, error) {
+	start := rr.offset
+	line, err := rr.readLine()
+	if err != nil {
+		return "", start, err
+	}
+	if rr.plain || rr.unquoted(line) || !inQuotedField(line, rr.dialect, false) {
+		return line, start, nil
+	}
+
+	lines := []string{line}
+	size := len(line)
+	for {
+		next, err := rr.readLine()
+		if err != nil {
+			break
+		}
+		lines = append(lines, next)
+		size += len(next) + 1
+		// A stray quote must not swallow the rest of the file
+		if !inQuotedField(next, rr.dialect, true) || size > maxQuotedRecordBytes {
+			break
+		}
+	}
+	return strings.Join(lines, "\n"), start, nil
+}
+
+// lineIndex keeps the file offset of every indexBlockRows-th row, so any
+// row can be parsed again from disk, and a cache of recently used blocks
+type lineIndex struct {
+	file *os.File
+	dialect
+	colStarts []int
+	visCol    []int // displayed columns, nil for all
+	width     int
+
+	mu           sync.Mutex
+	blockOffsets []int64
+	rows         int // rows indexed so far, including the header
+	done         bool
+	cache        map[int][][]string
+	lru          []int // cached blocks, least recently used first
+}
+
+// openLineIndex opens fn for indexed browsing and sets up the separator on b
+// like the regular loaders do. It returns a reader positioned at the first row.
+func openLineIndex(fn string, b *Buffer) (*lineIndex, *offsetRecordReader, error) {
+	head, err := readFileHead(fn, encodingSniffLen)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkIndexable(head); err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(fn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A byte order mark would end up in the first header cell
+	var start int64
+	if bytes.HasPrefix(head, utf8BOM) {
+		start = int64(len(utf8BOM))
+		if _, err := file.Seek(start, io.SeekStart); err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+	}
+
+	// Skipped lines come before the first indexed row
+	rr := newOffsetRecordReader(file, start, b.dialect, b.colStarts != nil)
+	for i := 0; i < args.SkipNum; i++ {
+		if _, _, err := rr.next(); err != nil {
+			break
+		}
+	}
+
+	if b.sep == "" {
+		if err := detectIndexedSeparator(fn, file, rr.offset, b); err != nil {
+			file.Close()
+			return nil, nil, err
+		}
+		rr.dialect = b.dialect
+		rr.plain = b.colStarts != nil
+	}
+
+	ix := &lineIndex{
+		file:      file,
+		dialect:   b.dialect,
+		colStarts: b.colStarts,
+		cache:     make(map[int][][]string),
+	}
+	return ix, rr, nil
+}
+
+// detectIndexedSeparator picks the separator from the first lines after offset
+func detectIndexedSeparator(fn string, file *os.File, offset int64, b *Buffer) error {
+	rr := newOffsetRecordReader(io.NewSectionReader(file, offset, 1<<62), offset, dialect{}, true)
+	var detectLines []string
+	for len(detectLines) < 10 {
+		line, _, err := rr.next()
+		if err != nil {
+			break
+		}
+		if !skipLine(line, args.SkipSymbol) {
+			detectLines = append(detectLines, line)
+		}
+	}
+	if strings.HasSuffix(plainFileName(fn), ".csv") {
+		b.sep = ","
+	} else if strings.HasSuffix(plainFileName(fn), ".tsv") {
+		b.sep = "\t"
+	} else {
+		sd := sepDetecor{}
+		b.sep = sd.sepDetect(detectLines)
+	}
+	useFixedWidth(b, detectLines, args.FixedWidth)
+	if b.sep == "" {
+		return errors.New("tv can't identify separator, you need to set it manual")
+	}
+	return nil
+}
+
+// build scans the file from rr and records block offsets, onBlock (optional)
+// is called each time a block of rows has been indexed
+func (ix *lineIndex) build(rr *offsetRecordReader, progress *LoadProgress, onBlock func()) error {
+	defer func() {
+		ix.mu.Lock()
+		ix.done = true
+		ix.mu.Unlock()
+	}()
+	for {
+		if args.NLine > 0 && ix.rowCount() >= args.NLine {
+			return nil
+		}
+		record, start, err := rr.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if skipLine(record, args.SkipSymbol) {
+			continue
+		}
+
+		ix.mu.Lock()
+		if ix.rows == 0 {
+			if err := ix.setColumns(record); err != nil {
+				ix.mu.Unlock()
+				return err
+			}
+		}
+		if ix.rows%indexBlockRows == 0 {
+			ix.blockOffsets = append(ix.blockOffsets, start)
+		}
+		ix.rows++
+		fullBlock := ix.rows%indexBlockRows == 0
+		ix.mu.Unlock()
+
+		progress.LoadedBytes = rr.offset
+		if fullBlock && onBlock != nil {
+			onBlock()
+		}
+	}
+}
+
+// setColumns fixes the displayed columns from the header record
+func (ix *lineIndex) setColumns(header string) error {
+	fields := ix.split(header)
+	ix.width = len(fields)
+	cs, err := columnSelectionFromArgs()
+	if err != nil || cs == nil {
+		return err
+	}
+	visCol, err := cs.resolve(fields)
+	if err != nil {
+		return err
+	}
+	ix.visCol = visCol
+	ix.width = len(visCol)
+	return nil
+}
+
+// split parses a record into its fields
+func (ix *lineIndex) split(record string) []string {
+	if ix.colStarts != nil {
+		return splitFixedWidth(record, ix.colStarts)
+	}
+	fields, err := lineCSVParseFast(record, ix.dialect)
+	if err != nil {
+		return []string{record}
+	}
+	return fields
+}
+
+// parseRow turns a record into a row as wide as the header, missing cells
+// are "NaN" like in the regular loaders and extra cells are dropped
+func (ix *lineIndex) parseRow(record string) []string {
+	fields := ix.split(record)
+	row := make([]string, ix.width)
+	for i := range row {
+		src := i
+		if ix.visCol != nil {
+			src = ix.visCol[i]
+		}
+		if src < len(fields) {
+			row[i] = fields[src]
+		} else {
+			row[i] = "NaN"
+		}
+	}
+	return row
+}
+
+// rowCount returns the number of rows indexed so far
+func (ix *lineIndex) rowCount() int {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.rows
+}
+
+// row returns row r, reading its block from disk when it is not cached
+func (ix *lineIndex) row(r int) []string {
+	block, err := ix.cachedBlock(r / indexBlockRows)
+	if err != nil {
+		return nil
+	}
+	i := r % indexBlockRows
+	if i >= len(block) {
+		return nil
+	}
+	return block[i]
+}
+
+// cachedBlock returns block k from the cache or disk, complete blocks are cached
+func (ix *lineIndex) cachedBlock(k int) ([][]string, error) {
+	ix.mu.Lock()
+	if rows, ok := ix.cache[k]; ok {
+		ix.touch(k)
+		ix.mu.Unlock()
+		return rows, nil
+	}
+	complete := ix.done || ix.rows >= (k+1)*indexBlockRows
+	ix.mu.Unlock()
+
+	rows, err := ix.readBlock(k)
+	if err != nil || !complete {
+		return rows, err
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.cache[k] = rows
+	ix.touch(k)
+	if len(ix.lru) > indexCacheBlocks {
+		delete(ix.cache, ix.lru[0])
+		ix.lru = ix.lru[1:]
+	}
+	return rows, nil
+}
+
+// touch marks block k as most recently used, callers hold ix.mu
+func (ix *lineIndex) touch(k int) {
+	for i, cached := range ix.lru {
+		if cached == k {
+			ix.lru = append(ix.lru[:i], ix.lru[i+1:]...)
+			break
+		}
+	}
+	ix.lru = append(ix.lru, k)
+}
+
+// readBlock parses the rows of block k from disk
+func (ix *lineIndex) readBlock(k int) ([][]string, error) {
+	ix.mu.Lock()
+	if k < 0 || k >= len(ix.blockOffsets) {
+		ix.mu.Unlock()
+		return nil, errors.New("row is not indexed yet")
+	}
+	start := ix.blockOffsets[k]
+	n := ix.rows - k*indexBlockRows
+	if n > indexBlockRows {
+		n = indexBlockRows
+	}
+	ix.mu.Unlock()
+
+	rr := newOffsetRecordReader(io.NewSectionReader(ix.file, start, 1<<62), start, ix.dialect, ix.colStarts != nil)
+	rows := make([][]string, 0, n)
+	for len(rows) < n {
+		record, _, err := rr.next()
+		if err != nil {
+			return rows, err
+		}
+		if skipLine(record, args.SkipSymbol) {
+			continue
+		}
+		rows = append(rows, ix.parseRow(record))
+	}
+	return rows, nil
+}
+
+// search scans the indexed rows for cells accepted by match, in row order,
+// and stops after limit results. Blocks are read without filling the cache.
+func (ix *lineIndex) search(match func(string) bool, limit int) []SearchResult {
+	var results []SearchResult
+	for k := 0; k*indexBlockRows < ix.rowCount(); k++ {
+		rows, err := ix.readBlock(k)
+		for i, row := range rows {
+			for c, cell := range row {
+				if match(cell) {
+					results = append(results, SearchResult{Row: k*indexBlockRows + i, Col: c})
+					if len(results) >= limit {
+						return results
+					}
+				}
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return results
+}
+
+// fillSample copies the first block into b, for column type detection and
+// width sampling, and attaches the index
+func (ix *lineIndex) fillSample(b *Buffer) error {
+	rows, err := ix.readBlock(0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	for _, row := range rows {
+		if err := b.contAppendSli(row, false); err != nil {
+			return err
+		}
+	}
+	b.detectAllColumnTypes()
+	b.mu.Lock()
+	b.index = ix
+	b.mu.Unlock()
+	return nil
+}
+
+// Close closes the file rows are read from, the index cannot read rows after it
+func (ix *lineIndex) Close() error {
+	return ix.file.Close()
+}
+
+// load a file through a line index (async version for progressive rendering)
+func loadIndexedFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+	info, err := os.Stat(fn)
+	if err != nil {
+		doneChan <- err
+		return
+	}
+	b.progress.TotalBytes = info.Size()
+	b.progress.LoadedBytes = 0
+	b.progress.CompressedBytes = nil
+	b.progress.IsComplete = false
+
+	ix, rr, err := openLineIndex(fn, b)
+	if err != nil {
+		doneChan <- err
+		return
+	}
+
+	initialSent := false
+	var sampleErr error
+	err = ix.build(rr, &b.progress, func() {
+		if sampleErr != nil {
+			return
+		}
+		if !initialSent {
+			if sampleErr = ix.fillSample(b); sampleErr != nil {
+				return
+			}
+			// Signal that initial data is ready for rendering
+			updateChan <- true
+			initialSent = true
+			return
+		}
+		select {
+		case updateChan <- true:
+		default:
+			// Non-blocking - skip update if channel is full
+		}
+	})
+	if err == nil {
+		err = sampleErr
+	}
+	if err == nil && !initialSent {
+		if err = ix.fillSample(b); err == nil {
+			updateChan <- true
+		}
+	}
+	if err != nil {
+		ix.Close()
+		doneChan <- err
+		return
+	}
+
+	b.progress.IsComplete = true
+	doneChan <- nil
+}
+
+// load a file through a line index (synchronous version)
+func loadIndexedFileToBuffer(fn string, b *Buffer) error {
+	info, err := os.Stat(fn)
+	if err != nil {
+		return err
+	}
+	ix, rr, err := openLineIndex(fn, b)
+	if err != nil {
+		return err
+	}
+
+	progress := newProgressTracker(info.Size(), true)
+	var indexed int64
+	err = ix.build(rr, &b.progress, func() {
+		// increment counts one line, add the rest of the block
+		progress.lineCount += indexBlockRows - 1
+		progress.increment(rr.offset - indexed)
+		indexed = rr.offset
+	})
+	progress.finish()
+	if err == nil {
+		err = ix.fillSample(b)
+	}
+	if err != nil {
+		ix.Close()
+	}
+	return err
+}
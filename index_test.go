@@ -0,0 +1,182 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeIndexTestFile writes a CSV with rows data rows, a comment line every
+// 1000 rows and a quoted field with a line break in row 3
+func writeIndexTestFile(t *testing.T, rows int) string {
+	t.Helper()
+	var sb strings.Builder
+	sb.WriteString("id,name,note\r\n")
+	for i := 1; i <= rows; i++ {
+		if i%1000 == 0 {
+			sb.WriteString("# checkpoint\n")
+		}
+		note := "plain"
+		if i == 3 {
+			note = "\"two\nlines\""
+		}
+		fmt.Fprintf(&sb, "%d,name%d,%s\n", i, i, note)
+	}
+	fn := filepath.Join(t.TempDir(), "big.csv")
+	if err := os.WriteFile(fn, []byte(sb.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return fn
+}
+
+func TestLoadIndexedFileToBuffer(t *testing.T) {
+	defer args.setDefault()
+	args.SkipSymbol = []string{"#"}
+
+	fn := writeIndexTestFile(t, 5000)
+	b := createNewBuffer()
+	if err := loadIndexedFileToBuffer(fn, b); err != nil {
+		t.Fatalf("loadIndexedFileToBuffer() error = %v", err)
+	}
+
+	if b.index == nil {
+		t.Fatal("Expected buffer to be indexed")
+	}
+	if got := b.rowCount(); got != 5001 {
+		t.Errorf("rowCount() = %d, want 5001", got)
+	}
+	if b.rowLen != indexBlockRows {
+		t.Errorf("Expected a sample of %d rows in memory, got %d", indexBlockRows, b.rowLen)
+	}
+	if b.getColType(0) != colTypeFloat {
+		t.Errorf("id column type = %s, want Number", type2name(b.getColType(0)))
+	}
+
+	tests := []struct {
+		row  int
+		want []string
+	}{
+		{0, []string{"id", "name", "note"}},
+		{3, []string{"3", "name3", "two\nlines"}},
+		{1024, []string{"1024", "name1024", "plain"}},
+		{4999, []string{"4999", "name4999", "plain"}},
+		{5000, []string{"5000", "name5000", "plain"}},
+	}
+	for _, tt := range tests {
+		got := b.index.row(tt.row)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("row(%d) = %q, want %q", tt.row, got, tt.want)
+		}
+	}
+	if got := b.index.row(5001); got != nil {
+		t.Errorf("row(5001) = %q, want nil", got)
+	}
+
+	b.close()
+	if err := b.index.file.Close(); err == nil {
+		t.Error("The indexed file is still open after close")
+	}
+}
+
+func TestLineIndexCache(t *testing.T) {
+	defer args.setDefault()
+	fn := writeIndexTestFile(t, (indexCacheBlocks+8)*indexBlockRows)
+	b := createNewBuffer()
+	if err := loadIndexedFileToBuffer(fn, b); err != nil {
+		t.Fatal(err)
+	}
+	ix := b.index
+	for r := 0; r < ix.rowCount(); r += indexBlockRows {
+		if ix.row(r) == nil {
+			t.Fatalf("row(%d) = nil", r)
+		}
+	}
+	if len(ix.cache) > indexCacheBlocks || len(ix.lru) != len(ix.cache) {
+		t.Errorf("Cache holds %d blocks (lru %d), limit %d", len(ix.cache), len(ix.lru), indexCacheBlocks)
+	}
+	if _, ok := ix.cache[0]; ok {
+		t.Error("Least recently used block should have been evicted")
+	}
+	// Evicted rows are read again from disk
+	if got := ix.row(1); got == nil || got[0] != "1" {
+		t.Errorf("row(1) after eviction = %q", got)
+	}
+}
+
+func TestLoadIndexedFileToBufferAsync(t *testing.T) {
+	defer args.setDefault()
+	fn := writeIndexTestFile(t, 3000)
+
+	b := createNewBuffer()
+	updateChan := make(chan bool, 10)
+	doneChan := make(chan error, 1)
+	go loadIndexedFileToBufferAsync(fn, b, updateChan, doneChan)
+	if err := <-doneChan; err != nil {
+		t.Fatalf("loadIndexedFileToBufferAsync() error = %v", err)
+	}
+	if len(updateChan) == 0 {
+		t.Error("Expected at least one update signal")
+	}
+	if b.rowCount() != 3004 { // header, rows and the checkpoint comments
+		t.Errorf("rowCount() = %d, want 3004", b.rowCount())
+	}
+	if !b.progress.IsComplete || b.progress.GetPercentage() != 100 {
+		t.Errorf("Expected complete progress, got %.1f%%", b.progress.GetPercentage())
+	}
+}
+
+func TestPerformSearchIndexed(t *testing.T) {
+	defer args.setDefault()
+	args.SkipSymbol = []string{"#"}
+	fn := writeIndexTestFile(t, 5000)
+	b := createNewBuffer()
+	if err := loadIndexedFileToBuffer(fn, b); err != nil {
+		t.Fatal(err)
+	}
+
+	results := performSearch(b, "name499", false, false)
+	// name499 and name4990-4999
+	if len(results) != 11 {
+		t.Fatalf("performSearch() found %d results, want 11", len(results))
+	}
+	if results[0] != (SearchResult{Row: 499, Col: 1}) {
+		t.Errorf("First result = %+v, want row 499 col 1", results[0])
+	}
+	if got := b.index.search(func(string) bool { return true }, 5); len(got) != 5 {
+		t.Errorf("search() should stop at the limit, got %d results", len(got))
+	}
+}
+
+func TestUseLineIndex(t *testing.T) {
+	defer args.setDefault()
+	fn := writeIndexTestFile(t, 2000)
+
+	if useLineIndex(fn) {
+		t.Error("Files should be loaded into memory by default")
+	}
+	args.MemoryMB = 1
+	if useLineIndex(fn) {
+		t.Error("A file below the memory limit should be loaded into memory")
+	}
+	args.Index = true
+	if !useLineIndex(fn) {
+		t.Error("--index should force indexed mode")
+	}
+
+	// Compressed files cannot be indexed
+	gzFn := filepath.Join(t.TempDir(), "big.csv.gz")
+	f, err := os.Create(gzFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	zw.Write([]byte("a,b\n1,2\n"))
+	zw.Close()
+	f.Close()
+	if err := loadIndexedFileToBuffer(gzFn, createNewBuffer()); err == nil {
+		t.Error("Indexing a compressed file should fail")
+	}
+}
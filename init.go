@@ -1,6 +1,8 @@
 package main
 
 import (
+	"sync/atomic"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 )
@@ -34,7 +36,6 @@ var mainPage *tview.Frame        // Reference to main page for footer updates
 var bufferTable *tview.Table     // Reference to buffer table
 var fileNameStr string           // Store filename for footer
 var cursorPosStr string          // Store cursor position for footer
-var loadProgress LoadProgress    // Track loading progress
 var userMovedCursor bool         // Track if user has moved the cursor
 var wrappedColumns map[int]int   // Track which columns are wrapped and their max width
 var searchResults []SearchResult // Store search results
@@ -46,14 +47,33 @@ var searchUseRegex bool
 var originalBuffer *Buffer              // Store original buffer before filtering
 var isFiltered bool                     // Track if filter is active
 var activeFilters map[int]FilterOptions // Track active filters: column -> query
+var filterExpr *rowFilter               // Filter expression over all columns, nil when none
 var currentCursorColumn int             // Track current cursor column position
 var lastKeyWasG bool                    // Track if last key pressed was 'g' for gg navigation
+var subSources *sourceSet               // Sheets of a workbook, nil for single-table inputs
+var followScroll bool                   // Keep the newest row in view in follow mode
+var tabs []*tab                         // Files opened side by side, nil for a single input
+var currentTab int                      // Index of the displayed tab
+var visualAnchor *cellPos               // Corner where the visual selection started, nil outside visual mode
+var visualCursor cellPos                // Other corner of the visual selection
+var yankPending bool                    // y was pressed and waits for y, c or a timeout
+var yankSeq int                         // Counts y presses so a stale timeout does nothing
+var uiScreen tcell.Screen               // Screen of the running UI, the clipboard is set through it
+var lastSQLQuery string                 // Query of the SQL prompt, offered again when it reopens
+var sqlQueryCount int                   // Numbers the tabs opened for query results
+var lastGroupAggs = "count"             // Aggregates of the group-by form, offered again when it reopens
+var groupViewCount int                  // Numbers the tabs opened for group-by results
+var pivotViewCount int                  // Numbers the tabs opened for pivot tables
 
 // LoadProgress tracks loading progress
 type LoadProgress struct {
 	TotalBytes  int64
 	LoadedBytes int64
 	IsComplete  bool
+	// CompressedBytes counts the input consumed when a file is decompressed
+	// or transcoded, it then drives the percentage since TotalBytes is the
+	// size on disk
+	CompressedBytes *atomic.Int64
 }
 
 // GetPercentage returns the loading percentage (0-100)
@@ -61,7 +81,11 @@ func (lp *LoadProgress) GetPercentage() float64 {
 	if lp.TotalBytes <= 0 {
 		return 0
 	}
-	percent := float64(lp.LoadedBytes) * 100.0 / float64(lp.TotalBytes)
+	loaded := lp.LoadedBytes
+	if lp.CompressedBytes != nil {
+		loaded = lp.CompressedBytes.Load()
+	}
+	percent := float64(loaded) * 100.0 / float64(lp.TotalBytes)
 	if percent > 100 {
 		percent = 100
 	}
@@ -78,6 +102,10 @@ type SearchResult struct {
 func initView() {
 	app = tview.NewApplication()
 	app.EnableMouse(true) // Enable mouse support
+	app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		uiScreen = screen
+		return false
+	})
 	b = createNewBuffer()
 	wrappedColumns = make(map[int]int) // Initialize wrapped columns map
 	searchResults = []SearchResult{}
@@ -89,6 +117,7 @@ func initView() {
 	activeFilters = make(map[int]FilterOptions) // Initialize active filters map
 	currentCursorColumn = 0                     // Initialize cursor column
 	lastKeyWasG = false                         // Initialize vim navigation state
+	followScroll = true                         // Auto-scroll while following
 }
 
 // stop UI
@@ -102,9 +131,13 @@ func updateFooterWithStatus(status string) {
 	if mainPage != nil {
 		// Update the footer by rebuilding it
 		mainPage.Clear()
+		if filterInfoStr := buildFilterInfoStr(currentCursorColumn); filterInfoStr != "" {
+			mainPage.AddText(filterInfoStr, true, tview.AlignCenter, tcell.NewRGBColor(255, 140, 0))
+		}
 		mainPage.AddText(fileNameStr, false, tview.AlignLeft, tcell.ColorDarkOrange).
 			AddText(status, false, tview.AlignCenter, tcell.ColorDarkOrange).
 			AddText(cursorPosStr, false, tview.AlignRight, tcell.ColorDarkOrange)
+		addTabStrip(mainPage)
 	}
 }
 
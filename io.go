@@ -2,7 +2,6 @@ package main
 
 import (
 	"bufio"
-	"compress/gzip"
 	"encoding/csv"
 	"errors"
 	"fmt"
@@ -12,6 +11,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
 )
 
 // progressTracker helps display loading progress
@@ -23,6 +25,7 @@ type progressTracker struct {
 	lineCount    int
 	showProgress bool
 	startTime    time.Time
+	load         *LoadProgress // progress of the buffer, counts the input of compressed files
 }
 
 func newProgressTracker(total int64, showProgress bool) *progressTracker {
@@ -32,7 +35,7 @@ func newProgressTracker(total int64, showProgress bool) *progressTracker {
 		lastUpdate:   time.Now(),
 		updateEvery:  5000, // update every 5000 lines
 		lineCount:    0,
-		showProgress: showProgress,
+		showProgress: showProgress && mainPage == nil && !args.Print, // it would scribble over the UI or the printed table
 		startTime:    time.Now(),
 	}
 }
@@ -63,7 +66,11 @@ func (p *progressTracker) display() {
 	linesPerSec := float64(p.lineCount) / elapsed
 
 	if p.total > 0 {
-		percent := float64(p.current) * 100.0 / float64(p.total)
+		current := p.current
+		if p.load != nil && p.load.CompressedBytes != nil {
+			current = p.load.CompressedBytes.Load()
+		}
+		percent := float64(current) * 100.0 / float64(p.total)
 		if percent > 100 {
 			percent = 100
 		}
@@ -114,26 +121,27 @@ func loadFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneCha
 	}
 
 	// Initialize load progress
-	loadProgress.TotalBytes = fileSize
-	loadProgress.LoadedBytes = 0
-	loadProgress.IsComplete = false
+	b.progress.TotalBytes = fileSize
+	b.progress.LoadedBytes = 0
+	b.progress.IsComplete = false
 
 	// Create progress tracker (disabled for async loading since UI will show it)
 	progress := newProgressTracker(fileSize, false)
 
-	scanner, err := getFileScanner(fn)
+	scanner, closer, err := getFileScanner(fn, &b.progress)
 	if err != nil {
 		doneChan <- err
 		return
 	}
 	scanner.Split(bufio.ScanLines)
+	records := newRecordScanner(scanner, b.dialect)
 	//set separator, if user does not provide it.
 	var detectLines []string //lines as detect separator data
-	if b.sep == 0 {
+	if b.sep == "" {
 		//read 10 lines to detect separator
 		lineNumber := 10
-		for scanner.Scan() {
-			line := scanner.Text()
+		for records.Scan() {
+			line := records.Text()
 			//skip empty line
 			if line == "\n" {
 				continue
@@ -154,34 +162,38 @@ func loadFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneCha
 		}
 		//if the suffix of file name is ".csv", set separator to ",".
 		//if the suffix of file name is "tsv", set separator to "\t".
-		if strings.HasSuffix(fn, ".csv") {
-			b.sep = ','
-		} else if strings.HasSuffix(fn, ".tsv") {
-			b.sep = '\t'
+		if strings.HasSuffix(plainFileName(fn), ".csv") {
+			b.sep = ","
+		} else if strings.HasSuffix(plainFileName(fn), ".tsv") {
+			b.sep = "\t"
 		} else {
 			sd := sepDetecor{}
 			b.sep = sd.sepDetect(detectLines)
 		}
-
+		useFixedWidth(b, detectLines, args.FixedWidth)
 	}
 	//check final separator
-	if b.sep == 0 {
+	if b.sep == "" {
+		closer.Close()
 		doneChan <- errors.New("tv can't identify separator, you need to set it manual")
 		return
 	}
+	records.dialect = b.dialect
+	records.plain = b.plainRecords()
 
 	//add detectLines to buffer
 	for _, line := range detectLines {
 		//parse and add line to buffer
-		err = addDRToBuffer(b, line, args.ShowNum, args.HideNum)
+		err = addDRToBuffer(b, line)
 		if err != nil {
+			closer.Close()
 			progress.finish()
 			doneChan <- err
 			return
 		}
 		totalAddedLN++
 		bytesRead := int64(len(line) + 1) // +1 for newline
-		loadProgress.LoadedBytes += bytesRead
+		b.progress.LoadedBytes += bytesRead
 		progress.increment(bytesRead)
 		if totalAddedLN >= args.NLine && args.NLine > 0 {
 			break
@@ -200,6 +212,8 @@ func loadFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneCha
 
 	lineChan := make(chan string, numWorkers*10)        // Input: raw lines
 	resultChan := make(chan *ParsedLine, numWorkers*10) // Output: parsed lines
+	stop := make(chan struct{})                         // Closed when the collector stops taking rows
+	defer close(stop)
 
 	// Start worker goroutines for parsing
 	var wg sync.WaitGroup
@@ -208,13 +222,17 @@ func loadFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneCha
 		go func() {
 			defer wg.Done()
 			for line := range lineChan {
-				fields, err := lineCSVParseFast(line, b.sep)
+				fields, err := b.splitLine(line)
 				result := &ParsedLine{
 					Fields: fields,
 					Bytes:  int64(len(line) + 1),
 					Err:    err,
 				}
-				resultChan <- result
+				select {
+				case resultChan <- result:
+				case <-stop:
+					return
+				}
 			}
 		}()
 	}
@@ -225,31 +243,35 @@ func loadFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneCha
 		close(resultChan)
 	}()
 
-	// Goroutine to read lines and send to workers
+	// Goroutine to read lines and send to workers until the collector stops
+	// taking rows, it closes the file once it stops reading. It may still be
+	// reading when the loader returns, so it keeps its own copy of the options.
+	skipNum, skipSymbol, nLine := args.SkipNum, args.SkipSymbol, args.NLine
 	go func() {
-		for scanner.Scan() {
-			line := scanner.Text()
+		defer closer.Close()
+		defer close(lineChan)
+		for records.Scan() {
+			line := records.Text()
 			//skip empty line
 			if line == "\n" {
 				continue
 			}
 			//ignore first n lines
-			if args.SkipNum > 0 && args.NLine > 0 {
-				args.SkipNum--
+			if skipNum > 0 && nLine > 0 {
+				skipNum--
 				continue
 			}
 			//ignore line with specified prefix
-			if skipLine(line, args.SkipSymbol) {
+			if skipLine(line, skipSymbol) {
 				continue
 			}
 
-			if totalAddedLN >= args.NLine && args.NLine > 0 {
-				break
+			select {
+			case lineChan <- line:
+			case <-stop:
+				return
 			}
-
-			lineChan <- line
 		}
-		close(lineChan)
 	}()
 
 	// Main thread: collect parsed results and add to buffer
@@ -263,21 +285,17 @@ func loadFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneCha
 			return
 		}
 
+		// Line skipped by --pattern
+		if result.Fields == nil {
+			continue
+		}
+
 		// Apply column filtering if needed
-		var fields []string
-		if len(args.ShowNum) != 0 || len(args.HideNum) != 0 {
-			visCol, err := getVisCol(args.ShowNum, args.HideNum, len(result.Fields))
-			if err != nil {
-				progress.finish()
-				doneChan <- err
-				return
-			}
-			fields = make([]string, 0, len(visCol))
-			for _, i := range visCol {
-				fields = append(fields, result.Fields[i])
-			}
-		} else {
-			fields = result.Fields
+		fields, err := b.visibleFields(result.Fields)
+		if err != nil {
+			progress.finish()
+			doneChan <- err
+			return
 		}
 
 		// Add to buffer
@@ -290,7 +308,7 @@ func loadFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneCha
 
 		totalAddedLN++
 		batchSize++
-		loadProgress.LoadedBytes += result.Bytes
+		b.progress.LoadedBytes += result.Bytes
 		progress.increment(result.Bytes)
 
 		// Update UI periodically
@@ -308,10 +326,11 @@ func loadFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneCha
 		}
 	}
 
-	loadProgress.IsComplete = true
+	b.progress.IsComplete = true
 
-	// Auto-detect column types after loading (async)
-	go b.detectAllColumnTypes()
+	// Detect column types before reporting done, so the final redraw shows
+	// them and nothing changes them after the loader returns
+	b.detectAllColumnTypes()
 
 	// Enable string interning for categorical columns (async)
 	go b.enableStringInterning()
@@ -336,19 +355,22 @@ func loadFileToBuffer(fn string, b *Buffer) error {
 
 	// Create progress tracker
 	progress := newProgressTracker(fileSize, true)
+	progress.load = &b.progress
 
-	scanner, err := getFileScanner(fn)
+	scanner, closer, err := getFileScanner(fn, &b.progress)
 	if err != nil {
 		return err
 	}
+	defer closer.Close()
 	scanner.Split(bufio.ScanLines)
+	records := newRecordScanner(scanner, b.dialect)
 	//set separator, if user does not provide it.
 	var detectLines []string //lines as detect separator data
-	if b.sep == 0 {
+	if b.sep == "" {
 		//read 10 lines to detect separator
 		lineNumber := 10
-		for scanner.Scan() {
-			line := scanner.Text()
+		for records.Scan() {
+			line := records.Text()
 			//skip empty line
 			if line == "\n" {
 				continue
@@ -369,25 +391,27 @@ func loadFileToBuffer(fn string, b *Buffer) error {
 		}
 		//if the suffix of file name is ".csv", set separator to ",".
 		//if the suffix of file name is "tsv", set separator to "\t".
-		if strings.HasSuffix(fn, ".csv") {
-			b.sep = ','
-		} else if strings.HasSuffix(fn, ".tsv") {
-			b.sep = '\t'
+		if strings.HasSuffix(plainFileName(fn), ".csv") {
+			b.sep = ","
+		} else if strings.HasSuffix(plainFileName(fn), ".tsv") {
+			b.sep = "\t"
 		} else {
 			sd := sepDetecor{}
 			b.sep = sd.sepDetect(detectLines)
 		}
-
+		useFixedWidth(b, detectLines, args.FixedWidth)
 	}
 	//check final separator
-	if b.sep == 0 {
+	if b.sep == "" {
 		fatalError(errors.New("tv can't identify separator, you need to set it manual"))
 	}
+	records.dialect = b.dialect
+	records.plain = b.plainRecords()
 
 	//add detectLines to buffer
 	for _, line := range detectLines {
 		//parse and add line to buffer
-		err = addDRToBuffer(b, line, args.ShowNum, args.HideNum)
+		err = addDRToBuffer(b, line)
 		if err != nil {
 			progress.finish()
 			return err
@@ -400,8 +424,8 @@ func loadFileToBuffer(fn string, b *Buffer) error {
 		}
 	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+	for records.Scan() {
+		line := records.Text()
 		//skip empty line
 		if line == "\n" {
 			continue
@@ -420,7 +444,7 @@ func loadFileToBuffer(fn string, b *Buffer) error {
 		if totalAddedLN >= args.NLine && args.NLine > 0 {
 			break
 		}
-		err = addDRToBuffer(b, line, args.ShowNum, args.HideNum)
+		err = addDRToBuffer(b, line)
 		if err != nil {
 			progress.finish()
 			return err
@@ -445,9 +469,9 @@ func loadPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, d
 	var err error
 
 	// For pipes, we don't know the total size
-	loadProgress.TotalBytes = 0
-	loadProgress.LoadedBytes = 0
-	loadProgress.IsComplete = false
+	b.progress.TotalBytes = 0
+	b.progress.LoadedBytes = 0
+	b.progress.IsComplete = false
 
 	// Create progress tracker (disabled for async loading)
 	progress := newProgressTracker(0, false)
@@ -457,35 +481,52 @@ func loadPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, d
 	const maxScanTokenSize = 1024 * 1024
 	buf := make([]byte, maxScanTokenSize)
 	scanner.Buffer(buf, maxScanTokenSize)
-	//read 10 lines to detect separator
-	lineNumber := 10
-	var detectLines []string //lines as detect separator data
-	if b.sep == 0 {
-		for scanner.Scan() {
-			line := scanner.Text()
+	records := newRecordScanner(scanner, b.dialect)
+	// next returns the next line to show, it runs on the goroutines reading
+	// the pipe and keeps its own count of the lines to skip
+	skipNum, skipSymbol := args.SkipNum, args.SkipSymbol
+	next := func() (string, bool) {
+		for records.Scan() {
+			line := records.Text()
 			//skip empty line
 			if line == "\n" {
 				continue
 			}
 			//ignore first n lines
-			if args.SkipNum > 0 {
-				args.SkipNum--
+			if skipNum > 0 {
+				skipNum--
 				continue
 			}
 			//ignore line with specified prefix
-			if skipLine(line, args.SkipSymbol) {
+			if skipLine(line, skipSymbol) {
 				continue
 			}
-			detectLines = append(detectLines, line)
-			if len(detectLines) >= lineNumber {
-				break
+			return line, true
+		}
+		return "", false
+	}
+	//read 10 lines to detect separator
+	lineNumber := 10
+	var detectLines []string       //lines as detect separator data
+	var pending <-chan scannedLine // line of a followed pipe detection stopped waiting for
+	if b.sep == "" {
+		if args.Follow {
+			detectLines, pending = sampleFollowedLines(next, lineNumber)
+		} else {
+			for len(detectLines) < lineNumber {
+				line, ok := next()
+				if !ok {
+					break
+				}
+				detectLines = append(detectLines, line)
 			}
 		}
 		sd := sepDetecor{}
 		b.sep = sd.sepDetect(detectLines)
+		useFixedWidth(b, detectLines, args.FixedWidth)
 	}
 	//check final separator
-	if b.sep == 0 {
+	if b.sep == "" {
 		doneChan <- errors.New("tv can't identify separator, you need to set it manual")
 		return
 	}
@@ -493,7 +534,7 @@ func loadPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, d
 	//add detectLines to buffer
 	for _, line := range detectLines {
 		//parse and add line to buffer
-		err = addDRToBuffer(b, line, args.ShowNum, args.HideNum)
+		err = addDRToBuffer(b, line)
 		if err != nil {
 			progress.finish()
 			doneChan <- err
@@ -501,7 +542,7 @@ func loadPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, d
 		}
 		totalAddedLN++
 		bytesRead := int64(len(line) + 1)
-		loadProgress.LoadedBytes += bytesRead
+		b.progress.LoadedBytes += bytesRead
 		progress.increment(bytesRead)
 		if totalAddedLN >= args.NLine && args.NLine > 0 {
 			break
@@ -519,6 +560,8 @@ func loadPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, d
 
 	lineChan := make(chan string, numWorkers*10)
 	resultChan := make(chan *ParsedLine, numWorkers*10)
+	stop := make(chan struct{})
+	defer close(stop)
 
 	// Start worker goroutines
 	var wg sync.WaitGroup
@@ -527,13 +570,17 @@ func loadPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, d
 		go func() {
 			defer wg.Done()
 			for line := range lineChan {
-				fields, err := lineCSVParseFast(line, b.sep)
+				fields, err := b.splitLine(line)
 				result := &ParsedLine{
 					Fields: fields,
 					Bytes:  int64(len(line) + 1),
 					Err:    err,
 				}
-				resultChan <- result
+				select {
+				case resultChan <- result:
+				case <-stop:
+					return
+				}
 			}
 		}()
 	}
@@ -544,26 +591,33 @@ func loadPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, d
 		close(resultChan)
 	}()
 
-	// Read lines and send to workers
+	// Read lines and send to workers until the collector stops taking rows
 	go func() {
-		for scanner.Scan() {
-			line := scanner.Text()
-			if line == "\n" {
-				continue
-			}
-			if args.SkipNum > 0 {
-				args.SkipNum--
-				continue
+		defer close(lineChan)
+		// The line detection stopped waiting for was read before the
+		// separator was known, the records after it are read with it
+		if pending != nil {
+			if l := <-pending; l.ok {
+				select {
+				case lineChan <- l.text:
+				case <-stop:
+					return
+				}
 			}
-			if skipLine(line, args.SkipSymbol) {
-				continue
+		}
+		records.dialect = b.dialect
+		records.plain = b.plainRecords()
+		for {
+			line, ok := next()
+			if !ok {
+				return
 			}
-			if totalAddedLN >= args.NLine && args.NLine > 0 {
-				break
+			select {
+			case lineChan <- line:
+			case <-stop:
+				return
 			}
-			lineChan <- line
 		}
-		close(lineChan)
 	}()
 
 	// Collect results
@@ -576,21 +630,15 @@ func loadPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, d
 			doneChan <- result.Err
 			return
 		}
+		if result.Fields == nil {
+			continue
+		}
 
-		var fields []string
-		if len(args.ShowNum) != 0 || len(args.HideNum) != 0 {
-			visCol, err := getVisCol(args.ShowNum, args.HideNum, len(result.Fields))
-			if err != nil {
-				progress.finish()
-				doneChan <- err
-				return
-			}
-			fields = make([]string, 0, len(visCol))
-			for _, i := range visCol {
-				fields = append(fields, result.Fields[i])
-			}
-		} else {
-			fields = result.Fields
+		fields, err := b.visibleFields(result.Fields)
+		if err != nil {
+			progress.finish()
+			doneChan <- err
+			return
 		}
 
 		err = b.contAppendSli(fields, args.Strict)
@@ -602,7 +650,7 @@ func loadPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, d
 
 		totalAddedLN++
 		batchSize++
-		loadProgress.LoadedBytes += result.Bytes
+		b.progress.LoadedBytes += result.Bytes
 		progress.increment(result.Bytes)
 
 		if batchSize >= updateInterval {
@@ -618,10 +666,10 @@ func loadPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, d
 		}
 	}
 
-	loadProgress.IsComplete = true
+	b.progress.IsComplete = true
 
-	// Auto-detect column types after loading (async)
-	go b.detectAllColumnTypes()
+	// Types are set before done, as loadFileToBufferAsync does
+	b.detectAllColumnTypes()
 
 	// Enable string interning for categorical columns (async)
 	go b.enableStringInterning()
@@ -643,12 +691,13 @@ func loadPipeToBuffer(stdin io.Reader, b *Buffer) error {
 	const maxScanTokenSize = 1024 * 1024
 	buf := make([]byte, maxScanTokenSize)
 	scanner.Buffer(buf, maxScanTokenSize)
+	records := newRecordScanner(scanner, b.dialect)
 	//read 10 lines to detect separator
 	lineNumber := 10
 	var detectLines []string //lines as detect separator data
-	if b.sep == 0 {
-		for scanner.Scan() {
-			line := scanner.Text()
+	if b.sep == "" {
+		for records.Scan() {
+			line := records.Text()
 			//skip empty line
 			if line == "\n" {
 				continue
@@ -669,16 +718,19 @@ func loadPipeToBuffer(stdin io.Reader, b *Buffer) error {
 		}
 		sd := sepDetecor{}
 		b.sep = sd.sepDetect(detectLines)
+		useFixedWidth(b, detectLines, args.FixedWidth)
 	}
 	//check final separator
-	if b.sep == 0 {
+	if b.sep == "" {
 		fatalError(errors.New("tv can't identify separator, you need to set it manual"))
 	}
+	records.dialect = b.dialect
+	records.plain = b.plainRecords()
 
 	//add detectLines to buffer
 	for _, line := range detectLines {
 		//parse and add line to buffer
-		err = addDRToBuffer(b, line, args.ShowNum, args.HideNum)
+		err = addDRToBuffer(b, line)
 		if err != nil {
 			progress.finish()
 			return err
@@ -689,8 +741,8 @@ func loadPipeToBuffer(stdin io.Reader, b *Buffer) error {
 			break
 		}
 	}
-	for scanner.Scan() {
-		line := scanner.Text()
+	for records.Scan() {
+		line := records.Text()
 		//skip empty line
 		if line == "\n" {
 			continue
@@ -709,7 +761,7 @@ func loadPipeToBuffer(stdin io.Reader, b *Buffer) error {
 		if totalAddedLN >= args.NLine && args.NLine > 0 {
 			break
 		}
-		err = addDRToBuffer(b, line, args.ShowNum, args.HideNum)
+		err = addDRToBuffer(b, line)
 		if err != nil {
 			progress.finish()
 			return err
@@ -739,100 +791,225 @@ func skipLine(line string, sy []string) bool {
 	return false
 }
 
-// get suitable scanner(compressed or not)
-func getFileScanner(fn string) (*bufio.Scanner, error) {
+// openFileReader opens fn and returns a reader over its content as UTF-8 text
+// (decompressed and transcoded if needed) and the closer of the underlying file
+func openFileReader(fn string) (io.Reader, io.Closer, error) {
 	info, err := os.Stat(fn)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	//check if fn is a directory
 	if info.IsDir() {
-		return nil, errors.New(fn + " is a directory")
+		return nil, nil, errors.New(fn + " is a directory")
 	}
 
 	file, err := os.Open(fn)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	var scanner *bufio.Scanner
-	//if input is a gzip file
-	if strings.HasSuffix(fn, ".gz") {
-		gzCont, err := gzip.NewReader(file)
+	//decompress by content, so a suffix is neither needed nor trusted
+	br := bufio.NewReader(file)
+	head, _ := br.Peek(compressMagicLen)
+	kind := detectCompression(head)
+	if kind == compressNone {
+		enc, err := textEncoding(br)
 		if err != nil {
-			return nil, err
+			file.Close()
+			return nil, nil, err
 		}
-		scanner = bufio.NewScanner(gzCont)
-	} else {
-		scanner = bufio.NewScanner(file)
+		if enc == nil {
+			return br, file, nil
+		}
+		raw := &countingReader{r: br}
+		return &textReader{transform.NewReader(raw, enc.NewDecoder()), raw}, file, nil
 	}
+	dr, err := newDecompressor(kind, br)
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	text := bufio.NewReader(dr)
+	enc, err := textEncoding(text)
+	if err != nil {
+		fileCloser{file, dr}.Close()
+		return nil, nil, err
+	}
+	if enc == nil {
+		return &textReader{text, dr.raw}, fileCloser{file, dr}, nil
+	}
+	return &textReader{transform.NewReader(text, enc.NewDecoder()), dr.raw}, fileCloser{file, dr}, nil
+}
+
+// fileCloser closes a decoder and then the file under it
+type fileCloser struct {
+	file *os.File
+	dec  io.Closer
+}
+
+func (fc fileCloser) Close() error {
+	fc.dec.Close()
+	return fc.file.Close()
+}
 
+// newLineScanner creates a scanner with a buffer large enough for long lines
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
 	//increase buffer size for large files and long lines
 	//default is 64KB, we set to 1MB for better performance
 	const maxScanTokenSize = 1024 * 1024
 	buf := make([]byte, maxScanTokenSize)
 	scanner.Buffer(buf, maxScanTokenSize)
+	return scanner
+}
+
+// get suitable scanner(compressed or not), progress of compressed or
+// transcoded files is measured in file bytes so it matches the file size.
+// The caller closes the file and decompressor with the returned closer.
+func getFileScanner(fn string, progress *LoadProgress) (*bufio.Scanner, io.Closer, error) {
+	reader, closer, err := openFileReader(fn)
+	if err != nil {
+		return nil, nil, err
+	}
+	progress.CompressedBytes = nil
+	if tr, ok := reader.(*textReader); ok {
+		progress.CompressedBytes = &tr.raw.n
+	}
+	return newLineScanner(reader), closer, nil
+}
 
-	return scanner, nil
+// maxQuotedRecordBytes bounds how far a quoted field may span physical lines
+// before the quote is treated as stray and the lines are read one by one again
+const maxQuotedRecordBytes = 1024 * 1024
+
+// recordScanner wraps a line scanner and yields whole CSV records, joining
+// physical lines when a quoted field contains a line break (RFC 4180)
+type recordScanner struct {
+	scanner *bufio.Scanner
+	dialect          // finds field starts and quotes (sep "" = not known yet)
+	plain   bool     // input has no quoting (fixed-width text), lines are records
+	pending []string // lines read ahead but not consumed
+	record  string
 }
 
-// check columns that should be displayed
-func getVisCol(showNumL, hideNumL []int, colLen int) ([]int, error) {
-	for _, i := range showNumL {
-		if i > colLen || i <= 0 {
-			return nil, errors.New("Column number " + I2S(i) + " does not exist")
-		}
+// newRecordScanner creates a record scanner on top of a line scanner
+func newRecordScanner(scanner *bufio.Scanner, d dialect) *recordScanner {
+	return &recordScanner{scanner: scanner, dialect: d}
+}
+
+// nextLine returns the next physical line, preferring lines that were pushed back
+func (rs *recordScanner) nextLine() (string, bool) {
+	if len(rs.pending) > 0 {
+		line := rs.pending[0]
+		rs.pending = rs.pending[1:]
+		return line, true
+	}
+	if rs.scanner.Scan() {
+		return rs.scanner.Text(), true
 	}
+	return "", false
+}
 
-	for _, i := range hideNumL {
-		if i > colLen || i <= 0 {
-			return nil, errors.New("Column number " + I2S(i) + " does not exist")
-		}
+// Scan advances to the next record, it returns false at the end of input
+func (rs *recordScanner) Scan() bool {
+	line, ok := rs.nextLine()
+	if !ok {
+		return false
 	}
 
-	var visCol []int
-	for i := 0; i < colLen; i++ {
-		flag, err := checkVisible(showNumL, hideNumL, i)
-		if err != nil {
-			return nil, err
+	// Fast path: lines without quotes or with balanced quotes are whole records
+	if rs.plain || rs.unquoted(line) || !inQuotedField(line, rs.dialect, false) {
+		rs.record = line
+		return true
+	}
+
+	lines := []string{line}
+	size := len(line)
+	for {
+		next, ok := rs.nextLine()
+		if !ok {
+			// Unterminated quote at EOF, the lazy CSV parser copes with it
+			break
 		}
-		if flag {
-			visCol = append(visCol, i)
+		lines = append(lines, next)
+		size += len(next) + 1
+		if !inQuotedField(next, rs.dialect, true) {
+			break
+		}
+		if size > maxQuotedRecordBytes {
+			// Most likely a stray quote: return the first line on its own
+			// and re-read the rest as independent lines
+			rs.pending = append(append([]string{}, lines[1:]...), rs.pending...)
+			rs.record = line
+			return true
 		}
 	}
-	return visCol, nil
+	rs.record = strings.Join(lines, "\n")
+	return true
+}
 
+// Text returns the current record
+func (rs *recordScanner) Text() string {
+	return rs.record
 }
 
-// check ith column should be displayed or not
-func checkVisible(showNumL, hideNumL []int, col int) (bool, error) {
-	if len(showNumL) != 0 && len(hideNumL) != 0 {
-		return false, errors.New("you can only set visible column or hidden column")
-	}
+// Err returns the first non-EOF error of the underlying scanner
+func (rs *recordScanner) Err() error {
+	return rs.scanner.Err()
+}
 
-	if len(showNumL) != 0 {
-		for _, colTestS := range showNumL {
-			if col+1 == colTestS {
-				return true, nil
+// inQuotedField reports whether a quoted field is still open at the end of line.
+// inQuotes is the state carried over from the previous line of the same record.
+// A quote only opens a field at the start of that field, like encoding/csv does.
+func inQuotedField(line string, d dialect, inQuotes bool) bool {
+	if d.quote == 0 {
+		return false
+	}
+	fieldStart := !inQuotes
+	for i := 0; i < len(line); {
+		if !inQuotes {
+			if n := d.sepAt(line, i); n > 0 {
+				i += n
+				fieldStart = true
+				continue
 			}
 		}
-		return false, nil
-	}
-	if len(hideNumL) != 0 {
-		for _, colTestH := range hideNumL {
-			if col+1 == colTestH {
-				return false, nil
+		r, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+		if r == d.escape && d.escape != 0 {
+			// The escaped character neither opens nor closes a field
+			_, size = utf8.DecodeRuneInString(line[i:])
+			i += size
+			fieldStart = false
+			continue
+		}
+		if inQuotes {
+			if r == d.quote {
+				if next, size := utf8.DecodeRuneInString(line[i:]); next == d.quote && i < len(line) {
+					i += size // escaped quote ("")
+					continue
+				}
+				inQuotes = false
 			}
+			continue
+		}
+		if r == d.quote && fieldStart {
+			inQuotes = true
 		}
+		fieldStart = false
 	}
-	return true, nil
+	return inQuotes
 }
 
 // use go csv library to parse a string line into csv format
-// Optimized version with reusable reader
-func lineCSVParse(s string, sep rune) ([]string, error) {
+// Optimized version with reusable reader. Separators of several characters,
+// other quotes and escapes are beyond the csv package and split by the dialect.
+func lineCSVParse(s string, d dialect) ([]string, error) {
+	if !d.standard() {
+		return d.split(s), nil
+	}
 	r := csv.NewReader(strings.NewReader(s))
-	r.Comma = sep
+	r.Comma, _ = utf8.DecodeRuneInString(d.sep)
 	r.LazyQuotes = true
 	r.ReuseRecord = true //reuse backing array for performance
 	//r.TrimLeadingSpace = true //disable, because it will remove NULL item and cause issue.
@@ -848,70 +1025,55 @@ func lineCSVParse(s string, sep rune) ([]string, error) {
 
 // Fast CSV parser for simple cases (no quotes, no escaping)
 // Falls back to standard parser if needed
-func lineCSVParseFast(s string, sep rune) ([]string, error) {
-	// Quick check if line contains quotes (needs full parser)
-	hasQuotes := false
-	for i := 0; i < len(s); i++ {
-		if s[i] == '"' {
-			hasQuotes = true
-			break
+func lineCSVParseFast(s string, d dialect) ([]string, error) {
+	// Use fast path for simple CSV lines
+	if d.unquoted(s) {
+		if d.sep == "" {
+			return []string{s}, nil
 		}
+		return strings.Split(s, d.sep), nil
 	}
 
-	// Use fast path for simple CSV lines
-	if !hasQuotes {
-		// Count separators to pre-allocate slice
-		sepCount := 0
-		for i := 0; i < len(s); i++ {
-			if rune(s[i]) == sep {
-				sepCount++
-			}
-		}
+	// Fall back to standard parser for complex cases
+	return lineCSVParse(s, d)
+}
 
-		result := make([]string, 0, sepCount+1)
-		start := 0
-		for i := 0; i < len(s); i++ {
-			if rune(s[i]) == sep {
-				result = append(result, s[start:i])
-				start = i + 1
-			}
-		}
-		// Add last field
-		result = append(result, s[start:])
-		return result, nil
+// splitLine splits a line into fields, by pattern, by separator or by
+// fixed-width columns. It returns nil fields for lines the pattern skips.
+func (b *Buffer) splitLine(line string) ([]string, error) {
+	if b.pattern != nil {
+		return b.pattern.split(line), nil
 	}
+	if b.colStarts != nil {
+		return splitFixedWidth(line, b.colStarts), nil
+	}
+	return lineCSVParseFast(line, b.dialect)
+}
 
-	// Fall back to standard parser for complex cases
-	return lineCSVParse(s, sep)
+// plainRecords reports whether every line is a record of its own, quotes
+// only join lines in delimited text
+func (b *Buffer) plainRecords() bool {
+	return b.colStarts != nil || b.pattern != nil
 }
 
 // add displayable(according to user's input argument) RowArray(covert line to array) To Buffer
-func addDRToBuffer(b *Buffer, line string, showNum, hideNum []int) error {
-	var err error
-	lineCSVParts, err := lineCSVParseFast(line, b.sep)
+func addDRToBuffer(b *Buffer, line string) error {
+	lineCSVParts, err := b.splitLine(line)
 	if err != nil {
 		return err
 	}
-	if len(showNum) != 0 || len(hideNum) != 0 {
-		// Pre-allocate slice with known capacity
-		visCol, err := getVisCol(showNum, hideNum, len(lineCSVParts))
-		if err != nil {
-			return err
-		}
-		lineSli := make([]string, 0, len(visCol))
-		for _, i := range visCol {
-			lineSli = append(lineSli, lineCSVParts[i])
-		}
-		err = b.contAppendSli(lineSli, args.Strict)
-		if err != nil {
-			return err
-		}
+	//line skipped by --pattern
+	if lineCSVParts == nil {
+		return nil
+	}
+	return appendVisible(b, lineCSVParts)
+}
 
-	} else {
-		err := b.contAppendSli(lineCSVParts, args.Strict)
-		if err != nil {
-			return err
-		}
+// appendVisible appends the displayable fields of a row to the buffer
+func appendVisible(b *Buffer, fields []string) error {
+	fields, err := b.visibleFields(fields)
+	if err != nil {
+		return err
 	}
-	return err
+	return b.contAppendSli(fields, args.Strict)
 }
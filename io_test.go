@@ -1,7 +1,11 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -92,6 +96,27 @@ func TestLoadFileToBuffer_Compressed(t *testing.T) {
 	}
 }
 
+func TestGetFileScannerCloser(t *testing.T) {
+	var progress LoadProgress
+	scanner, closer, err := getFileScanner("./data/test/compressed_data.csv.gz", &progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !scanner.Scan() {
+		t.Fatalf("Expected a first line, got error %v", scanner.Err())
+	}
+	if progress.CompressedBytes == nil {
+		t.Error("Expected progress to count the compressed bytes")
+	}
+	if err := closer.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	// The file under the decompressor is closed
+	if err := closer.Close(); err == nil {
+		t.Error("Expected closing twice to fail")
+	}
+}
+
 func TestLoadFileToBuffer_TSV(t *testing.T) {
 	b := createNewBuffer()
 	err := loadFileToBuffer("./data/test/tab_separated.tsv", b)
@@ -100,7 +125,7 @@ func TestLoadFileToBuffer_TSV(t *testing.T) {
 		return
 	}
 
-	if b.sep != '\t' {
+	if b.sep != "\t" {
 		t.Errorf("Expected tab separator, got %q", b.sep)
 	}
 }
@@ -216,6 +241,34 @@ func TestLoadFileToBufferAsync(t *testing.T) {
 	}
 }
 
+func TestLoadFileToBufferAsyncLineLimit(t *testing.T) {
+	defer args.setDefault()
+	var sb strings.Builder
+	sb.WriteString("id,name\n")
+	for i := 1; i <= 5000; i++ {
+		fmt.Fprintf(&sb, "%d,name%d\n", i, i)
+	}
+	fn := filepath.Join(t.TempDir(), "rows.csv")
+	if err := os.WriteFile(fn, []byte(sb.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	args.NLine = 50
+	b := createNewBuffer()
+	updateChan := make(chan bool, 10)
+	doneChan := make(chan error, 1)
+	go loadFileToBufferAsync(fn, b, updateChan, doneChan)
+	if err := <-doneChan; err != nil {
+		t.Fatalf("loadFileToBufferAsync() error = %v", err)
+	}
+	if b.rowLen != 50 {
+		t.Errorf("rowLen = %d, want the 50 of -n", b.rowLen)
+	}
+	if b.getColType(0) != colTypeFloat {
+		t.Errorf("column 0 type = %s, want the types detected when done", type2name(b.getColType(0)))
+	}
+}
+
 func TestLoadPipeToBufferAsync(t *testing.T) {
 	csvData := "Name,Age,City\nJohn,30,NYC\nJane,25,LA\n"
 	reader := strings.NewReader(csvData)
@@ -272,84 +325,6 @@ func Test_skipLine(t *testing.T) {
 	}
 }
 
-// ========================================
-// Column Visibility Tests
-// ========================================
-
-func Test_getVisCol(t *testing.T) {
-	type args struct {
-		showNumL []int
-		hideNumL []int
-		colLen   int
-	}
-	tests := []struct {
-		name    string
-		args    args
-		want    []int
-		wantErr bool
-	}{
-		{"Set show argument", args{[]int{1, 2, 5}, []int{}, 6}, []int{0, 1, 4}, false},
-		{"Set hide argument", args{[]int{}, []int{1, 2, 5}, 6}, []int{2, 3, 5}, false},
-		{"No arguments set", args{[]int{}, []int{}, 6}, []int{0, 1, 2, 3, 4, 5}, false},
-		{"Both arguments error", args{[]int{1, 2, 3}, []int{1, 2, 3}, 6}, nil, true},
-		{"Show column out of range", args{[]int{1, 2, 3, 7}, []int{}, 6}, nil, true},
-		{"Hide column out of range", args{[]int{}, []int{1, 2, 3, 7}, 6}, nil, true},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := getVisCol(tt.args.showNumL, tt.args.hideNumL, tt.args.colLen)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("getVisCol() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("getVisCol() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
-func Test_checkVisible(t *testing.T) {
-	type args struct {
-		showNumL []int
-		hideNumL []int
-		col      int
-	}
-	tests := []struct {
-		name    string
-		args    args
-		want    bool
-		wantErr bool
-	}{
-		{"Show list col 5", args{[]int{1, 3, 5}, []int{}, 5}, false, false},
-		{"Show list col 4", args{[]int{1, 3, 5}, []int{}, 4}, true, false},
-		{"Show list col 3", args{[]int{1, 3, 5}, []int{}, 3}, false, false},
-		{"Show list col 2", args{[]int{1, 3, 5}, []int{}, 2}, true, false},
-		{"Show list col 1", args{[]int{1, 3, 5}, []int{}, 1}, false, false},
-		{"Show list col 0", args{[]int{1, 3, 5}, []int{}, 0}, true, false},
-		{"Hide list col 5", args{[]int{}, []int{1, 3, 5}, 5}, true, false},
-		{"Hide list col 4", args{[]int{}, []int{1, 3, 5}, 4}, false, false},
-		{"Hide list col 3", args{[]int{}, []int{1, 3, 5}, 3}, true, false},
-		{"Hide list col 2", args{[]int{}, []int{1, 3, 5}, 2}, false, false},
-		{"Hide list col 1", args{[]int{}, []int{1, 3, 5}, 1}, true, false},
-		{"Hide list col 0", args{[]int{}, []int{1, 3, 5}, 0}, false, false},
-		{"Both lists error", args{[]int{1, 3, 5}, []int{1, 3, 5}, 4}, false, true},
-		{"No lists all visible", args{[]int{}, []int{}, 3}, true, false},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := checkVisible(tt.args.showNumL, tt.args.hideNumL, tt.args.col)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("checkVisible() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if got != tt.want {
-				t.Errorf("checkVisible() = %v, want %v", got, tt.want)
-			}
-		})
-	}
-}
-
 // ========================================
 // CSV Parsing Tests
 // ========================================
@@ -357,7 +332,7 @@ func Test_checkVisible(t *testing.T) {
 func Test_lineCSVParse(t *testing.T) {
 	type args struct {
 		s   string
-		sep rune
+		sep string
 	}
 	tests := []struct {
 		name    string
@@ -365,12 +340,12 @@ func Test_lineCSVParse(t *testing.T) {
 		want    []string
 		wantErr bool
 	}{
-		{"CSV line", args{"a,b,c,d", ','}, []string{"a", "b", "c", "d"}, false},
-		{"TSV line", args{"a	b	c	d", '	'}, []string{"a", "b", "c", "d"}, false},
+		{"CSV line", args{"a,b,c,d", ","}, []string{"a", "b", "c", "d"}, false},
+		{"TSV line", args{"a	b	c	d", "\t"}, []string{"a", "b", "c", "d"}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := lineCSVParse(tt.args.s, tt.args.sep)
+			got, err := lineCSVParse(tt.args.s, dialect{sep: tt.args.sep, quote: '"'})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("lineCSVParse() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -386,18 +361,18 @@ func TestLineCSVParse(t *testing.T) {
 	tests := []struct {
 		name    string
 		line    string
-		sep     rune
+		sep     string
 		wantLen int
 	}{
-		{"Simple CSV", "a,b,c", ',', 3},
-		{"Tab separated", "a\tb\tc", '\t', 3},
-		{"Empty fields", "a,,c", ',', 3},
-		{"Single field", "single", ',', 1},
+		{"Simple CSV", "a,b,c", ",", 3},
+		{"Tab separated", "a\tb\tc", "\t", 3},
+		{"Empty fields", "a,,c", ",", 3},
+		{"Single field", "single", ",", 1},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := lineCSVParse(tt.line, tt.sep)
+			result, err := lineCSVParse(tt.line, dialect{sep: tt.sep, quote: '"'})
 			if err != nil {
 				t.Fatalf("lineCSVParse() error = %v", err)
 			}
@@ -410,7 +385,7 @@ func TestLineCSVParse(t *testing.T) {
 
 func TestAddDRToBuffer(t *testing.T) {
 	b := createNewBuffer()
-	b.sep = ','
+	b.sep = ","
 
 	tests := []struct {
 		name    string
@@ -424,7 +399,7 @@ func TestAddDRToBuffer(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := addDRToBuffer(b, tt.line, []int{}, []int{})
+			err := addDRToBuffer(b, tt.line)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("addDRToBuffer() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -442,10 +417,10 @@ func TestConcurrentCSVParsing(t *testing.T) {
 	}
 
 	b := createNewBuffer()
-	b.sep = ','
+	b.sep = ","
 
 	for _, line := range lines {
-		err := addDRToBuffer(b, line, []int{}, []int{})
+		err := addDRToBuffer(b, line)
 		if err != nil {
 			t.Fatalf("addDRToBuffer() error = %v", err)
 		}
@@ -475,3 +450,75 @@ func TestIntegration_FullWorkflow(t *testing.T) {
 
 	t.Log("Integration test completed successfully")
 }
+
+// ========================================
+// Multi-line Record Tests
+// ========================================
+
+func TestRecordScanner(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		sep   string
+		want  []string
+	}{
+		{"Simple lines", "a,b\nc,d\n", ",", []string{"a,b", "c,d"}},
+		{"Embedded newline", "a,\"b\nc\",d\ne,f\n", ",", []string{"a,\"b\nc\",d", "e,f"}},
+		{"Escaped quote across lines", "1,\"x \"\"y\"\"\nz\"\n2,w\n", ",", []string{"1,\"x \"\"y\"\"\nz\"", "2,w"}},
+		{"Quote inside unquoted field", "5\" screen,10\nnext,1\n", ",", []string{"5\" screen,10", "next,1"}},
+		{"Unknown separator", "a\t\"b\nc\"\n", "", []string{"a\t\"b\nc\""}},
+		{"Unterminated quote at EOF", "a,\"b\nc\n", ",", []string{"a,\"b\nc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := newRecordScanner(bufio.NewScanner(strings.NewReader(tt.input)), dialect{sep: tt.sep, quote: '"'})
+			var got []string
+			for rs.Scan() {
+				got = append(got, rs.Text())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileToBuffer_MultilineQuoted(t *testing.T) {
+	b := createNewBuffer()
+	if err := loadFileToBuffer("./data/test/multiline_quoted.csv", b); err != nil {
+		t.Fatalf("loadFileToBuffer() error = %v", err)
+	}
+
+	if b.rowLen != 5 {
+		t.Fatalf("Expected 5 rows, got %d", b.rowLen)
+	}
+	if b.colLen != 4 {
+		t.Errorf("Expected 4 columns, got %d", b.colLen)
+	}
+	if want := "Called twice,\nasked for a refund"; b.cont[1][2] != want {
+		t.Errorf("Expected %q, got %q", want, b.cont[1][2])
+	}
+	if want := "Line one\nLine two\n\"Line three\" quoted"; b.cont[3][2] != want {
+		t.Errorf("Expected %q, got %q", want, b.cont[3][2])
+	}
+}
+
+func TestLoadPipeToBufferAsync_MultilineQuoted(t *testing.T) {
+	data := "id,note\n1,\"first\nsecond\"\n2,plain\n"
+
+	b := createNewBuffer()
+	updateChan := make(chan bool, 10)
+	doneChan := make(chan error, 1)
+
+	go loadPipeToBufferAsync(strings.NewReader(data), b, updateChan, doneChan)
+
+	if err := <-doneChan; err != nil {
+		t.Fatalf("loadPipeToBufferAsync() error = %v", err)
+	}
+	if b.rowLen != 3 {
+		t.Fatalf("Expected 3 rows, got %d", b.rowLen)
+	}
+	if b.cont[1][1] != "first\nsecond" {
+		t.Errorf("Expected stitched field, got %q", b.cont[1][1])
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// jsonRawColumn holds lines that are not JSON objects
+const jsonRawColumn = "_raw"
+
+// jsonField is one flattened key/value pair of a JSON record
+type jsonField struct {
+	path  string
+	value string
+}
+
+// isJSONLinesFile checks the file name for a JSON Lines extension
+func isJSONLinesFile(fn string) bool {
+	lower := strings.ToLower(plainFileName(fn))
+	return strings.HasSuffix(lower, ".jsonl") || strings.HasSuffix(lower, ".ndjson")
+}
+
+// looksLikeJSONLines checks whether sample lines are all JSON objects
+func looksLikeJSONLines(lines []string) bool {
+	found := 0
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "{") || !json.Valid([]byte(line)) {
+			return false
+		}
+		found++
+	}
+	return found > 0
+}
+
+// flattenJSONLine parses a JSON object and flattens nested objects into dotted
+// paths, keeping the key order of the record
+func flattenJSONLine(line string) ([]jsonField, error) {
+	data := bytes.TrimSpace([]byte(line))
+	if len(data) == 0 || data[0] != '{' {
+		return nil, errors.New("not a JSON object")
+	}
+	var fields []jsonField
+	if err := flattenJSONObject("", data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// flattenJSONObject appends the flattened members of the object in data to fields
+func flattenJSONObject(prefix string, data []byte, fields *[]jsonField) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil { // opening {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+		raw = bytes.TrimSpace(raw)
+
+		if len(raw) > 0 && raw[0] == '{' {
+			before := len(*fields)
+			if err := flattenJSONObject(path, raw, fields); err != nil {
+				return err
+			}
+			if len(*fields) == before {
+				*fields = append(*fields, jsonField{path: path, value: "{}"})
+			}
+			continue
+		}
+
+		value, err := jsonScalarString(raw)
+		if err != nil {
+			return err
+		}
+		*fields = append(*fields, jsonField{path: path, value: value})
+	}
+	_, err := dec.Token() // closing }
+	return err
+}
+
+// jsonScalarString renders a JSON value as cell text: strings unquoted, null
+// empty and arrays as compact JSON
+func jsonScalarString(raw []byte) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+	switch raw[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	case '[':
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	case 'n':
+		return "", nil
+	default:
+		return string(raw), nil
+	}
+}
+
+// jsonLinesLoader turns JSON Lines records into buffer rows, the columns are
+// the union of keys in order of first appearance
+type jsonLinesLoader struct {
+	b        *Buffer
+	cols     map[string]int   // flattened key -> buffer column (-1 if hidden)
+	keyCount int              // number of distinct keys, used for column selection
+	sel      *columnSelection // --columns and --hide-columns, nil to show all keys
+	header   []string         // header row until the first record is appended
+	width    int              // number of visible columns
+}
+
+// newJSONLinesLoader creates a loader that appends to b
+func newJSONLinesLoader(b *Buffer) (*jsonLinesLoader, error) {
+	sel, err := columnSelectionFromArgs()
+	if err != nil {
+		return nil, err
+	}
+	return &jsonLinesLoader{b: b, cols: make(map[string]int), sel: sel}, nil
+}
+
+// addLine parses one line and appends it as a row, new keys add columns
+func (jl *jsonLinesLoader) addLine(line string) error {
+	fields, err := flattenJSONLine(line)
+	if err != nil {
+		if args.Strict {
+			return errors.New("invalid JSON record: " + err.Error())
+		}
+		fields = []jsonField{{path: jsonRawColumn, value: line}}
+	}
+
+	for _, f := range fields {
+		if _, known := jl.cols[f.path]; known {
+			continue
+		}
+		// Keys appear one by one, so the selection cannot reorder them
+		visible := jl.sel == nil || jl.sel.visible(jl.keyCount, f.path)
+		jl.keyCount++
+		if !visible {
+			jl.cols[f.path] = -1
+			continue
+		}
+		jl.cols[f.path] = jl.width
+		jl.width++
+		if jl.b.rowLen == 0 {
+			jl.header = append(jl.header, f.path)
+		} else {
+			jl.b.appendColumn(f.path, "")
+		}
+	}
+
+	if jl.b.rowLen == 0 {
+		if err := jl.b.contAppendSli(jl.header, false); err != nil {
+			return err
+		}
+		jl.header = nil
+	}
+
+	row := make([]string, jl.width)
+	for _, f := range fields {
+		if col := jl.cols[f.path]; col >= 0 {
+			row[col] = f.value
+		}
+	}
+	return jl.b.contAppendSli(row, args.Strict)
+}
+
+// loadJSONLines reads JSON Lines from scanner into b, onUpdate (optional) is
+// called every updateInterval records so the UI can redraw
+func loadJSONLines(scanner *bufio.Scanner, b *Buffer, progress *progressTracker, onUpdate func()) error {
+	const updateInterval = 500
+	jl, err := newJSONLinesLoader(b)
+	if err != nil {
+		return err
+	}
+	totalAddedLN := 0
+	batchSize := 0
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		bytesRead := int64(len(line) + 1)
+		b.progress.LoadedBytes += bytesRead
+		//skip empty line
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		//ignore first n lines
+		if args.SkipNum > 0 {
+			args.SkipNum--
+			continue
+		}
+		//ignore line with specified prefix
+		if skipLine(line, args.SkipSymbol) {
+			continue
+		}
+		if totalAddedLN >= args.NLine && args.NLine > 0 {
+			break
+		}
+
+		if err := jl.addLine(line); err != nil {
+			return err
+		}
+		totalAddedLN++
+		batchSize++
+		progress.increment(bytesRead)
+
+		if onUpdate != nil && (totalAddedLN == 1 || batchSize >= updateInterval) {
+			onUpdate()
+			batchSize = 0
+		}
+	}
+	return scanner.Err()
+}
+
+// finishJSONLines runs the post-load steps shared with the delimited loaders
+func finishJSONLines(b *Buffer, async bool) {
+	b.progress.IsComplete = true
+	b.detectAllColumnTypes()
+	if async {
+		go b.enableStringInterning()
+		return
+	}
+	b.enableStringInterning()
+}
+
+// load JSON Lines file to buffer (async version for progressive rendering)
+func loadJSONLFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+	fileInfo, err := os.Stat(fn)
+	if err != nil {
+		doneChan <- err
+		return
+	}
+	b.progress.TotalBytes = fileInfo.Size()
+	scanner, closer, err := getFileScanner(fn, &b.progress)
+	if err != nil {
+		doneChan <- err
+		return
+	}
+	defer closer.Close()
+	loadJSONLToBufferAsync(scanner, fileInfo.Size(), b, updateChan, doneChan)
+}
+
+// load JSON Lines file to buffer (synchronous version)
+func loadJSONLFileToBuffer(fn string, b *Buffer) error {
+	fileInfo, err := os.Stat(fn)
+	if err != nil {
+		return err
+	}
+	scanner, closer, err := getFileScanner(fn, &b.progress)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	return loadJSONLToBuffer(scanner, fileInfo.Size(), b)
+}
+
+// load JSON Lines from console pipe to buffer (async version)
+func loadJSONLPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+	b.progress.TotalBytes = 0
+	loadJSONLToBufferAsync(newLineScanner(stdin), 0, b, updateChan, doneChan)
+}
+
+// load JSON Lines from console pipe to buffer (synchronous version)
+func loadJSONLPipeToBuffer(stdin io.Reader, b *Buffer) error {
+	return loadJSONLToBuffer(newLineScanner(stdin), 0, b)
+}
+
+// loadJSONLToBufferAsync streams records into b and signals on updateChan
+func loadJSONLToBufferAsync(scanner *bufio.Scanner, size int64, b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+	b.progress.LoadedBytes = 0
+	b.progress.IsComplete = false
+	progress := newProgressTracker(size, false)
+
+	initialSent := false
+	err := loadJSONLines(scanner, b, progress, func() {
+		if !initialSent {
+			// Signal that initial data is ready for rendering
+			updateChan <- true
+			initialSent = true
+			return
+		}
+		select {
+		case updateChan <- true:
+		default:
+			// Non-blocking - skip update if channel is full
+		}
+	})
+	if err != nil {
+		progress.finish()
+		doneChan <- err
+		return
+	}
+	if !initialSent {
+		updateChan <- true
+	}
+
+	finishJSONLines(b, true)
+	progress.finish()
+	doneChan <- nil
+}
+
+// loadJSONLToBuffer reads all records into b
+func loadJSONLToBuffer(scanner *bufio.Scanner, size int64, b *Buffer) error {
+	progress := newProgressTracker(size, true)
+	progress.load = &b.progress
+	if err := loadJSONLines(scanner, b, progress, nil); err != nil {
+		progress.finish()
+		return err
+	}
+	finishJSONLines(b, false)
+	progress.finish()
+	return nil
+}
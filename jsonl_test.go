@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlattenJSONLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    []jsonField
+		wantErr bool
+	}{
+		{"Flat object", `{"a":1,"b":"x"}`, []jsonField{{"a", "1"}, {"b", "x"}}, false},
+		{"Nested object", `{"user":{"id":7,"geo":{"cc":"de"}}}`, []jsonField{{"user.id", "7"}, {"user.geo.cc", "de"}}, false},
+		{"Array compact", `{"tags": [ "a", 1 , {"k": null} ]}`, []jsonField{{"tags", `["a",1,{"k":null}]`}}, false},
+		{"Null and bool", `{"n":null,"t":true}`, []jsonField{{"n", ""}, {"t", "true"}}, false},
+		{"Empty nested object", `{"meta":{}}`, []jsonField{{"meta", "{}"}}, false},
+		{"Not an object", `[1,2]`, nil, true},
+		{"Broken JSON", `{"a":`, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := flattenJSONLine(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("flattenJSONLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("flattenJSONLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLooksLikeJSONLines(t *testing.T) {
+	if !looksLikeJSONLines([]string{`{"a":1}`, "", `{"b":2}`}) {
+		t.Error("Expected JSON Lines to be detected")
+	}
+	if looksLikeJSONLines([]string{"a,b", "1,2"}) {
+		t.Error("CSV should not be detected as JSON Lines")
+	}
+	if looksLikeJSONLines(nil) {
+		t.Error("Empty sample should not be detected as JSON Lines")
+	}
+}
+
+func TestLoadJSONLFileToBuffer(t *testing.T) {
+	b := createNewBuffer()
+	if err := loadJSONLFileToBuffer("./data/test/events.jsonl", b); err != nil {
+		t.Fatalf("loadJSONLFileToBuffer() error = %v", err)
+	}
+
+	wantHeader := []string{"ts", "level", "user.id", "user.name", "tags", "error.code", "error.retry", "latency", "extra"}
+	if !reflect.DeepEqual(b.cont[0], wantHeader) {
+		t.Fatalf("header = %v, want %v", b.cont[0], wantHeader)
+	}
+	if b.rowLen != 4 {
+		t.Fatalf("Expected 4 rows (including header), got %d", b.rowLen)
+	}
+	for i, row := range b.cont {
+		if len(row) != b.colLen {
+			t.Errorf("Row %d has %d cells, want %d", i, len(row), b.colLen)
+		}
+	}
+	if b.cont[1][5] != "" || b.cont[2][5] != "500" {
+		t.Errorf("Missing keys should be empty cells, got %q and %q", b.cont[1][5], b.cont[2][5])
+	}
+	if b.cont[1][4] != `["a","b"]` {
+		t.Errorf("Expected compact array, got %q", b.cont[1][4])
+	}
+	if b.getColType(7) != colTypeFloat {
+		t.Errorf("Expected latency column to be numeric, got %s", type2name(b.getColType(7)))
+	}
+}
+
+func TestLoadJSONLPipeToBufferAsync(t *testing.T) {
+	data := "{\"a\":1}\nnot json\n{\"a\":2,\"b\":\"x\"}\n"
+
+	b := createNewBuffer()
+	updateChan := make(chan bool, 10)
+	doneChan := make(chan error, 1)
+
+	go loadJSONLPipeToBufferAsync(strings.NewReader(data), b, updateChan, doneChan)
+
+	if err := <-doneChan; err != nil {
+		t.Fatalf("loadJSONLPipeToBufferAsync() error = %v", err)
+	}
+	want := [][]string{{"a", "_raw", "b"}, {"1", "", ""}, {"", "not json", ""}, {"2", "", "x"}}
+	if !reflect.DeepEqual(b.cont, want) {
+		t.Errorf("cont = %v, want %v", b.cont, want)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	format, err := detectFileFormat("./data/test/events.jsonl")
+	if err != nil || format != formatJSONLines {
+		t.Errorf("detectFileFormat(events.jsonl) = %d, %v", format, err)
+	}
+	format, err = detectFileFormat("./data/test/test.csv")
+	if err != nil || format != formatDelimited {
+		t.Errorf("detectFileFormat(test.csv) = %d, %v", format, err)
+	}
+
+	pipe := bufio.NewReader(strings.NewReader("{\"a\":1}\n{\"a\":2}\n"))
+	if got := detectPipeFormat(pipe); got != formatJSONLines {
+		t.Errorf("detectPipeFormat() = %d, want JSON Lines", got)
+	}
+	pipe = bufio.NewReader(strings.NewReader("a,b\n1,2\n"))
+	if got := detectPipeFormat(pipe); got != formatDelimited {
+		t.Errorf("detectPipeFormat() = %d, want delimited", got)
+	}
+}
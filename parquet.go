@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// parquetMagic starts (and ends) every Parquet file
+const parquetMagic = "PAR1"
+
+// rows per record batch when reading Parquet
+const parquetBatchSize = 4096
+
+// isParquetFile checks the file name for a Parquet extension
+func isParquetFile(fn string) bool {
+	lower := strings.ToLower(fn)
+	return strings.HasSuffix(lower, ".parquet") || strings.HasSuffix(lower, ".parq")
+}
+
+// parquetRecords is an open Parquet file read as Arrow record batches
+type parquetRecords struct {
+	file    *file.Reader
+	records pqarrow.RecordReader
+}
+
+// openParquetRecords opens Parquet data for batch reading, row group by row group
+func openParquetRecords(r parquet.ReaderAtSeeker) (*parquetRecords, error) {
+	pf, err := file.NewParquetReader(r)
+	if err != nil {
+		return nil, err
+	}
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{BatchSize: parquetBatchSize}, memory.DefaultAllocator)
+	if err != nil {
+		pf.Close()
+		return nil, err
+	}
+	rr, err := fr.GetRecordReader(context.Background(), nil, nil)
+	if err != nil {
+		pf.Close()
+		return nil, err
+	}
+	return &parquetRecords{file: pf, records: rr}, nil
+}
+
+// close releases the record reader and the file
+func (pr *parquetRecords) close() {
+	pr.records.Release()
+	pr.file.Close()
+}
+
+// openParquetInput opens a Parquet file, or buffers a pipe since Parquet
+// needs random access to read its footer
+func openParquetInput(fn string, stdin io.Reader) (*parquetRecords, error) {
+	if stdin != nil {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, err
+		}
+		return openParquetRecords(bytes.NewReader(data))
+	}
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := openParquetRecords(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return pr, nil
+}
+
+// load Parquet file to buffer (async version for progressive rendering)
+func loadParquetFileToBufferAsync(fn string, b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+	pr, err := openParquetInput(fn, nil)
+	if err != nil {
+		doneChan <- err
+		return
+	}
+	defer pr.close()
+	loadArrowRecordsAsync(pr.records, pr.file.NumRows(), nil, b, updateChan, doneChan)
+}
+
+// load Parquet file to buffer (synchronous version)
+func loadParquetFileToBuffer(fn string, b *Buffer) error {
+	pr, err := openParquetInput(fn, nil)
+	if err != nil {
+		return err
+	}
+	defer pr.close()
+	return loadArrowRecordsSync(pr.records, pr.file.NumRows(), nil, b)
+}
+
+// load Parquet data from console pipe to buffer (async version)
+func loadParquetPipeToBufferAsync(stdin io.Reader, b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+	pr, err := openParquetInput("", stdin)
+	if err != nil {
+		doneChan <- err
+		return
+	}
+	defer pr.close()
+	loadArrowRecordsAsync(pr.records, pr.file.NumRows(), nil, b, updateChan, doneChan)
+}
+
+// load Parquet data from console pipe to buffer (synchronous version)
+func loadParquetPipeToBuffer(stdin io.Reader, b *Buffer) error {
+	pr, err := openParquetInput("", stdin)
+	if err != nil {
+		return err
+	}
+	defer pr.close()
+	return loadArrowRecordsSync(pr.records, pr.file.NumRows(), nil, b)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// writeTestParquet writes a small Parquet file with several row groups
+func writeTestParquet(t *testing.T, rows int) string {
+	t.Helper()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "score", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "created", Type: &arrow.TimestampType{Unit: arrow.Second}},
+		{Name: "day", Type: arrow.FixedWidthTypes.Date32},
+	}, nil)
+
+	bld := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer bld.Release()
+	base := time.Date(2024, 10, 17, 8, 30, 0, 0, time.UTC)
+	for i := 0; i < rows; i++ {
+		bld.Field(0).(*array.Int64Builder).Append(int64(i))
+		if i%3 == 0 {
+			bld.Field(1).(*array.StringBuilder).AppendNull()
+		} else {
+			bld.Field(1).(*array.StringBuilder).Append("name" + I2S(i))
+		}
+		bld.Field(2).(*array.Float64Builder).Append(float64(i) + 0.5)
+		bld.Field(3).(*array.TimestampBuilder).Append(arrow.Timestamp(base.Add(time.Duration(i) * time.Hour).Unix()))
+		bld.Field(4).(*array.Date32Builder).Append(arrow.Date32FromTime(base))
+	}
+	rec := bld.NewRecordBatch()
+	defer rec.Release()
+	tbl := array.NewTableFromRecords(schema, []arrow.RecordBatch{rec})
+	defer tbl.Release()
+
+	var buf bytes.Buffer
+	props := parquet.NewWriterProperties(parquet.WithMaxRowGroupLength(10))
+	if err := pqarrow.WriteTable(tbl, &buf, 10, props, pqarrow.DefaultWriterProps()); err != nil {
+		t.Fatalf("WriteTable() error = %v", err)
+	}
+	fn := filepath.Join(t.TempDir(), "sample.parquet")
+	if err := os.WriteFile(fn, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return fn
+}
+
+func TestLoadParquetFileToBuffer(t *testing.T) {
+	fn := writeTestParquet(t, 25)
+
+	b := createNewBuffer()
+	if err := loadParquetFileToBuffer(fn, b); err != nil {
+		t.Fatalf("loadParquetFileToBuffer() error = %v", err)
+	}
+
+	if b.rowLen != 26 || b.colLen != 5 {
+		t.Fatalf("Expected 26x5 buffer, got %dx%d", b.rowLen, b.colLen)
+	}
+	wantHeader := []string{"id", "name", "score", "created", "day"}
+	for i, h := range wantHeader {
+		if b.cont[0][i] != h {
+			t.Errorf("header[%d] = %q, want %q", i, b.cont[0][i], h)
+		}
+	}
+	wantTypes := []int{colTypeFloat, colTypeStr, colTypeFloat, colTypeDate, colTypeDate}
+	for i, want := range wantTypes {
+		if b.getColType(i) != want {
+			t.Errorf("column %d type = %s, want %s", i, type2name(b.getColType(i)), type2name(want))
+		}
+	}
+	if b.cont[1][1] != "" || b.cont[2][1] != "name1" {
+		t.Errorf("Unexpected name cells %q, %q", b.cont[1][1], b.cont[2][1])
+	}
+	if b.cont[2][2] != "1.5" {
+		t.Errorf("score = %q, want 1.5", b.cont[2][2])
+	}
+	if b.cont[2][3] != "2024-10-17 09:30:00" || b.cont[2][4] != "2024-10-17" {
+		t.Errorf("Unexpected date cells %q, %q", b.cont[2][3], b.cont[2][4])
+	}
+	if parseDateValueFast(b.cont[2][3]) == 0 {
+		t.Errorf("Timestamp cell %q should be sortable as date", b.cont[2][3])
+	}
+}
+
+func TestLoadParquetFileToBufferAsync(t *testing.T) {
+	fn := writeTestParquet(t, 40)
+
+	b := createNewBuffer()
+	updateChan := make(chan bool, 10)
+	doneChan := make(chan error, 1)
+	go loadParquetFileToBufferAsync(fn, b, updateChan, doneChan)
+
+	if err := <-doneChan; err != nil {
+		t.Fatalf("loadParquetFileToBufferAsync() error = %v", err)
+	}
+	if len(updateChan) == 0 {
+		t.Error("Expected at least one update signal")
+	}
+	if b.rowLen != 41 {
+		t.Errorf("Expected 41 rows, got %d", b.rowLen)
+	}
+	if !b.progress.IsComplete || b.progress.GetPercentage() != 100 {
+		t.Errorf("Expected complete progress, got %.1f%%", b.progress.GetPercentage())
+	}
+}
+
+func TestLoadParquetPipeToBuffer(t *testing.T) {
+	fn := writeTestParquet(t, 5)
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := createNewBuffer()
+	if err := loadParquetPipeToBuffer(bytes.NewReader(data), b); err != nil {
+		t.Fatalf("loadParquetPipeToBuffer() error = %v", err)
+	}
+	if b.rowLen != 6 {
+		t.Errorf("Expected 6 rows, got %d", b.rowLen)
+	}
+
+	format, err := detectFileFormat(fn)
+	if err != nil || format != formatParquet {
+		t.Errorf("detectFileFormat() = %d, %v, want Parquet", format, err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"regexp"
+)
+
+// built-in --pattern presets for common log formats
+var patternPresets = map[string]string{
+	// Apache/nginx Common Log Format
+	"common": `^(?P<host>\S+) (?P<ident>\S+) (?P<user>\S+) \[(?P<time>[^\]]+)\] "(?:(?P<method>[A-Z]+) (?P<path>[^ "]*)(?: (?P<protocol>[^"]*))?|[^"]*)" (?P<status>\d{3}) (?P<bytes>\d+|-)`,
+	// Common Log Format followed by referer and user agent (nginx default)
+	"combined": `^(?P<host>\S+) (?P<ident>\S+) (?P<user>\S+) \[(?P<time>[^\]]+)\] "(?:(?P<method>[A-Z]+) (?P<path>[^ "]*)(?: (?P<protocol>[^"]*))?|[^"]*)" (?P<status>\d{3}) (?P<bytes>\d+|-) "(?P<referer>[^"]*)" "(?P<user_agent>[^"]*)"`,
+	// BSD syslog (RFC 3164) as written to /var/log/syslog or /var/log/messages
+	"syslog": `^(?P<time>[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (?P<host>\S+) (?P<program>[^\s\[:]+)(?:\[(?P<pid>\d+)\])?: (?P<message>.*)$`,
+}
+
+// values of --unmatched
+const (
+	unmatchedSkip = "skip"
+	unmatchedRaw  = "raw"
+)
+
+// name of the column that holds lines not matching the pattern
+const rawColumnName = "raw"
+
+// linePattern turns log lines into rows with a regular expression, the named
+// groups become the columns
+type linePattern struct {
+	re     *regexp.Regexp
+	groups []int // submatch indexes of the named groups
+	names  []string
+	raw    bool // keep unmatched lines in a raw column instead of skipping them
+}
+
+// newLinePattern compiles a --pattern, either a preset name or a regular
+// expression with named groups like (?P<status>\d+)
+func newLinePattern(expr string, unmatched string) (*linePattern, error) {
+	if preset, ok := patternPresets[expr]; ok {
+		expr = preset
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	if unmatched != unmatchedSkip && unmatched != unmatchedRaw {
+		return nil, errors.New("--unmatched must be " + unmatchedSkip + " or " + unmatchedRaw)
+	}
+
+	lp := &linePattern{re: re, raw: unmatched == unmatchedRaw}
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			lp.groups = append(lp.groups, i)
+			lp.names = append(lp.names, name)
+		}
+	}
+	if len(lp.groups) == 0 {
+		return nil, errors.New("the pattern needs named groups like (?P<name>...) to make columns")
+	}
+	return lp, nil
+}
+
+// header returns the column names
+func (lp *linePattern) header() []string {
+	header := append([]string{}, lp.names...)
+	if lp.raw {
+		header = append(header, rawColumnName)
+	}
+	return header
+}
+
+// split returns the row for line, or nil when it does not match and
+// unmatched lines are skipped
+func (lp *linePattern) split(line string) []string {
+	m := lp.re.FindStringSubmatch(line)
+	if m == nil && !lp.raw {
+		return nil
+	}
+	width := len(lp.names)
+	if lp.raw {
+		width++
+	}
+	row := make([]string, width)
+	if m == nil {
+		row[len(row)-1] = line
+		return row
+	}
+	for i, g := range lp.groups {
+		row[i] = m[g]
+	}
+	return row
+}
+
+// usePattern makes b parse lines with lp, the header row comes from the group
+// names since log files have none
+func usePattern(b *Buffer, lp *linePattern) error {
+	b.pattern = lp
+	// Any separator will do, it only stops the loaders from detecting one
+	b.sep = " "
+	return appendVisible(b, lp.header())
+}
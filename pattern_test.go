@@ -0,0 +1,154 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLinePatternSplit(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		unmatched string
+		line      string
+		want      []string
+	}{
+		{"named groups", `(?P<level>\w+): (?P<msg>.*)`, unmatchedSkip, "ERROR: disk full", []string{"ERROR", "disk full"}},
+		{"unnamed groups are not columns", `(\d+)-(?P<id>\d+)`, unmatchedSkip, "12-34", []string{"34"}},
+		{"optional group", `(?P<a>a)(?P<b>b)?`, unmatchedSkip, "a", []string{"a", ""}},
+		{"skipped", `(?P<n>\d+)`, unmatchedSkip, "none", nil},
+		{"raw column", `(?P<n>\d+)`, unmatchedRaw, "none", []string{"", "none"}},
+		{"matched in raw mode", `(?P<n>\d+)`, unmatchedRaw, "42", []string{"42", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lp, err := newLinePattern(tt.expr, tt.unmatched)
+			if err != nil {
+				t.Fatalf("newLinePattern() error = %v", err)
+			}
+			if got := lp.split(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("split(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLinePatternErrors(t *testing.T) {
+	tests := []struct {
+		expr, unmatched string
+	}{
+		{`(\d+)`, unmatchedSkip},          // no named groups
+		{`(?P<n>\d+`, unmatchedSkip},      // invalid expression
+		{`(?P<n>\d+)`, "keep-everything"}, // unknown --unmatched
+	}
+	for _, tt := range tests {
+		if _, err := newLinePattern(tt.expr, tt.unmatched); err == nil {
+			t.Errorf("newLinePattern(%q, %q) should fail", tt.expr, tt.unmatched)
+		}
+	}
+}
+
+func TestLoadFileWithPattern(t *testing.T) {
+	defer args.setDefault()
+	tests := []struct {
+		fn        string
+		preset    string
+		unmatched string
+		rows      int
+		check     func(b *Buffer) string
+	}{
+		{
+			"./data/test/access.log", "combined", unmatchedSkip, 5,
+			func(b *Buffer) string {
+				if got := b.cont[2][:9]; !reflect.DeepEqual(got, []string{"192.168.1.11", "-", "alice", "10/Oct/2024:13:55:40 +0000", "POST", "/api/login", "HTTP/1.1", "302", "0"}) {
+					return "row 2 = " + strings.Join(got, "|")
+				}
+				if b.cont[4][b.colLen-1] != "-" || b.cont[4][4] != "" {
+					return "a bare \"-\" request should match with empty method"
+				}
+				if b.getColType(7) != colTypeFloat {
+					return "status should be a number column"
+				}
+				return ""
+			},
+		},
+		{
+			"./data/test/access.log", "common", unmatchedRaw, 6,
+			func(b *Buffer) string {
+				if b.cont[0][b.colLen-1] != rawColumnName || b.cont[4][b.colLen-1] != "this line was written by a misconfigured module" {
+					return "unmatched line should be kept in the raw column"
+				}
+				return ""
+			},
+		},
+		{
+			"./data/test/syslog.txt", "syslog", unmatchedSkip, 5,
+			func(b *Buffer) string {
+				if got := b.cont[1]; !reflect.DeepEqual(got, []string{"Oct 10 13:55:36", "web01", "sshd", "1024", "Accepted publickey for deploy from 10.0.0.5 port 52144 ssh2"}) {
+					return "row 1 = " + strings.Join(got, "|")
+				}
+				if b.cont[3][2] != "kernel" || b.cont[3][3] != "" {
+					return "row without pid = " + strings.Join(b.cont[3], "|")
+				}
+				return ""
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.preset+"/"+tt.unmatched, func(t *testing.T) {
+			args.Pattern = tt.preset
+			format, err := detectFileFormat(tt.fn)
+			if err != nil || format != formatDelimited {
+				t.Fatalf("detectFileFormat() = %d, %v", format, err)
+			}
+			lp, err := newLinePattern(tt.preset, tt.unmatched)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, async := range []bool{false, true} {
+				b := createNewBuffer()
+				if err := usePattern(b, lp); err != nil {
+					t.Fatal(err)
+				}
+				if async {
+					updateChan := make(chan bool, 10)
+					doneChan := make(chan error, 1)
+					go loadFileToBufferAsync(tt.fn, b, updateChan, doneChan)
+					err = <-doneChan
+				} else {
+					err = loadFileToBuffer(tt.fn, b)
+				}
+				if err != nil {
+					t.Fatalf("load error = %v", err)
+				}
+				if b.rowLen != tt.rows {
+					t.Fatalf("async=%v: got %d rows, want %d: %q", async, b.rowLen, tt.rows, b.cont)
+				}
+				if msg := tt.check(b); msg != "" {
+					t.Errorf("async=%v: %s", async, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestPipeWithPatternAndColumns(t *testing.T) {
+	defer args.setDefault()
+	args.Columns = []string{"1", "3"}
+	lp, err := newLinePattern(`(?P<level>\w+) (?P<code>\d+) (?P<msg>.*)`, unmatchedSkip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := createNewBuffer()
+	if err := usePattern(b, lp); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadPipeToBuffer(strings.NewReader("INFO 200 started\n\nWARN 301 \"moved\" away\n"), b); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"level", "msg"}, {"INFO", "started"}, {"WARN", `"moved" away`}}
+	if !reflect.DeepEqual(b.cont, want) {
+		t.Errorf("buffer = %q, want %q", b.cont, want)
+	}
+}
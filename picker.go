@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// sourceSet is an input that holds several tables, like the sheets of a
+// workbook. One of them is displayed at a time.
+type sourceSet struct {
+	kind    string // what a source is called in the UI, e.g. "Sheet"
+	names   []string
+	current string
+	load    func(name string, b *Buffer) error
+}
+
+// loadInto loads the named source into b and makes it the current one
+func (ss *sourceSet) loadInto(name string, b *Buffer) error {
+	if err := ss.load(name, b); err != nil {
+		return err
+	}
+	ss.current = name
+	return nil
+}
+
+// hasSources reports whether files of the format hold several tables, which
+// are opened through the source picker
+func hasSources(format int) bool {
+	return format == formatXLSX || format == formatArchive || format == formatSQLite
+}
+
+// openSourceSet opens a workbook, archive or database file for the source picker
+func openSourceSet(fn string, format int) (*sourceSet, error) {
+	if format == formatSQLite {
+		sd, err := openSQLite(fn)
+		if err != nil {
+			return nil, err
+		}
+		return newSQLiteSourceSet(sd)
+	}
+	if format == formatArchive {
+		a, err := openArchive(fn)
+		if err != nil {
+			return nil, err
+		}
+		return newArchiveSourceSet(a), nil
+	}
+	wb, err := openXLSXFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	return newXLSXSourceSet(wb), nil
+}
+
+// switchSource replaces the displayed buffer with another source of the set
+func switchSource(name string) {
+	nb := createNewBuffer()
+	// Archive members are parsed like files given on the command line
+	if err := configureBuffer(nb); err != nil {
+		updateFooterWithStatus(err.Error())
+		return
+	}
+	updateFooterWithStatus(fmt.Sprintf("Loading %s %s...", subSources.kind, name))
+	app.ForceDraw()
+
+	if err := subSources.loadInto(name, nb); err != nil {
+		updateFooterWithStatus(fmt.Sprintf("Cannot load %s %s: %v", subSources.kind, name, err))
+		return
+	}
+	setupFreezeMode(nb)
+	showBuffer(nb)
+	updateFooterWithStatus(fmt.Sprintf("%s %s: %d rows", subSources.kind, name, nb.rowLen))
+}
+
+// showSourcePicker lists the sources of the current input in a modal, Enter
+// loads the selected one
+func showSourcePicker() {
+	if subSources == nil || len(subSources.names) == 0 {
+		updateFooterWithStatus("Nothing to switch: input has a single table")
+		return
+	}
+
+	list := tview.NewList().ShowSecondaryText(false)
+	closePicker := func() {
+		UI.RemovePage("sourcePicker")
+		app.SetFocus(bufferTable)
+	}
+	for i, name := range subSources.names {
+		label := name
+		if name == subSources.current {
+			label = name + "  (current)"
+			list.SetCurrentItem(i)
+		}
+		name := name
+		list.AddItem(label, "", 0, func() {
+			closePicker()
+			if name != subSources.current {
+				switchSource(name)
+			}
+		})
+	}
+	if subSources.current == "" {
+		list.SetCurrentItem(0)
+	}
+
+	list.SetBorder(true)
+	list.SetTitle(fmt.Sprintf(" 📑 Select %s - Enter to open, Esc to cancel ", subSources.kind))
+	list.SetTitleAlign(tview.AlignCenter)
+	list.SetBorderColor(tcell.NewRGBColor(0, 200, 255)) // Bright Blue
+	list.SetBackgroundColor(tcell.NewRGBColor(20, 30, 40))
+	list.SetMainTextColor(tcell.NewRGBColor(180, 220, 220))
+	list.SetSelectedBackgroundColor(tcell.NewRGBColor(80, 120, 160))
+	list.SetSelectedTextColor(tcell.ColorWhite)
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || (event.Key() == tcell.KeyRune && event.Rune() == 'q') {
+			closePicker()
+			return nil
+		}
+		// j/k navigation
+		if event.Key() == tcell.KeyRune && event.Rune() == 'j' {
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		}
+		if event.Key() == tcell.KeyRune && event.Rune() == 'k' {
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		}
+		return event
+	})
+
+	// Create centered modal overlay, tall enough for the list plus borders
+	height := len(subSources.names) + 2
+	if height > 20 {
+		height = 20
+	}
+	pickerModal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(list, height, 1, true).
+			AddItem(nil, 0, 1, false), 60, 1, true).
+		AddItem(nil, 0, 1, false)
+
+	UI.AddPage("sourcePicker", pickerModal, true, true)
+	app.SetFocus(list)
+}
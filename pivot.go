@@ -0,0 +1,252 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+const (
+	// default cap on the columns a pivot generates
+	pivotMaxColumns = 50
+	// labels of the total row and column
+	pivotTotal = "Total"
+	// column of the values past the cap
+	pivotOther = "(other)"
+	// label of an empty value
+	pivotEmpty = "(empty)"
+)
+
+// pivotKey is the column bucket of a value, the value itself or the merged
+// values past the cap
+type pivotKey struct {
+	value string
+	other bool
+}
+
+// pivotLabel shows a value as a row or column label, quoting values that
+// read like the labels pivotBuffer adds
+func pivotLabel(value string) string {
+	switch value {
+	case "":
+		return pivotEmpty
+	case pivotTotal, pivotOther, pivotEmpty:
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// pivotBuffer builds a cross-tab of the data rows of nb: a row for each value
+// of rowCol, a column for each value of colCol and agg over the rows of both
+// in the cells, with totals of both. Past maxCols columns the rarest values
+// share an (other) column. It also returns the number of values merged.
+func pivotBuffer(nb *Buffer, rowCol, colCol int, agg groupAgg, maxCols int) (*Buffer, int, error) {
+	if maxCols < 2 {
+		return nil, 0, errors.New("at least 2 columns are needed, one holds the others")
+	}
+	// The buckets of an indexed file would hold all of its rows in memory
+	if nb.index != nil {
+		return nil, 0, errors.New(indexedUnsupportedMsg)
+	}
+	nb.mu.RLock()
+	names := sqlColumnNames(nb)
+	var all [][]string
+	var colOrder []string
+	colCount := make(map[string]int)
+	for r := nb.rowFreeze; r < nb.dataRowCount(); r++ {
+		row := nb.rowAt(r)
+		all = append(all, row)
+		ck := ""
+		if colCol < len(row) {
+			ck = row[colCol]
+		}
+		if colCount[ck] == 0 {
+			colOrder = append(colOrder, ck)
+		}
+		colCount[ck]++
+	}
+	nb.mu.RUnlock()
+
+	// The most frequent values get columns, the others are merged
+	kept := colOrder
+	merged := 0
+	if len(colOrder) > maxCols {
+		byCount := append([]string(nil), colOrder...)
+		sort.SliceStable(byCount, func(i, j int) bool { return colCount[byCount[i]] > colCount[byCount[j]] })
+		kept = byCount[:maxCols-1]
+		merged = len(colOrder) - len(kept)
+	}
+	kept = append([]string(nil), kept...)
+	less := valueLess(nb.getColType(colCol))
+	sort.SliceStable(kept, func(i, j int) bool { return less(kept[i], kept[j]) })
+
+	// Buckets are keyed by the raw value, so a value that reads like one of
+	// the labels is not mixed up with the merged or total cells
+	keys := make(map[string]pivotKey, len(colOrder))
+	for _, ck := range colOrder {
+		keys[ck] = pivotKey{other: true}
+	}
+	header := []string{names[rowCol]}
+	var colKeys []pivotKey
+	for _, ck := range kept {
+		keys[ck] = pivotKey{value: ck}
+		colKeys = append(colKeys, keys[ck])
+		header = append(header, pivotLabel(ck))
+	}
+	if merged > 0 {
+		colKeys = append(colKeys, pivotKey{other: true})
+		header = append(header, pivotOther)
+	}
+	header = append(header, pivotTotal)
+
+	var rowOrder []string
+	cells := make(map[string]map[pivotKey][][]string)
+	byRow := make(map[string][][]string)
+	byCol := make(map[pivotKey][][]string)
+	for _, row := range all {
+		rk, ck := "", ""
+		if rowCol < len(row) {
+			rk = row[rowCol]
+		}
+		if colCol < len(row) {
+			ck = row[colCol]
+		}
+		key := keys[ck]
+		if _, ok := byRow[rk]; !ok {
+			rowOrder = append(rowOrder, rk)
+			cells[rk] = make(map[pivotKey][][]string)
+		}
+		byRow[rk] = append(byRow[rk], row)
+		byCol[key] = append(byCol[key], row)
+		cells[rk][key] = append(cells[rk][key], row)
+	}
+	less = valueLess(nb.getColType(rowCol))
+	sort.SliceStable(rowOrder, func(i, j int) bool { return less(rowOrder[i], rowOrder[j]) })
+
+	result := createNewBuffer()
+	result.colFreeze = nb.colFreeze
+	if err := result.contAppendSli(header, false); err != nil {
+		return nil, 0, err
+	}
+	for _, rk := range rowOrder {
+		row := []string{pivotLabel(rk)}
+		for _, key := range colKeys {
+			row = append(row, aggregate(nb, agg, cells[rk][key]))
+		}
+		row = append(row, aggregate(nb, agg, byRow[rk]))
+		if err := result.contAppendSli(row, false); err != nil {
+			return nil, 0, err
+		}
+	}
+	totals := []string{pivotTotal}
+	for _, key := range colKeys {
+		totals = append(totals, aggregate(nb, agg, byCol[key]))
+	}
+	totals = append(totals, aggregate(nb, agg, all))
+	if err := result.contAppendSli(totals, false); err != nil {
+		return nil, 0, err
+	}
+	// The totals stay last when the pivot is sorted and stay out of stats
+	result.pinnedRows = 1
+
+	result.setColType(0, nb.getColType(rowCol))
+	for c := 1; c < result.colLen; c++ {
+		result.setColType(c, result.autoDetectColumnType(c))
+	}
+	return result, merged, nil
+}
+
+// showPivotForm asks for the row, column and value of a pivot of the
+// displayed rows and opens it in a new tab
+func showPivotForm() {
+	form := tview.NewForm()
+	closeForm := func() { closeModalForm("pivotModal") }
+
+	b.mu.RLock()
+	names := sqlColumnNames(b)
+	b.mu.RUnlock()
+	_, col := bufferTable.GetSelection()
+	rowText := ""
+	if col < len(names) {
+		rowText = names[col]
+	}
+	hint := "[gray]Columns by name or number, the value is one aggregate:\n" +
+		"count, sum(x), mean(x), median(x), min(x), max(x), distinct(x)\n" +
+		"Columns: " + tview.Escape(strings.Join(names, ", ")) + "[-]"
+	form.AddInputField("Rows:", rowText, 40, nil, nil)
+	form.AddInputField("Columns:", "", 40, nil, nil)
+	form.AddInputField("Value:", "count", 40, nil, nil)
+	form.AddInputField("Max columns:", strconv.Itoa(pivotMaxColumns), 6, tview.InputFieldInteger, nil)
+	form.AddTextView("", hint, 80, 4, true, false)
+	message := form.GetFormItem(4).(*tview.TextView)
+	showError := func(msg string) {
+		message.SetText("[red]" + tview.Escape(msg) + "[-]")
+	}
+	column := func(i int, what string) (int, error) {
+		text := strings.TrimSpace(form.GetFormItem(i).(*tview.InputField).GetText())
+		if text == "" {
+			return 0, errors.New("Name a column for the " + what)
+		}
+		cols, err := resolveColumns([]string{text}, names)
+		if err != nil {
+			return 0, err
+		}
+		if len(cols) != 1 {
+			return 0, errors.New(strconv.Quote(text) + " matches several columns, the " + what + " come from one")
+		}
+		return cols[0], nil
+	}
+
+	run := func() {
+		rowCol, err := column(0, "rows")
+		if err != nil {
+			showError(err.Error())
+			return
+		}
+		colCol, err := column(1, "columns")
+		if err != nil {
+			showError(err.Error())
+			return
+		}
+		if rowCol == colCol {
+			showError("Rows and columns need different columns")
+			return
+		}
+		aggs, err := parseGroupAggs(form.GetFormItem(2).(*tview.InputField).GetText(), b, names)
+		if err != nil {
+			showError(err.Error())
+			return
+		}
+		if len(aggs) != 1 {
+			showError("The value is a single aggregate, like sum(" + names[colCol] + ")")
+			return
+		}
+		maxCols, _ := strconv.Atoi(form.GetFormItem(3).(*tview.InputField).GetText())
+		message.SetText("[yellow]Building...[-]")
+		app.ForceDraw()
+		result, merged, err := pivotBuffer(b, rowCol, colCol, aggs[0], maxCols)
+		if err != nil {
+			showError(err.Error())
+			return
+		}
+		if result.rowLen <= result.rowFreeze+1 {
+			showError("There are no rows to pivot")
+			return
+		}
+		closeForm()
+
+		pivotViewCount++
+		openTab("pivot-"+strconv.Itoa(pivotViewCount), result)
+		statusMessage = fmt.Sprintf("Pivot of %s by %s and %s", aggs[0].name, names[rowCol], names[colCol])
+		if merged > 0 {
+			statusMessage += fmt.Sprintf(", %d rarer values in %s", merged, pivotOther)
+		}
+		updateFooterWithStatus(statusMessage)
+	}
+
+	showModalForm("pivotModal", form, " ⊞ Pivot Table - Enter to build, Esc to cancel ", "Pivot", run, 100, 17)
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPivotBuffer(t *testing.T) {
+	nb := newTestBuffer(t, [][]string{
+		{"region", "status", "latency", "month"},
+		{"eu", "ok", "10", "2"},
+		{"us", "ok", "20", "10"},
+		{"eu", "failed", "30", "1"},
+		{"us", "", "5", "2"},
+		{"ap", "failed", "7", "10"},
+		{"ap", "slow", "1", "1"},
+		{"eu", "ok", "2", "1"},
+	}, colTypeStr, colTypeStr, colTypeFloat, colTypeFloat)
+	names := sqlColumnNames(nb)
+
+	tests := []struct {
+		name         string
+		rowCol       int
+		colCol       int
+		value        string
+		maxCols      int
+		want         [][]string
+		types        []int
+		wantMerged   int
+		wantErrorMsg string
+	}{
+		{
+			"count with an empty value", 0, 1, "count", pivotMaxColumns,
+			[][]string{
+				{"region", pivotEmpty, "failed", "ok", "slow", pivotTotal},
+				{"ap", "0", "1", "0", "1", "2"},
+				{"eu", "0", "1", "2", "0", "3"},
+				{"us", "1", "0", "1", "0", "2"},
+				{pivotTotal, "1", "2", "3", "1", "7"},
+			},
+			[]int{colTypeStr, colTypeFloat, colTypeFloat, colTypeFloat, colTypeFloat, colTypeFloat},
+			0, "",
+		},
+		{
+			"numeric columns in order", 0, 3, "sum(latency)", 3,
+			[][]string{
+				{"region", "1", "2", "10", pivotTotal},
+				{"ap", "1", "", "7", "8"},
+				{"eu", "32", "10", "", "42"},
+				{"us", "", "5", "20", "25"},
+				{pivotTotal, "33", "15", "27", "75"},
+			},
+			[]int{colTypeStr, colTypeFloat, colTypeFloat, colTypeFloat, colTypeFloat},
+			0, "",
+		},
+		{
+			"the rarest values merged past the cap", 0, 3, "sum(latency)", 2,
+			[][]string{
+				{"region", "1", pivotOther, pivotTotal},
+				{"ap", "1", "7", "8"},
+				{"eu", "32", "10", "42"},
+				{"us", "", "25", "25"},
+				{pivotTotal, "33", "42", "75"},
+			},
+			[]int{colTypeStr, colTypeFloat, colTypeFloat, colTypeFloat},
+			2, "",
+		},
+		{
+			"mean totals are computed from the rows", 1, 0, "mean(latency)", pivotMaxColumns,
+			[][]string{
+				{"status", "ap", "eu", "us", pivotTotal},
+				{pivotEmpty, "", "", "5", "5"},
+				{"failed", "7", "30", "", "18.5"},
+				{"ok", "", "6", "20", "10.6667"},
+				{"slow", "1", "", "", "1"},
+				{pivotTotal, "4", "14", "12.5", "10.7143"},
+			},
+			[]int{colTypeStr, colTypeFloat, colTypeFloat, colTypeFloat, colTypeFloat},
+			0, "",
+		},
+		{"cap too small", 0, 1, "count", 1, nil, nil, 0, "at least 2 columns are needed, one holds the others"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggs, err := parseGroupAggs(tt.value, nb, names)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, merged, err := pivotBuffer(nb, tt.rowCol, tt.colCol, aggs[0], tt.maxCols)
+			if tt.wantErrorMsg != "" {
+				if err == nil || err.Error() != tt.wantErrorMsg {
+					t.Errorf("pivotBuffer() error = %v, want %q", err, tt.wantErrorMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.cont, tt.want) {
+				t.Errorf("rows = %q, want %q", got.cont, tt.want)
+			}
+			if !reflect.DeepEqual(got.colType[:got.colLen], tt.types) {
+				t.Errorf("types = %v, want %v", got.colType[:got.colLen], tt.types)
+			}
+			if merged != tt.wantMerged {
+				t.Errorf("merged = %d, want %d", merged, tt.wantMerged)
+			}
+		})
+	}
+}
+
+func TestPivotBufferIndexed(t *testing.T) {
+	nb := newTestBuffer(t, [][]string{{"region", "status"}, {"eu", "ok"}}, colTypeStr, colTypeStr)
+	aggs, err := parseGroupAggs("count", nb, sqlColumnNames(nb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb.index = &lineIndex{}
+	if _, _, err := pivotBuffer(nb, 0, 1, aggs[0], pivotMaxColumns); err == nil || err.Error() != indexedUnsupportedMsg {
+		t.Errorf("pivotBuffer() in indexed mode error = %v, want %q", err, indexedUnsupportedMsg)
+	}
+}
+
+func TestPivotTotalsStayPinned(t *testing.T) {
+	nb := newTestBuffer(t, [][]string{
+		{"region", "status"},
+		{"eu", "ok"},
+		{"us", "ok"},
+		{"eu", "failed"},
+		{"ap", "ok"},
+		{"eu", "ok"},
+	}, colTypeStr, colTypeStr)
+	aggs, err := parseGroupAggs("count", nb, sqlColumnNames(nb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := pivotBuffer(nb, 0, 1, aggs[0], pivotMaxColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got.sortByNum(3, true)
+	want := [][]string{
+		{"region", "failed", "ok", pivotTotal},
+		{"eu", "1", "2", "3"},
+		{"ap", "0", "1", "1"},
+		{"us", "0", "1", "1"},
+		{pivotTotal, "1", "4", "5"},
+	}
+	if !reflect.DeepEqual(got.cont, want) {
+		t.Errorf("sorted descending = %q, want %q", got.cont, want)
+	}
+	got.sortByStr(0, true)
+	if last := got.cont[got.rowLen-1][0]; last != pivotTotal {
+		t.Errorf("last row after a text sort = %q, want the totals", last)
+	}
+
+	filtered := got.filterByColumn(0, FilterOptions{Query: "", Operator: "contains"})
+	if n := filtered.rowLen - filtered.rowFreeze; n != 3 {
+		t.Errorf("filter kept %d rows, want the 3 without the totals", n)
+	}
+}
+
+func TestPivotValuesLikeLabels(t *testing.T) {
+	nb := newTestBuffer(t, [][]string{
+		{"region", "status"},
+		{"eu", pivotOther},
+		{"eu", pivotOther},
+		{"eu", pivotTotal},
+		{pivotTotal, pivotTotal},
+		{"us", "a"},
+		{pivotEmpty, ""},
+	}, colTypeStr, colTypeStr)
+	aggs, err := parseGroupAggs("count", nb, sqlColumnNames(nb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, merged, err := pivotBuffer(nb, 0, 1, aggs[0], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"region", `"(other)"`, `"Total"`, pivotOther, pivotTotal},
+		{`"(empty)"`, "0", "0", "1", "1"},
+		{`"Total"`, "0", "1", "0", "1"},
+		{"eu", "2", "1", "0", "3"},
+		{"us", "0", "0", "1", "1"},
+		{pivotTotal, "2", "2", "2", "6"},
+	}
+	if !reflect.DeepEqual(got.cont, want) {
+		t.Errorf("rows = %q, want %q", got.cont, want)
+	}
+	if merged != 2 {
+		t.Errorf("merged = %d, want 2", merged)
+	}
+}
+
+func TestPivotResultAsSource(t *testing.T) {
+	nb := newTestBuffer(t, [][]string{
+		{"region", "status"},
+		{"eu", "ok"},
+		{"us", "ok"},
+		{"eu", "failed"},
+		{"eu", "ok"},
+	}, colTypeStr, colTypeStr)
+	count, err := parseGroupAggs("count", nb, sqlColumnNames(nb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pv, _, err := pivotBuffer(nb, 0, 1, count[0], pivotMaxColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The totals row is not a group of its own and not added to the sums
+	aggs, err := parseGroupAggs("count, sum(ok)", pv, sqlColumnNames(pv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	grouped, err := groupBuffer(pv, []int{1}, aggs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"failed", "count", "sum(ok)"}, {"1", "1", "2"}, {"0", "1", "1"}}; !reflect.DeepEqual(grouped.cont, want) {
+		t.Errorf("grouped = %q, want %q", grouped.cont, want)
+	}
+
+	again, _, err := pivotBuffer(pv, 0, 2, count[0], pivotMaxColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{pivotTotal, "1", "1", "2"}; !reflect.DeepEqual(again.cont[again.rowLen-1], want) {
+		t.Errorf("totals of the pivot of a pivot = %q, want %q", again.cont[again.rowLen-1], want)
+	}
+
+	result, err := queryBuffer(pv, "SELECT count(*), sum(ok) FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2", "3"}; !reflect.DeepEqual(result.cont[1], want) {
+		t.Errorf("query = %q, want %q", result.cont[1], want)
+	}
+}
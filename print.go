@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// border styles of --print
+const (
+	borderBox   = "box"
+	borderASCII = "ascii"
+)
+
+// columns narrower than this are not shrunk to fit the terminal
+const minPrintWidth = 6
+
+// tableBorder holds the characters a printed table is drawn with. The rules
+// list the left, middle and right joints of the top, header and bottom lines.
+type tableBorder struct {
+	top, mid, bottom [3]string
+	h, v             string
+	ellipsis         string // marks a cut cell
+}
+
+// newTableBorder returns the border style named by --border
+func newTableBorder(name string) (tableBorder, error) {
+	switch name {
+	case borderBox:
+		return tableBorder{
+			top:    [3]string{"┌", "┬", "┐"},
+			mid:    [3]string{"├", "┼", "┤"},
+			bottom: [3]string{"└", "┴", "┘"},
+			h:      "─", v: "│", ellipsis: "…",
+		}, nil
+	case borderASCII:
+		return tableBorder{
+			top:    [3]string{"+", "+", "+"},
+			mid:    [3]string{"+", "+", "+"},
+			bottom: [3]string{"+", "+", "+"},
+			h:      "-", v: "|", ellipsis: "...",
+		}, nil
+	}
+	return tableBorder{}, errors.New("unknown border " + strconv.Quote(name) + ", use " + borderBox + " or " + borderASCII)
+}
+
+// stdoutIsTerminal reports whether output goes to a terminal, ftv prints the
+// table instead of starting the UI when it does not
+func stdoutIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// printWidth returns the width to fit the table in: the terminal's, else
+// $COLUMNS, else 0 for no limit
+func printWidth() int {
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 0
+}
+
+// printBuffer writes nb to stdout as configured by --border
+func printBuffer(nb *Buffer) error {
+	border, err := newTableBorder(args.Border)
+	if err != nil {
+		return err
+	}
+	return printTable(os.Stdout, nb, border, printWidth())
+}
+
+// printTabs prints the table of every tab under its file name, like head does
+// for several files
+func printTabs() error {
+	border, err := newTableBorder(args.Border)
+	if err != nil {
+		return err
+	}
+	width := printWidth()
+	for i, t := range tabs {
+		if i > 0 {
+			os.Stdout.WriteString("\n")
+		}
+		os.Stdout.WriteString("==> " + t.fileName + " <==\n")
+		if err := printTable(os.Stdout, t.buf, border, width); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cellReplacer keeps every cell on one line
+var cellReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// printTable writes the rows of nb as an aligned table. A frozen header row
+// is ruled off and centered like in the UI, columns that do not fit in width
+// are shrunk and then left out, a frozen first column is kept whole as long
+// as it fits (width <= 0 = no limit).
+func printTable(w io.Writer, nb *Buffer, border tableBorder, width int) error {
+	nb.mu.RLock()
+	defer nb.mu.RUnlock()
+
+	rows := nb.rowCount()
+	natural := make([]int, nb.colLen)
+	for r := 0; r < rows; r++ {
+		for c, cell := range nb.rowAt(r) {
+			if c < len(natural) {
+				natural[c] = max(natural[c], runewidth.StringWidth(cellReplacer.Replace(cell)))
+			}
+		}
+	}
+	widths := fitColumns(natural, width, nb.colFreeze > 0)
+
+	out := bufio.NewWriter(w)
+	rule := func(joints [3]string) {
+		out.WriteString(joints[0])
+		for c, cw := range widths {
+			if c > 0 {
+				out.WriteString(joints[1])
+			}
+			out.WriteString(strings.Repeat(border.h, cw+2))
+		}
+		out.WriteString(joints[2] + "\n")
+	}
+
+	rule(border.top)
+	for r := 0; r < rows; r++ {
+		cells := nb.rowAt(r)
+		isHeaderRow := r < nb.rowFreeze
+		out.WriteString(border.v)
+		for c, cw := range widths {
+			text := ""
+			if c < len(cells) {
+				text = cellReplacer.Replace(cells[c])
+			}
+			if runewidth.StringWidth(text) > cw {
+				text = runewidth.Truncate(text, cw, border.ellipsis)
+			}
+			pad := cw - runewidth.StringWidth(text)
+			left := 0
+			if isHeaderRow {
+				left = pad / 2
+			}
+			out.WriteString(" " + strings.Repeat(" ", left) + text + strings.Repeat(" ", pad-left) + " " + border.v)
+		}
+		out.WriteString("\n")
+		if isHeaderRow && r == nb.rowFreeze-1 && r < rows-1 {
+			rule(border.mid)
+		}
+	}
+	rule(border.bottom)
+	return out.Flush()
+}
+
+// fitColumns returns the printed widths of the leading columns that fit in
+// width, given their natural widths. Columns that fit at minPrintWidth are
+// kept and the widest of them shrunk until the table fits, keepFirst leaves
+// the first column as it is unless it cannot fit otherwise.
+func fitColumns(natural []int, width int, keepFirst bool) []int {
+	widths := append([]int{}, natural...)
+	// Each column takes a space on both sides and a border on its right
+	lineWidth := func(ws []int) int {
+		n := 1
+		for _, cw := range ws {
+			n += cw + 3
+		}
+		return n
+	}
+	if width <= 0 || lineWidth(widths) <= width {
+		return widths
+	}
+
+	minWidth := func(c int) int {
+		if c == 0 && keepFirst {
+			return natural[0]
+		}
+		return min(natural[c], minPrintWidth)
+	}
+	n, used := 0, 1
+	for n < len(widths) && used+minWidth(n)+3 <= width {
+		used += minWidth(n) + 3
+		n++
+	}
+	widths = widths[:max(n, 1)]
+
+	for excess := lineWidth(widths) - width; excess > 0; {
+		// Bring the widest column down to the next widest one at most
+		widest, next := -1, 0
+		for c, cw := range widths {
+			if cw <= minWidth(c) {
+				continue
+			}
+			if widest < 0 || cw > widths[widest] {
+				if widest >= 0 {
+					next = max(next, widths[widest])
+				}
+				widest = c
+			} else {
+				next = max(next, cw)
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		step := min(excess, max(widths[widest]-max(next, minWidth(widest)), 1))
+		widths[widest] -= step
+		excess -= step
+	}
+	// A single column wider than the terminal is cut to fit
+	if over := lineWidth(widths) - width; over > 0 {
+		widths[0] = max(widths[0]-over, 1)
+	}
+	return widths
+}
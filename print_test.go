@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPrintTable(t *testing.T) {
+	box, _ := newTableBorder(borderBox)
+	ascii, _ := newTableBorder(borderASCII)
+	data := [][]string{{"id", "name"}, {"1", "ann"}, {"22", "a very long\nname"}}
+	tests := []struct {
+		name      string
+		border    tableBorder
+		width     int
+		rowFreeze int
+		want      string
+	}{
+		{"Box with header", box, 0, 1, `
+┌────┬──────────────────┐
+│ id │       name       │
+├────┼──────────────────┤
+│ 1  │ ann              │
+│ 22 │ a very long name │
+└────┴──────────────────┘
+`},
+		{"ASCII without header", ascii, 0, 0, `
++----+------------------+
+| id | name             |
+| 1  | ann              |
+| 22 | a very long name |
++----+------------------+
+`},
+		{"Cut to width", ascii, 18, 1, `
++----+-----------+
+| id |   name    |
++----+-----------+
+| 1  | ann       |
+| 22 | a very... |
++----+-----------+
+`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := createNewBufferWithData(data, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b.rowFreeze = tt.rowFreeze
+			var sb strings.Builder
+			if err := printTable(&sb, b, tt.border, tt.width); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want[1:] {
+				t.Errorf("printTable() =\n%s\nwant\n%s", got, tt.want[1:])
+			}
+		})
+	}
+}
+
+func TestFitColumns(t *testing.T) {
+	tests := []struct {
+		name      string
+		natural   []int
+		width     int
+		keepFirst bool
+		want      []int
+	}{
+		{"No limit", []int{5, 40}, 0, false, []int{5, 40}},
+		{"Fits", []int{5, 10}, 22, false, []int{5, 10}},
+		{"Shrink the widest", []int{5, 30, 20}, 40, false, []int{5, 12, 13}},
+		{"Drop columns that do not fit", []int{10, 10, 10, 10}, 30, false, []int{6, 7, 7}},
+		{"Keep the frozen column", []int{12, 30}, 30, true, []int{12, 11}},
+		{"Cut a lone column", []int{50}, 20, true, []int{16}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fitColumns(tt.natural, tt.width, tt.keepFirst); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fitColumns(%v, %d) = %v, want %v", tt.natural, tt.width, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"sort"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 type sepDetecor struct {
@@ -15,38 +17,42 @@ type sepDetecor struct {
 // 3. Optimized character counting
 // 4. Better validation logic
 
-func (sd *sepDetecor) sepDetect(s []string) rune {
+func (sd *sepDetecor) sepDetect(s []string) string {
 	if len(s) < 1 {
-		return 0
+		return ""
 	}
 
+	// Multi-character separators like || or ^|^ are made of characters that
+	// would pass as separators on their own
+	multi := sd.multiCharSeparators(s)
+
 	// Fast path: Check common separators first (99% of cases)
-	commonSeps := []rune{',', '\t', '|', ';'}
+	commonSeps := []string{",", "\t", "|", ";"}
 	for _, sep := range commonSeps {
-		if sd.isValidSeparator(s, sep) {
+		if sd.isValidSeparator(s, sep) && !partOfSeparator(s, sep, multi) {
 			return sep
 		}
 	}
 
 	// Fallback: Analyze all potential separators
-	return sd.detectBestSeparator(s)
+	return sd.detectBestSeparator(s, multi)
 }
 
 // Fast validation: Check if a separator is valid for all lines
-func (sd *sepDetecor) isValidSeparator(lines []string, sep rune) bool {
+func (sd *sepDetecor) isValidSeparator(lines []string, sep string) bool {
 	if len(lines) == 0 {
 		return false
 	}
 
 	// Count separator occurrences in first line
-	firstCount := countRuneFast(lines[0], sep)
+	firstCount := strings.Count(lines[0], sep)
 	if firstCount == 0 {
 		return false // Separator not found
 	}
 
 	// Verify all lines have same count
 	for i := 1; i < len(lines); i++ {
-		if countRuneFast(lines[i], sep) != firstCount {
+		if strings.Count(lines[i], sep) != firstCount {
 			return false
 		}
 	}
@@ -54,32 +60,83 @@ func (sd *sepDetecor) isValidSeparator(lines []string, sep rune) bool {
 	return true
 }
 
-// Optimized rune counter - much faster than strings.Count for single runes
-func countRuneFast(s string, r rune) int {
-	count := 0
-	for _, c := range s {
-		if c == r {
-			count++
+// multiCharSeparators finds runs of symbols in the first line that occur the
+// same number of times on every line, longest first
+func (sd *sepDetecor) multiCharSeparators(lines []string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	line := lines[0]
+	for i := 0; i < len(line); {
+		j := i
+		for j < len(line) && isSeparatorSymbol(rune(line[j])) {
+			j++
+		}
+		if j == i {
+			i++
+			continue
+		}
+		run := line[i:j]
+		i = j
+		// Repeated commas, tabs or semicolons are empty fields, not a separator
+		if len(run) < 2 || len(run) > 4 || seen[run] || (strings.Trim(run, run[:1]) == "" && strings.Contains(",;", run[:1])) {
+			continue
+		}
+		seen[run] = true
+		if sd.isValidSeparator(lines, run) {
+			found = append(found, run)
+		}
+	}
+	sort.SliceStable(found, func(a, b int) bool { return len(found[a]) > len(found[b]) })
+	return found
+}
+
+// isSeparatorSymbol checks whether an ASCII character can be part of a
+// multi-character separator, quotes and escapes cannot
+func isSeparatorSymbol(r rune) bool {
+	if r == '"' || r == '\'' || r == '\\' {
+		return false
+	}
+	return r < utf8.RuneSelf && (unicode.IsPunct(r) || unicode.IsSymbol(r))
+}
+
+// partOfSeparator checks whether sep only ever occurs inside one of the
+// multi-character separators, on every line
+func partOfSeparator(lines []string, sep string, multi []string) bool {
+	for _, m := range multi {
+		per := strings.Count(m, sep)
+		if per == 0 || m == sep {
+			continue
+		}
+		inside := true
+		for _, line := range lines {
+			if strings.Count(line, sep) != strings.Count(line, m)*per {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			return true
 		}
 	}
-	return count
+	return false
 }
 
-// Analyze all potential separators when common ones don't work
-func (sd *sepDetecor) detectBestSeparator(lines []string) rune {
+// Analyze all potential separators when common ones don't work, multi is
+// the result of multiCharSeparators
+func (sd *sepDetecor) detectBestSeparator(lines []string, multi []string) string {
 	if len(lines) == 0 {
-		return 0
+		return ""
 	}
 
 	// Build candidate list from first line
-	candidates := sd.getCandidates(lines[0])
+	candidates := append(sd.getCandidates(lines[0]), multi...)
 	if len(candidates) == 0 {
-		return 0
+		return ""
 	}
 
 	// Score each candidate
 	type candidateScore struct {
-		sep   rune
+		sep   string
 		score int
 		count int
 	}
@@ -87,30 +144,10 @@ func (sd *sepDetecor) detectBestSeparator(lines []string) rune {
 	var scored []candidateScore
 
 	for _, sep := range candidates {
-		counts := make([]int, len(lines))
-		allEqual := true
-
-		// Count occurrences in each line
-		for i, line := range lines {
-			counts[i] = countRuneFast(line, sep)
-		}
-
-		// Check if all counts are equal and non-zero
-		firstCount := counts[0]
-		if firstCount == 0 {
-			continue
-		}
-
-		for i := 1; i < len(counts); i++ {
-			if counts[i] != firstCount {
-				allEqual = false
-				break
-			}
-		}
-
-		if !allEqual {
+		if !sd.isValidSeparator(lines, sep) || partOfSeparator(lines, sep, multi) {
 			continue
 		}
+		firstCount := strings.Count(lines[0], sep)
 
 		// Calculate score based on separator quality
 		score := sd.scoreSeparator(sep, firstCount)
@@ -123,7 +160,7 @@ func (sd *sepDetecor) detectBestSeparator(lines []string) rune {
 
 	// Return separator with highest score
 	if len(scored) == 0 {
-		return 0
+		return ""
 	}
 
 	best := scored[0]
@@ -137,17 +174,17 @@ func (sd *sepDetecor) detectBestSeparator(lines []string) rune {
 }
 
 // Get candidate separators from first line
-func (sd *sepDetecor) getCandidates(line string) []rune {
+func (sd *sepDetecor) getCandidates(line string) []string {
 	// Use map for deduplication
 	seen := make(map[rune]bool)
-	var candidates []rune
+	var candidates []string
 
 	// Priority characters to check first
 	priority := []rune{',', '\t', '|', ';', ':', ' '}
 	for _, r := range priority {
 		if strings.ContainsRune(line, r) && !seen[r] {
 			seen[r] = true
-			candidates = append(candidates, r)
+			candidates = append(candidates, string(r))
 		}
 	}
 
@@ -161,32 +198,36 @@ func (sd *sepDetecor) getCandidates(line string) []rune {
 			continue
 		}
 		seen[r] = true
-		candidates = append(candidates, r)
+		candidates = append(candidates, string(r))
 	}
 
 	return candidates
 }
 
 // Score separator quality (higher is better)
-func (sd *sepDetecor) scoreSeparator(sep rune, count int) int {
+func (sd *sepDetecor) scoreSeparator(sep string, count int) int {
 	score := 0
 
 	// Prefer common separators
 	switch sep {
-	case ',':
+	case ",":
 		score += 1000 // Highest priority
-	case '\t':
+	case "\t":
 		score += 900
-	case '|':
+	case "|":
 		score += 800
-	case ';':
+	case ";":
 		score += 700
-	case ':':
+	case ":":
 		score += 600
-	case ' ':
+	case " ":
 		score += 100 // Lowest priority (can be ambiguous)
 	default:
-		score += 500 // Moderate priority for other chars
+		if utf8.RuneCountInString(sep) > 1 {
+			score += 850 // Deliberate multi-character separators like ||
+		} else {
+			score += 500 // Moderate priority for other chars
+		}
 	}
 
 	// Prefer separators with reasonable column counts (2-100)
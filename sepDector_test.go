@@ -17,8 +17,8 @@ func TestSepDetect_Comma(t *testing.T) {
 	}
 
 	sep := sd.sepDetect(lines)
-	if sep != ',' {
-		t.Errorf("Expected comma separator, got %c", sep)
+	if sep != "," {
+		t.Errorf("Expected comma separator, got %q", sep)
 	}
 }
 
@@ -31,8 +31,8 @@ func TestSepDetect_Tab(t *testing.T) {
 	}
 
 	sep := sd.sepDetect(lines)
-	if sep != '\t' {
-		t.Errorf("Expected tab separator, got %c", sep)
+	if sep != "\t" {
+		t.Errorf("Expected tab separator, got %q", sep)
 	}
 }
 
@@ -45,8 +45,8 @@ func TestSepDetect_Pipe(t *testing.T) {
 	}
 
 	sep := sd.sepDetect(lines)
-	if sep != '|' {
-		t.Errorf("Expected pipe separator, got %c", sep)
+	if sep != "|" {
+		t.Errorf("Expected pipe separator, got %q", sep)
 	}
 }
 
@@ -59,8 +59,8 @@ func TestSepDetect_Semicolon(t *testing.T) {
 	}
 
 	sep := sd.sepDetect(lines)
-	if sep != ';' {
-		t.Errorf("Expected semicolon separator, got %c", sep)
+	if sep != ";" {
+		t.Errorf("Expected semicolon separator, got %q", sep)
 	}
 }
 
@@ -69,8 +69,8 @@ func TestSepDetect_EmptyInput(t *testing.T) {
 	lines := []string{}
 
 	sep := sd.sepDetect(lines)
-	if sep != 0 {
-		t.Errorf("Expected null separator for empty input, got %c", sep)
+	if sep != "" {
+		t.Errorf("Expected null separator for empty input, got %q", sep)
 	}
 }
 
@@ -85,9 +85,9 @@ func TestSepDetect_InconsistentSeparators(t *testing.T) {
 	// Should detect the most consistent separator
 	sep := sd.sepDetect(lines)
 	// Any valid separator is acceptable here
-	// With inconsistent separators, detection may fail (return 0)
+	// With inconsistent separators, detection may fail (return "")
 	// or detect the first consistent one
-	t.Logf("Detected separator for inconsistent input: %c (code: %d)", sep, sep)
+	t.Logf("Detected separator for inconsistent input: %q", sep)
 }
 
 func TestIsValidSeparator(t *testing.T) {
@@ -96,31 +96,31 @@ func TestIsValidSeparator(t *testing.T) {
 	tests := []struct {
 		name     string
 		lines    []string
-		sep      rune
+		sep      string
 		expected bool
 	}{
 		{
 			"Valid comma",
 			[]string{"a,b,c", "1,2,3"},
-			',',
+			",",
 			true,
 		},
 		{
 			"Invalid separator",
 			[]string{"a,b,c", "1,2,3"},
-			'|',
+			"|",
 			false,
 		},
 		{
 			"Inconsistent counts",
 			[]string{"a,b,c", "1,2"},
-			',',
+			",",
 			false,
 		},
 		{
 			"Empty lines",
 			[]string{},
-			',',
+			",",
 			false,
 		},
 	}
@@ -135,30 +135,6 @@ func TestIsValidSeparator(t *testing.T) {
 	}
 }
 
-func TestCountRuneFast(t *testing.T) {
-	tests := []struct {
-		name     string
-		s        string
-		r        rune
-		expected int
-	}{
-		{"Count commas", "a,b,c,d", ',', 3},
-		{"Count none", "abcd", ',', 0},
-		{"Count all", ",,,,", ',', 4},
-		{"Empty string", "", ',', 0},
-		{"Single char", "a", 'a', 1},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := countRuneFast(tt.s, tt.r)
-			if result != tt.expected {
-				t.Errorf("countRuneFast() = %d, want %d", result, tt.expected)
-			}
-		})
-	}
-}
-
 func TestGetCandidates(t *testing.T) {
 	sd := sepDetecor{}
 
@@ -172,7 +148,7 @@ func TestGetCandidates(t *testing.T) {
 	// Check that common separators are found
 	found := false
 	for _, c := range candidates {
-		if c == ',' || c == '|' || c == ';' {
+		if c == "," || c == "|" || c == ";" {
 			found = true
 			break
 		}
@@ -188,28 +164,28 @@ func TestScoreSeparator(t *testing.T) {
 
 	tests := []struct {
 		name  string
-		sep   rune
+		sep   string
 		count int
 	}{
-		{"Comma high score", ',', 5},
-		{"Tab high score", '\t', 5},
-		{"Pipe good score", '|', 5},
-		{"Semicolon good score", ';', 5},
-		{"Space low score", ' ', 5},
+		{"Comma high score", ",", 5},
+		{"Tab high score", "\t", 5},
+		{"Pipe good score", "|", 5},
+		{"Semicolon good score", ";", 5},
+		{"Space low score", " ", 5},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			score := sd.scoreSeparator(tt.sep, tt.count)
 			if score <= 0 {
-				t.Errorf("scoreSeparator(%c, %d) = %d, should be positive", tt.sep, tt.count, score)
+				t.Errorf("scoreSeparator(%q, %d) = %d, should be positive", tt.sep, tt.count, score)
 			}
 		})
 	}
 
 	// Verify comma has highest score
-	commaScore := sd.scoreSeparator(',', 5)
-	spaceScore := sd.scoreSeparator(' ', 5)
+	commaScore := sd.scoreSeparator(",", 5)
+	spaceScore := sd.scoreSeparator(" ", 5)
 
 	if commaScore <= spaceScore {
 		t.Error("Comma should have higher score than space")
@@ -274,12 +250,3 @@ func BenchmarkSepDetect_Comma(b *testing.B) {
 		sd.sepDetect(lines)
 	}
 }
-
-func BenchmarkCountRuneFast(b *testing.B) {
-	s := "a,b,c,d,e,f,g,h,i,j,k,l,m,n,o,p,q,r,s,t,u,v,w,x,y,z"
-
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		countRuneFast(s, ',')
-	}
-}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // pure Go driver, keeps the binaries static
+)
+
+// sqliteMagic starts every SQLite 3 database file
+const sqliteMagic = "SQLite format 3\x00"
+
+// sqliteQuerySource is the picker entry for the --query result
+const sqliteQuerySource = "(query)"
+
+// sqliteDB is a database file whose tables and views are opened one at a time
+type sqliteDB struct {
+	db    *sql.DB
+	names []string // tables and views
+}
+
+// openSQLite opens a database read-only and lists its tables and views
+func openSQLite(fn string) (*sqliteDB, error) {
+	// Rows of a table have no order to skip by, queries can filter them
+	if args.SkipNum > 0 {
+		return nil, errors.New("--skip-lines does not apply to SQLite tables, use --query to pick rows")
+	}
+	// A read-only URI fails on a missing file instead of creating it
+	db, err := sql.Open("sqlite", "file:"+(&url.URL{Path: fn}).EscapedPath()+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY type, name")
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	defer rows.Close()
+	sd := &sqliteDB{db: db}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			db.Close()
+			return nil, err
+		}
+		sd.names = append(sd.names, name)
+	}
+	if err := rows.Err(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return sd, nil
+}
+
+// quoteSQLiteName quotes a table name for use in a statement
+func quoteSQLiteName(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// loadTable loads a table or view into b, or the --query result for
+// sqliteQuerySource
+func (sd *sqliteDB) loadTable(name string, b *Buffer) error {
+	query := "SELECT * FROM " + quoteSQLiteName(name)
+	if name == sqliteQuerySource {
+		query = args.Query
+	}
+	return sd.loadQuery(query, b)
+}
+
+// loadQuery runs query and loads the result set into b, column types come
+// from the declared types and are detected only for computed columns
+func (sd *sqliteDB) loadQuery(query string, b *Buffer) error {
+	rows, err := sd.db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	return loadRows(rows, b, selectColumns, args.NLine, sqliteColType)
+}
+
+// loadRows loads a result set into b. visCol picks the columns to keep from
+// their names, at most limit rows are loaded (0 = all) and colType maps the
+// declared types onto column types, which are detected where it has none.
+func loadRows(rows *sql.Rows, b *Buffer, visCol func([]string) ([]int, error), limit int, colType func(string) (int, bool)) error {
+	cols, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name()
+	}
+	picked, err := visCol(names)
+	if err != nil {
+		return err
+	}
+	header := make([]string, len(picked))
+	decls := make([]string, len(picked))
+	for i, c := range picked {
+		header[i] = cols[c].Name()
+		decls[i] = cols[c].DatabaseTypeName()
+	}
+	if err := b.contAppendSli(header, false); err != nil {
+		return err
+	}
+
+	values := make([]any, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	totalAddedLN := 1
+	for rows.Next() {
+		if totalAddedLN >= limit && limit > 0 {
+			break
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return err
+		}
+		row := make([]string, len(picked))
+		for i, c := range picked {
+			row[i] = sqliteValueString(values[c], decls[i])
+		}
+		if err := b.contAppendSli(row, args.Strict); err != nil {
+			return err
+		}
+		totalAddedLN++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i, decl := range decls {
+		if t, ok := colType(decl); ok {
+			b.setColType(i, t)
+		} else {
+			b.setColType(i, b.autoDetectColumnType(i))
+		}
+	}
+	b.enableStringInterning()
+	return nil
+}
+
+// sqliteColType maps a declared column type onto a column type following the
+// SQLite affinity rules, ok is false for expressions, which declare none
+func sqliteColType(decl string) (int, bool) {
+	decl = strings.ToUpper(decl)
+	switch {
+	case decl == "":
+		return colTypeStr, false
+	case strings.Contains(decl, "INT"):
+		return colTypeFloat, true
+	case strings.Contains(decl, "CHAR"), strings.Contains(decl, "CLOB"), strings.Contains(decl, "TEXT"), strings.Contains(decl, "BLOB"):
+		return colTypeStr, true
+	case strings.Contains(decl, "REAL"), strings.Contains(decl, "FLOA"), strings.Contains(decl, "DOUB"):
+		return colTypeFloat, true
+	case strings.Contains(decl, "DATE"), strings.Contains(decl, "TIME"):
+		// NUMERIC affinity, but SQLite has no date type and these hold dates
+		return colTypeDate, true
+	}
+	// Other NUMERIC affinity columns, like DECIMAL or BOOLEAN
+	return colTypeFloat, true
+}
+
+// sqliteValueString renders a scanned value as cell text
+func sqliteValueString(v any, decl string) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []byte:
+		return bytesString(v)
+	case string:
+		return v
+	case time.Time:
+		if strings.Contains(strings.ToUpper(decl), "DATE") && !strings.Contains(strings.ToUpper(decl), "TIME") {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format(arrowTimestampLayout)
+	}
+	return ""
+}
+
+// newSQLiteSourceSet exposes the tables and views of a database to the
+// source picker, with the --query result first when there is one
+func newSQLiteSourceSet(sd *sqliteDB) (*sourceSet, error) {
+	names := sd.names
+	if args.Query != "" {
+		names = append([]string{sqliteQuerySource}, names...)
+	}
+	if len(names) == 0 {
+		return nil, errors.New("the database has no tables or views")
+	}
+	return &sourceSet{
+		kind:  "Table",
+		names: names,
+		load:  sd.loadTable,
+	}, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// createTestDatabase writes a small SQLite database with a table and a view
+func createTestDatabase(t *testing.T) string {
+	t.Helper()
+	fn := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite", fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE orders (id INTEGER PRIMARY KEY, customer VARCHAR(20), amount DECIMAL(10,2), placed DATE, note BLOB)`,
+		`INSERT INTO orders VALUES (1, 'ann', 12.5, '2024-03-01', X'CAFE'), (2, 'bob', 7, '2024-03-02', 'paid'), (3, NULL, 100.25, '2024-02-28', NULL)`,
+		`CREATE VIEW "big orders" AS SELECT id, customer FROM orders WHERE amount > 10`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fn
+}
+
+func TestSQLiteColType(t *testing.T) {
+	tests := []struct {
+		decl string
+		want int
+		ok   bool
+	}{
+		{"INTEGER", colTypeFloat, true},
+		{"BIGINT", colTypeFloat, true},
+		{"VARCHAR(20)", colTypeStr, true},
+		{"TEXT", colTypeStr, true},
+		{"BLOB", colTypeStr, true},
+		{"DOUBLE PRECISION", colTypeFloat, true},
+		{"DECIMAL(10,2)", colTypeFloat, true},
+		{"DATETIME", colTypeDate, true},
+		{"date", colTypeDate, true},
+		{"", colTypeStr, false},
+	}
+	for _, tt := range tests {
+		if got, ok := sqliteColType(tt.decl); got != tt.want || ok != tt.ok {
+			t.Errorf("sqliteColType(%q) = %d, %v, want %d, %v", tt.decl, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLoadSQLiteTables(t *testing.T) {
+	defer args.setDefault()
+	fn := createTestDatabase(t)
+	if format, err := detectFileFormat(fn); err != nil || format != formatSQLite {
+		t.Fatalf("detectFileFormat() = %d, %v", format, err)
+	}
+
+	set, err := openSourceSet(fn, formatSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"orders", "big orders"}; !reflect.DeepEqual(set.names, want) {
+		t.Fatalf("names = %q, want %q", set.names, want)
+	}
+
+	b := createNewBuffer()
+	if err := set.loadInto("orders", b); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"id", "customer", "amount", "placed", "note"},
+		{"1", "ann", "12.5", "2024-03-01", "cafe"},
+		{"2", "bob", "7", "2024-03-02", "paid"},
+		{"3", "", "100.25", "2024-02-28", ""},
+	}
+	if !reflect.DeepEqual(b.cont, want) {
+		t.Errorf("orders = %q, want %q", b.cont, want)
+	}
+	for i, want := range []int{colTypeFloat, colTypeStr, colTypeFloat, colTypeDate, colTypeStr} {
+		if got := b.getColType(i); got != want {
+			t.Errorf("column %d type = %d, want %d", i, got, want)
+		}
+	}
+
+	b = createNewBuffer()
+	if err := set.loadInto("big orders", b); err != nil {
+		t.Fatal(err)
+	}
+	if b.rowLen != 3 || b.cont[2][1] != "" {
+		t.Errorf("view = %q", b.cont)
+	}
+}
+
+func TestSQLiteQuery(t *testing.T) {
+	defer args.setDefault()
+	fn := createTestDatabase(t)
+	args.Query = "SELECT customer, amount * 2 AS doubled FROM orders WHERE customer IS NOT NULL ORDER BY id"
+	set, err := openSourceSet(fn, formatSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.names[0] != sqliteQuerySource {
+		t.Fatalf("names = %q, want the query first", set.names)
+	}
+	b := createNewBuffer()
+	if err := set.loadInto(sqliteQuerySource, b); err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"customer", "doubled"}, {"ann", "25"}, {"bob", "14"}}; !reflect.DeepEqual(b.cont, want) {
+		t.Errorf("result = %q, want %q", b.cont, want)
+	}
+	// Computed columns have no declared type and are detected
+	if b.getColType(1) != colTypeFloat {
+		t.Errorf("doubled type = %d, want number", b.getColType(1))
+	}
+
+	// The database is opened read-only
+	args.Query = "DELETE FROM orders"
+	if err := set.loadInto(sqliteQuerySource, createNewBuffer()); err == nil {
+		t.Error("A query that writes should fail")
+	}
+}
+
+func TestOpenSQLiteErrors(t *testing.T) {
+	defer args.setDefault()
+	// Names with URI characters are escaped
+	dir := filepath.Join(t.TempDir(), "db #1?")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	fn := filepath.Join(dir, "app 2%.db")
+	if err := os.Rename(createTestDatabase(t), fn); err != nil {
+		t.Fatal(err)
+	}
+	sd, err := openSQLite(fn)
+	if err != nil {
+		t.Fatalf("openSQLite(%q) error = %v", fn, err)
+	}
+	sd.db.Close()
+
+	missing := filepath.Join(dir, "missing.db")
+	if _, err := openSQLite(missing); err == nil {
+		t.Error("Opening a missing database should fail")
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("Opening a missing database should not create it, stat error = %v", err)
+	}
+
+	args.SkipNum = 1
+	if _, err := openSQLite(fn); err == nil {
+		t.Error("--skip-lines should be rejected for a database")
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// sqlTableName is the name of the displayed table in queries
+const sqlTableName = "t"
+
+// sqlDateDecl is declared for date columns. The driver turns DATE columns
+// into times, which would lose the format of the text, this one keeps TEXT
+// affinity and is mapped back to colTypeDate.
+const sqlDateDecl = "DATE_TEXT"
+
+// sqlColumnNames returns the names of the columns of nb in queries: the
+// header, made unique since SQLite ignores case, or c1, c2, ... without one
+func sqlColumnNames(nb *Buffer) []string {
+	var header []string
+	if nb.rowFreeze > 0 && nb.rowCount() > 0 {
+		header = nb.rowAt(0)
+	}
+	names := make([]string, nb.colLen)
+	seen := make(map[string]bool, nb.colLen)
+	for i := range names {
+		name := ""
+		if i < len(header) {
+			name = strings.TrimSpace(header[i])
+		}
+		if name == "" {
+			name = "c" + strconv.Itoa(i+1)
+		}
+		unique := name
+		for n := 2; seen[strings.ToLower(unique)]; n++ {
+			unique = name + "_" + strconv.Itoa(n)
+		}
+		seen[strings.ToLower(unique)] = true
+		names[i] = unique
+	}
+	return names
+}
+
+// sqlColumnDecl returns the declared type of a column of type t
+func sqlColumnDecl(t int) string {
+	switch t {
+	case colTypeFloat:
+		return "REAL"
+	case colTypeDate:
+		return sqlDateDecl
+	}
+	return "TEXT"
+}
+
+// sqlResultColType maps the declared types of a result onto column types,
+// expressions declare none and have theirs detected
+func sqlResultColType(decl string) (int, bool) {
+	switch decl {
+	case "REAL":
+		return colTypeFloat, true
+	case sqlDateDecl:
+		return colTypeDate, true
+	case "TEXT":
+		return colTypeStr, true
+	}
+	return colTypeStr, false
+}
+
+// sqlValue converts a cell of a column of type t for the database. Missing
+// numbers and dates are NULL so aggregates skip them, numbers that do not
+// parse stay text like SQLite keeps them.
+func sqlValue(cell string, t int) any {
+	if t == colTypeStr {
+		return cell
+	}
+	if isMissing(cell) {
+		return nil
+	}
+	if t == colTypeFloat {
+		s := strings.NewReplacer(",", "", "_", "").Replace(strings.TrimSpace(cell))
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return cell
+}
+
+// openBufferDB copies the data rows of nb into table t of an in-memory
+// database, typed after the column types
+func openBufferDB(nb *Buffer) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	// Every connection would get its own empty in-memory database
+	db.SetMaxOpenConns(1)
+	if err := fillBufferDB(db, nb); err != nil {
+		db.Close()
+		return nil, err
+	}
+	// The table is a copy, changing it would change nothing
+	if _, err := db.Exec("PRAGMA query_only = ON"); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// fillBufferDB creates table t for nb in db and inserts its data rows
+func fillBufferDB(db *sql.DB, nb *Buffer) error {
+	nb.mu.RLock()
+	defer nb.mu.RUnlock()
+	if nb.colLen == 0 {
+		return errors.New("the table has no columns")
+	}
+
+	names := sqlColumnNames(nb)
+	types := make([]int, nb.colLen)
+	defs := make([]string, nb.colLen)
+	marks := make([]string, nb.colLen)
+	for i, name := range names {
+		types[i] = nb.getColType(i)
+		defs[i] = quoteSQLiteName(name) + " " + sqlColumnDecl(types[i])
+		marks[i] = "?"
+	}
+	if _, err := db.Exec("CREATE TABLE " + sqlTableName + " (" + strings.Join(defs, ", ") + ")"); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.Prepare("INSERT INTO " + sqlTableName + " VALUES (" + strings.Join(marks, ", ") + ")")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	values := make([]any, nb.colLen)
+	for r := nb.rowFreeze; r < nb.dataRowCount(); r++ {
+		row := nb.rowAt(r)
+		for c := range values {
+			values[c] = nil
+			if c < len(row) {
+				values[c] = sqlValue(row[c], types[c])
+			}
+		}
+		if _, err := stmt.Exec(values...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// queryBuffer runs query against nb as table t and returns the result as a
+// new buffer with a header row
+func queryBuffer(nb *Buffer, query string) (*Buffer, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("the query is empty")
+	}
+	// Copying an indexed file into SQLite would load all of it after all
+	if nb.index != nil {
+		return nil, errors.New(indexedUnsupportedMsg)
+	}
+	db, err := openBufferDB(nb)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, errors.New(sqlErrorText(err))
+	}
+	defer rows.Close()
+	result := createNewBuffer()
+	result.colFreeze = nb.colFreeze
+	allColumns := func(names []string) ([]int, error) {
+		visCol := make([]int, len(names))
+		for i := range visCol {
+			visCol[i] = i
+		}
+		return visCol, nil
+	}
+	if err := loadRows(rows, result, allColumns, 0, sqlResultColType); err != nil {
+		return nil, errors.New(sqlErrorText(err))
+	}
+	if result.colLen == 0 {
+		return nil, errors.New("the statement returned no columns, only SELECT queries show a result")
+	}
+	return result, nil
+}
+
+// sqlErrorText drops the "SQL logic error" prefix and the result code the
+// driver puts around the message of the database
+func sqlErrorText(err error) string {
+	msg := strings.TrimPrefix(err.Error(), "SQL logic error: ")
+	if i := strings.LastIndex(msg, " ("); i > 0 && strings.HasSuffix(msg, ")") {
+		if _, err := strconv.Atoi(msg[i+2 : len(msg)-1]); err == nil {
+			msg = msg[:i]
+		}
+	}
+	return msg
+}
+
+// showQueryResult opens the result of a query in a tab of its own, the
+// query is kept in the footer
+func showQueryResult(query string, result *Buffer) {
+	sqlQueryCount++
+	openTab("query-"+strconv.Itoa(sqlQueryCount), result)
+	statusMessage = fmt.Sprintf("Query returned %d rows: %s", result.rowLen-result.rowFreeze, strings.Join(strings.Fields(query), " "))
+	updateFooterWithStatus(statusMessage)
+}
+
+// displaySQLArg shows the result of --sql over the loaded table b, printed or
+// in a tab next to the input
+func displaySQLArg() error {
+	result, err := queryBuffer(b, args.SQL)
+	if err != nil {
+		return errors.New("--sql: " + err.Error())
+	}
+	if args.Print {
+		return printBuffer(result)
+	}
+	if err := validateDataNotEmpty(result, "The query result"); err != nil {
+		return err
+	}
+	if err := drawUI(b); err != nil {
+		return err
+	}
+	lastSQLQuery = args.SQL
+	showQueryResult(args.SQL, result)
+	return runApp()
+}
+
+// showSQLForm opens the prompt that runs a query against the displayed
+// rows and opens the result in a new tab
+func showSQLForm() {
+	form := tview.NewForm()
+	closeForm := func() { closeModalForm("sqlModal") }
+
+	b.mu.RLock()
+	columns := strings.Join(sqlColumnNames(b), ", ")
+	b.mu.RUnlock()
+	hint := "[gray]SELECT region, count(*), avg(latency) FROM t GROUP BY region ORDER BY 2 DESC\nColumns of t: " + tview.Escape(columns) + "[-]"
+	form.AddInputField("Query:", lastSQLQuery, 80, nil, nil)
+	form.AddTextView("", hint, 80, 3, true, false)
+	queryField := form.GetFormItem(0).(*tview.InputField)
+	message := form.GetFormItem(1).(*tview.TextView)
+
+	run := func() {
+		query := strings.TrimSpace(queryField.GetText())
+		if query == "" {
+			closeForm()
+			return
+		}
+		lastSQLQuery = query
+		message.SetText("[yellow]Running...[-]")
+		app.ForceDraw()
+		result, err := queryBuffer(b, query)
+		if err != nil {
+			message.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+			return
+		}
+		if result.rowLen <= result.rowFreeze {
+			message.SetText("[red]The query returned no rows[-]")
+			return
+		}
+		closeForm()
+		showQueryResult(query, result)
+	}
+
+	showModalForm("sqlModal", form, " 🗃 SQL Query on the displayed rows - Enter to run, Esc to cancel ", "Run", run, 100, 10)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newSQLTestBuffer(t *testing.T) *Buffer {
+	t.Helper()
+	return newTestBuffer(t, [][]string{
+		{"region", "latency", "day", "Region"},
+		{"eu", "10", "2024-01-05", "x"},
+		{"us", "1,200", "2024-02-01", "y"},
+		{"eu", "30", "2024-03-01", "z"},
+		{"us", "NA", "2024-01-01", ""},
+		{"ap", "5.5", "", "w"},
+	}, colTypeStr, colTypeFloat, colTypeDate, colTypeStr)
+}
+
+func TestQueryBuffer(t *testing.T) {
+	nb := newSQLTestBuffer(t)
+	tests := []struct {
+		query string
+		want  [][]string
+		types []int
+	}{
+		{
+			"SELECT region, count(*) AS n, avg(latency), sum(latency) FROM t GROUP BY region ORDER BY 2 DESC, 1",
+			[][]string{{"region", "n", "avg(latency)", "sum(latency)"}, {"eu", "2", "20", "40"}, {"us", "2", "1200", "1200"}, {"ap", "1", "5.5", "5.5"}},
+			[]int{colTypeStr, colTypeFloat, colTypeFloat, colTypeFloat},
+		},
+		{
+			"SELECT day, Region_2 FROM t WHERE day >= '2024-02-01' ORDER BY day",
+			[][]string{{"day", "Region_2"}, {"2024-02-01", "y"}, {"2024-03-01", "z"}},
+			[]int{colTypeDate, colTypeStr},
+		},
+		{
+			"SELECT region FROM t WHERE latency IS NULL OR day IS NULL",
+			[][]string{{"region"}, {"us"}, {"ap"}},
+			[]int{colTypeStr},
+		},
+		{
+			"SELECT max(latency) > 1000 AS big FROM t",
+			[][]string{{"big"}, {"1"}},
+			[]int{colTypeFloat},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := queryBuffer(nb, tt.query)
+			if err != nil {
+				t.Fatalf("queryBuffer() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.cont, tt.want) {
+				t.Errorf("rows = %q, want %q", got.cont, tt.want)
+			}
+			if !reflect.DeepEqual(got.colType[:got.colLen], tt.types) {
+				t.Errorf("types = %v, want %v", got.colType[:got.colLen], tt.types)
+			}
+			if got.rowFreeze != 1 {
+				t.Errorf("rowFreeze = %d, want the header frozen", got.rowFreeze)
+			}
+		})
+	}
+}
+
+func TestQueryBufferWithoutHeader(t *testing.T) {
+	nb, err := createNewBufferWithData([][]string{{"a", "1"}, {"b", "2"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb.rowFreeze = 0
+	nb.colType = []int{colTypeStr, colTypeFloat, colTypeStr}
+	got, err := queryBuffer(nb, "SELECT c1 FROM t WHERE c2 > 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]string{{"c1"}, {"b"}}; !reflect.DeepEqual(got.cont, want) {
+		t.Errorf("rows = %q, want %q", got.cont, want)
+	}
+}
+
+func TestQueryBufferErrors(t *testing.T) {
+	nb := newSQLTestBuffer(t)
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"  ", "the query is empty"},
+		{"SELECT nope FROM t", "no such column: nope"},
+		{"SELEC * FROM t", `near "SELEC": syntax error`},
+		{"DELETE FROM t", "attempt to write a readonly database"},
+	}
+	for _, tt := range tests {
+		if _, err := queryBuffer(nb, tt.query); err == nil || err.Error() != tt.want {
+			t.Errorf("queryBuffer(%q) error = %v, want %q", tt.query, err, tt.want)
+		}
+	}
+	nb.index = &lineIndex{}
+	if _, err := queryBuffer(nb, "SELECT * FROM t"); err == nil || err.Error() != indexedUnsupportedMsg {
+		t.Errorf("queryBuffer() in indexed mode error = %v, want %q", err, indexedUnsupportedMsg)
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tab is a file opened next to others, with the view state that is global
+// while it is displayed
+type tab struct {
+	fileName string
+	buf      *Buffer // loaded content
+	loaded   bool
+	err      error       // why loading failed
+	drill    *groupDrill // set for group-by results
+
+	// saved when another tab is displayed
+	view               *Buffer // displayed buffer, buf or a filtered copy
+	originalBuffer     *Buffer
+	isFiltered         bool
+	activeFilters      map[int]FilterOptions
+	filterExpr         *rowFilter
+	searchResults      []SearchResult
+	currentSearchIndex int
+	searchQuery        string
+	searchUseRegex     bool
+	wrappedColumns     map[int]int
+	subSources         *sourceSet
+	statusMessage      string
+	userMovedCursor    bool
+	row, col           int
+}
+
+// expandFileArgs expands glob patterns among the file arguments, which the
+// shell leaves alone when quoted or on Windows. Names matching nothing are
+// kept so they are reported as missing.
+func expandFileArgs(names []string) []string {
+	var files []string
+	for _, name := range names {
+		if strings.ContainsAny(name, "*?[") {
+			if matches, err := filepath.Glob(name); err == nil && len(matches) > 0 {
+				files = append(files, matches...)
+				continue
+			}
+		}
+		files = append(files, name)
+	}
+	return files
+}
+
+// displayedSource returns the buffer the displayed view comes from, which is
+// not b while a filter is active
+func displayedSource() *Buffer {
+	if isFiltered && originalBuffer != nil {
+		return originalBuffer
+	}
+	return b
+}
+
+// source returns the buffer the view of t comes from
+func (t *tab) source() *Buffer {
+	switch {
+	case t.isFiltered && t.originalBuffer != nil:
+		return t.originalBuffer
+	case t.view != nil:
+		return t.view
+	}
+	return t.buf
+}
+
+// saveTab keeps the view state of the displayed tab in t
+func saveTab(t *tab) {
+	t.view = b
+	t.originalBuffer = originalBuffer
+	t.isFiltered = isFiltered
+	t.activeFilters = activeFilters
+	t.filterExpr = filterExpr
+	t.searchResults = searchResults
+	t.currentSearchIndex = currentSearchIndex
+	t.searchQuery = searchQuery
+	t.searchUseRegex = searchUseRegex
+	t.wrappedColumns = wrappedColumns
+	t.subSources = subSources
+	t.statusMessage = statusMessage
+	t.userMovedCursor = userMovedCursor
+	t.row, t.col = bufferTable.GetSelection()
+}
+
+// restoreTab displays t with the view state it had when it was left, a tab
+// shown for the first time starts with a fresh one
+func restoreTab(t *tab) {
+	if t.view == nil {
+		b = t.buf
+		resetViewState()
+		searchUseRegex = false
+		userMovedCursor = false
+		detectAndWrapLongColumns(b, 100, 50)
+		statusMessage = t.status()
+	} else {
+		b = t.view
+		originalBuffer = t.originalBuffer
+		isFiltered = t.isFiltered
+		activeFilters = t.activeFilters
+		filterExpr = t.filterExpr
+		searchResults = t.searchResults
+		currentSearchIndex = t.currentSearchIndex
+		searchQuery = t.searchQuery
+		searchUseRegex = t.searchUseRegex
+		wrappedColumns = t.wrappedColumns
+		statusMessage = t.statusMessage
+		userMovedCursor = t.userMovedCursor
+	}
+	subSources = t.subSources
+	args.FileName = t.fileName
+	currentCursorColumn = t.col
+
+	fileNameStr = buildFileNameStr()
+	bufferTable.SetFixed(b.rowFreeze, b.colFreeze)
+	drawBuffer(b, bufferTable)
+	bufferTable.Select(t.row, t.col)
+	cursorPosStr = buildCursorPosStr(t.row, t.col)
+	updateFooterWithStatus(statusMessage)
+}
+
+// openTab displays nb in a new tab named name, a single input becomes the
+// first tab
+func openTab(name string, nb *Buffer) {
+	if tabs == nil {
+		tabs = []*tab{{fileName: args.FileName, buf: displayedSource(), loaded: true, subSources: subSources, currentSearchIndex: -1}}
+		currentTab = 0
+	}
+	saveTab(tabs[currentTab])
+	tabs = append(tabs, &tab{fileName: name, buf: nb, loaded: true, currentSearchIndex: -1})
+	currentTab = len(tabs) - 1
+	restoreTab(tabs[currentTab])
+}
+
+// status returns the footer status of a tab shown for the first time
+func (t *tab) status() string {
+	switch {
+	case t.err != nil:
+		return "Cannot load " + filepath.Base(t.fileName) + ": " + t.err.Error()
+	case !t.loaded && t.buf.progress.TotalBytes > 0:
+		return "Loading... " + makeProgressBar(t.buf.progress.GetPercentage(), 15)
+	case !t.loaded:
+		return "Loading..."
+	}
+	return "Loaded " + strconv.Itoa(t.buf.rowCount()) + " rows"
+}
+
+// switchTab displays the tab at index i, counting around from either end
+func switchTab(i int) {
+	if len(tabs) < 2 {
+		return
+	}
+	saveTab(tabs[currentTab])
+	currentTab = (i%len(tabs) + len(tabs)) % len(tabs)
+	restoreTab(tabs[currentTab])
+}
+
+// buildTabStripStr builds the footer line that lists the open files with
+// their row counts, the displayed one highlighted
+func buildTabStripStr() string {
+	if len(tabs) < 2 {
+		return ""
+	}
+	var sb strings.Builder
+	for i, t := range tabs {
+		src := t.source()
+		if i == currentTab {
+			src = displayedSource()
+		}
+		src.mu.RLock()
+		rows := src.rowCount() - src.rowFreeze
+		src.mu.RUnlock()
+		if rows < 0 {
+			rows = 0
+		}
+
+		label := fmt.Sprintf(" %d:%s (%d", i+1, tview.Escape(filepath.Base(t.fileName)), rows)
+		switch {
+		case t.err != nil:
+			label += " !"
+		case !t.loaded:
+			label += "…"
+		}
+		label += ") "
+		if i == currentTab {
+			sb.WriteString("[black:darkorange]" + label + "[-:-]")
+		} else {
+			sb.WriteString(label)
+		}
+		sb.WriteString(" ")
+	}
+	return sb.String()
+}
+
+// addTabStrip adds the tab strip to the footer above the status line, the
+// footer texts must already be added
+func addTabStrip(f *tview.Frame) {
+	if strip := buildTabStripStr(); strip != "" {
+		f.AddText(strip, false, tview.AlignLeft, tcell.NewRGBColor(255, 150, 50))
+	}
+}
+
+// newTab prepares a tab for fn with a buffer configured from the command line
+func newTab(fn string) (*tab, error) {
+	nb := createNewBuffer()
+	if err := configureBuffer(nb); err != nil {
+		return nil, err
+	}
+	setupFreezeMode(nb)
+	return &tab{fileName: fn, buf: nb, currentSearchIndex: -1}, nil
+}
+
+// loaders returns the loaders for t. Workbooks, archives and databases load
+// their first table, or the member named like bundle.zip:data/x.csv, and keep the others
+// for the picker.
+func (t *tab) loaders() (func(*Buffer, chan<- bool, chan<- error), func(*Buffer) error, error) {
+	var set *sourceSet
+	var first string
+	if fn, member, ok := splitArchiveMember(t.fileName); ok {
+		a, err := openArchive(fn)
+		if err != nil {
+			return nil, nil, err
+		}
+		t.fileName = fn
+		set, first = newArchiveSourceSet(a), member
+	}
+	format := formatDelimited
+	if set == nil {
+		var err error
+		if format, err = detectFileFormat(t.fileName); err != nil {
+			return nil, nil, err
+		}
+		if hasSources(format) {
+			if set, err = openSourceSet(t.fileName, format); err != nil {
+				return nil, nil, err
+			}
+			first = set.names[0]
+		}
+	}
+	if set != nil {
+		t.subSources = set
+		syncLoader := func(b *Buffer) error {
+			return set.loadInto(first, b)
+		}
+		return func(b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+			go func() {
+				err := syncLoader(b)
+				if err == nil {
+					updateChan <- true
+				}
+				doneChan <- err
+			}()
+		}, syncLoader, nil
+	}
+
+	asyncLoader, syncLoader := fileLoaders(t.fileName, format)
+	return func(b *Buffer, updateChan chan<- bool, doneChan chan<- error) {
+			go asyncLoader(t.fileName, b, updateChan, doneChan)
+		}, func(b *Buffer) error {
+			return syncLoader(t.fileName, b)
+		}, nil
+}
+
+// loadAndDisplayTabs opens every file in its own tab. With async loading the
+// first file is displayed while it loads and the others follow one by one.
+func loadAndDisplayTabs(files []string, useAsync bool) error {
+	skipNum := args.SkipNum
+	tabs = nil
+	currentTab = 0
+	var asyncLoaders []func(*Buffer, chan<- bool, chan<- error)
+	var syncLoaders []func(*Buffer) error
+	for _, fn := range files {
+		t, err := newTab(fn)
+		if err != nil {
+			return err
+		}
+		asyncLoader, syncLoader, err := t.loaders()
+		if err != nil {
+			return errors.New(fn + ": " + err.Error())
+		}
+		tabs = append(tabs, t)
+		asyncLoaders = append(asyncLoaders, asyncLoader)
+		syncLoaders = append(syncLoaders, syncLoader)
+	}
+
+	first := tabs[0]
+	args.FileName = first.fileName
+	subSources = first.subSources
+
+	if !useAsync {
+		for i, t := range tabs {
+			// Options like --skip-lines apply to every file
+			args.SkipNum = skipNum
+			if err := syncLoaders[i](t.buf); err != nil {
+				return errors.New(t.fileName + ": " + err.Error())
+			}
+			t.loaded = true
+		}
+		if args.Print {
+			return printTabs()
+		}
+		if err := drawUI(first.buf); err != nil {
+			return err
+		}
+		return runApp()
+	}
+
+	updateChan, doneChan, err := loadDataAsync(asyncLoaders[0], first.buf)
+	if err != nil {
+		return errors.New(first.fileName + ": " + err.Error())
+	}
+	if err := drawUI(first.buf); err != nil {
+		return err
+	}
+
+	// Files are loaded one at a time so they do not compete for the disk
+	go func() {
+		for i, t := range tabs {
+			if i > 0 {
+				args.SkipNum = skipNum
+				updateChan = make(chan bool, 10)
+				doneChan = make(chan error, 1)
+				asyncLoaders[i](t.buf, updateChan, doneChan)
+			}
+			// A file that cannot be loaded leaves the other tabs usable
+			err := runAsyncUpdates(t.buf, updateChan, doneChan)
+			app.QueueUpdateDraw(func() {
+				t.loaded = true
+				t.err = err
+				if err != nil && tabs[currentTab] == t {
+					statusMessage = t.status()
+				}
+				updateFooterWithStatus(statusMessage)
+			})
+		}
+	}()
+	return runApp()
+}
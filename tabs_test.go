@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rivo/tview"
+)
+
+func TestExpandFileArgs(t *testing.T) {
+	got := expandFileArgs([]string{"./data/test/cities_*.csv", "./data/test/test.csv", "./data/test/missing_*.csv"})
+	want := []string{
+		"data/test/cities_cp1252.csv",
+		"data/test/cities_utf16le.csv",
+		"data/test/cities_utf8bom.csv",
+		"./data/test/test.csv",
+		"./data/test/missing_*.csv",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expandFileArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestSwitchTab(t *testing.T) {
+	defer func() {
+		args.setDefault()
+		tabs = nil
+		currentTab = 0
+		resetViewState()
+	}()
+	bufferTable = tview.NewTable()
+
+	tabs = nil
+	for _, fn := range []string{"./data/test/cities_utf8bom.csv", "./data/test/sort_test.csv"} {
+		tb, err := newTab(fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, syncLoader, err := tb.loaders()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := syncLoader(tb.buf); err != nil {
+			t.Fatalf("Loading %s: %v", fn, err)
+		}
+		tb.loaded = true
+		tabs = append(tabs, tb)
+	}
+	currentTab = 0
+	restoreTab(tabs[0])
+
+	// Filter and search the first tab
+	filtered := createNewBuffer()
+	filtered.contAppendSli([]string{"city", "country", "population"}, false)
+	filtered.contAppendSli([]string{"Zürich", "Switzerland", "421878"}, false)
+	originalBuffer, b, isFiltered = tabs[0].buf, filtered, true
+	activeFilters = map[int]FilterOptions{0: {Query: "Zürich"}}
+	searchQuery = "Spain"
+	bufferTable.Select(1, 1)
+
+	switchTab(1)
+	if b != tabs[1].buf || isFiltered || originalBuffer != nil || len(activeFilters) != 0 || searchQuery != "" {
+		t.Fatal("The second tab should start with its own, empty view state")
+	}
+	if args.FileName != tabs[1].fileName {
+		t.Errorf("args.FileName = %q, want %q", args.FileName, tabs[1].fileName)
+	}
+	strip := buildTabStripStr()
+	if !strings.Contains(strip, "1:cities_utf8bom.csv (3)") || !strings.Contains(strip, "[black:darkorange] 2:sort_test.csv (") {
+		t.Errorf("Unexpected tab strip %q", strip)
+	}
+
+	// Around the end back to the first tab
+	switchTab(2)
+	if b != filtered || originalBuffer != tabs[0].buf || !isFiltered || activeFilters[0].Query != "Zürich" || searchQuery != "Spain" {
+		t.Error("The first tab should get its filter and search back")
+	}
+	if row, col := bufferTable.GetSelection(); row != 1 || col != 1 {
+		t.Errorf("Selection = %d,%d, want 1,1", row, col)
+	}
+	if strip := buildTabStripStr(); !strings.Contains(strip, "1:cities_utf8bom.csv (3)") {
+		t.Errorf("The strip should count the rows of the unfiltered file: %q", strip)
+	}
+}
+
+func TestBuildTabStripSingleFile(t *testing.T) {
+	tabs = nil
+	if got := buildTabStripStr(); got != "" {
+		t.Errorf("buildTabStripStr() = %q without tabs, want empty", got)
+	}
+}
+
+func TestTabStatusShowsItsOwnProgress(t *testing.T) {
+	first, second := &tab{buf: createNewBuffer()}, &tab{buf: createNewBuffer()}
+	first.buf.progress = LoadProgress{TotalBytes: 100, LoadedBytes: 50}
+	second.buf.progress = LoadProgress{TotalBytes: 100, LoadedBytes: 10}
+	if got, want := first.status(), "Loading... "+makeProgressBar(50, 15); got != want {
+		t.Errorf("status() = %q, want %q", got, want)
+	}
+	if got, want := second.status(), "Loading... "+makeProgressBar(10, 15); got != want {
+		t.Errorf("status() = %q, want %q", got, want)
+	}
+	second.buf.progress = LoadProgress{}
+	if got := second.status(); got != "Loading..." {
+		t.Errorf("status() of a pipe = %q", got)
+	}
+}
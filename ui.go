@@ -16,13 +16,62 @@ func buildCursorPosStr(row, column int) string {
 	return posStr
 }
 
+// buildFileNameStr builds the footer file name, with the current sheet or
+// table when the input holds several
+func buildFileNameStr() string {
+	shorFileName := filepath.Base(args.FileName)
+	if subSources != nil && subSources.current != "" {
+		shorFileName += " [" + subSources.current + "]"
+	}
+	return shorFileName + "  |  " + "? help"
+}
+
+// resetViewState clears search, filter and wrapping state that belongs to the
+// displayed buffer
+func resetViewState() {
+	wrappedColumns = make(map[int]int)
+	searchResults = []SearchResult{}
+	currentSearchIndex = -1
+	searchQuery = ""
+	originalBuffer = nil
+	isFiltered = false
+	activeFilters = make(map[int]FilterOptions)
+	filterExpr = nil
+	currentCursorColumn = 0
+	visualAnchor = nil
+	yankPending = false
+}
+
+// showBuffer replaces the displayed buffer with nb, e.g. after switching sheets
+func showBuffer(nb *Buffer) {
+	b = nb
+	resetViewState()
+	fileNameStr = buildFileNameStr()
+	bufferTable.SetFixed(b.rowFreeze, b.colFreeze)
+	detectAndWrapLongColumns(b, 100, 50)
+	drawBuffer(b, bufferTable)
+	bufferTable.Select(0, 0)
+	bufferTable.ScrollToBeginning()
+	cursorPosStr = buildCursorPosStr(0, 0)
+}
+
 // buildFilterInfoStr builds the filter information string for the top strip
 // Shows all active filters or current column filter when cursor is on a filtered column
 func buildFilterInfoStr(currentColumn int) string {
-	if !isFiltered || len(activeFilters) == 0 {
+	if !isFiltered || activeFilterCount() == 0 {
 		return "" // No filter active
 	}
 
+	// The expression is shown whichever column the cursor is on
+	exprStr := ""
+	if filterExpr != nil {
+		exprStr = "🔎 Expression: " + tview.Escape(filterExpr.text) + "  |  Press '&' to edit"
+		if len(activeFilters) == 0 {
+			return exprStr
+		}
+		exprStr += "  |  "
+	}
+
 	// Check if current column has a filter
 	if opts, hasFilter := activeFilters[currentColumn]; hasFilter {
 		// Get column name if available
@@ -31,15 +80,21 @@ func buildFilterInfoStr(currentColumn int) string {
 			columnName = b.cont[0][currentColumn]
 		}
 
-		return fmt.Sprintf("🔎 Filter Active: [%s] %s \"%s\"  |  %d filters total  |  Press 'r' to remove this filter", columnName, opts.Operator, opts.Query, len(activeFilters))
+		return exprStr + fmt.Sprintf("🔎 Filter Active: [%s] %s \"%s\"  |  %d filters total  |  Press 'r' to remove this filter", columnName, opts.Operator, opts.Query, activeFilterCount())
 	}
 
 	// Show summary if cursor is not on a filtered column
-	return fmt.Sprintf("🔎 %d filters active  |  Navigate to filtered column and press 'r' to remove", len(activeFilters))
+	return exprStr + fmt.Sprintf("🔎 %d filters active  |  Navigate to filtered column and press 'r' to remove", activeFilterCount())
 }
 
 // add buffer data to buffer table with optimized wrapped column lookup
 func drawBuffer(b *Buffer, t *tview.Table) {
+	// Indexed files are too big for the table, it pulls visible rows instead
+	if b.index != nil {
+		t.SetContent(&indexedTableContent{b: b})
+		return
+	}
+
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
@@ -60,39 +115,6 @@ func drawBuffer(b *Buffer, t *tview.Table) {
 
 	for r := 0; r < rows; r++ {
 		for c := 0; c < cols; c++ {
-			color := tcell.ColorWhite
-			backgroundColor := tcell.ColorDefault
-			attributes := tcell.AttrNone
-			alignment := tview.AlignLeft
-
-			// Get cell content
-			cellText := b.cont[r][c]
-
-			// Check if this is a header row/column (frozen area)
-			isHeaderRow := r < b.rowFreeze && args.Header != -1 && args.Header != 2
-			isHeaderCol := c < b.colFreeze
-
-			// Modern header styling with rich visual design
-			if isHeaderRow {
-				// Main header row: bold white text on gradient blue background
-				color = tcell.ColorWhite
-				backgroundColor = tcell.NewRGBColor(30, 60, 120) // Deep blue
-				attributes = tcell.AttrBold | tcell.AttrUnderline
-				alignment = tview.AlignCenter
-
-				// Add filter indicator if this column has a filter applied
-				if isFiltered {
-					if _, hasFilter := activeFilters[c]; hasFilter {
-						cellText = "🔎 " + cellText + " 🔎"
-						backgroundColor = tcell.NewRGBColor(255, 100, 0) // Orange background for filtered column
-					}
-				}
-			} else if isHeaderCol {
-				// Frozen column: gold color for row headers
-				color = tcell.NewRGBColor(255, 215, 0) // Gold
-				attributes = tcell.AttrBold
-			}
-
 			// Check if this cell is a search result and highlight it
 			isSearchMatch := false
 			if searchQuery != "" && len(searchResults) > 0 {
@@ -104,46 +126,126 @@ func drawBuffer(b *Buffer, t *tview.Table) {
 				}
 			}
 
-			// Modern search match highlighting (overrides header styling)
-			if isSearchMatch {
-				// Check if this is the current search result
-				if currentSearchIndex >= 0 && currentSearchIndex < len(searchResults) &&
-					searchResults[currentSearchIndex].Row == r &&
-					searchResults[currentSearchIndex].Col == c {
-					// Current match: vibrant cyan highlight
-					backgroundColor = tcell.NewRGBColor(0, 180, 216)
-					color = tcell.ColorBlack
-					attributes = tcell.AttrBold
-				} else {
-					// Other matches: soft purple highlight
-					backgroundColor = tcell.NewRGBColor(100, 100, 150)
-					color = tcell.ColorWhite
-					attributes = tcell.AttrNone
-				}
-			}
-
 			// Use pre-computed column info for truncation
 			maxWidth := 0
 			if colInfos[c].needsTruncate {
 				maxWidth = colInfos[c].maxWidth
-				cellText = truncateText(cellText, maxWidth)
 			}
 
-			// Create cell with modern styling
-			cell := tview.NewTableCell(cellText).
-				SetTextColor(color).
-				SetBackgroundColor(backgroundColor).
-				SetAttributes(attributes).
-				SetAlign(alignment).
-				SetExpansion(1)
+			t.SetCell(r, c, newBufferCell(b, r, c, b.cont[r][c], isSearchMatch, maxWidth))
+		}
+	}
+}
 
-			if maxWidth > 0 {
-				cell.SetMaxWidth(maxWidth)
+// newBufferCell creates a styled table cell for row r and column c
+func newBufferCell(b *Buffer, r, c int, cellText string, isSearchMatch bool, maxWidth int) *tview.TableCell {
+	color := tcell.ColorWhite
+	backgroundColor := tcell.ColorDefault
+	attributes := tcell.AttrNone
+	alignment := tview.AlignLeft
+
+	// Check if this is a header row/column (frozen area)
+	isHeaderRow := r < b.rowFreeze && args.Header != -1 && args.Header != 2
+	isHeaderCol := c < b.colFreeze
+
+	// Modern header styling with rich visual design
+	if isHeaderRow {
+		// Main header row: bold white text on gradient blue background
+		color = tcell.ColorWhite
+		backgroundColor = tcell.NewRGBColor(30, 60, 120) // Deep blue
+		attributes = tcell.AttrBold | tcell.AttrUnderline
+		alignment = tview.AlignCenter
+
+		// Add filter indicator if this column has a filter applied
+		if isFiltered {
+			if _, hasFilter := activeFilters[c]; hasFilter {
+				cellText = "🔎 " + cellText + " 🔎"
+				backgroundColor = tcell.NewRGBColor(255, 100, 0) // Orange background for filtered column
 			}
+		}
+	} else if b.pinnedRows > 0 && r >= b.rowLen-b.pinnedRows {
+		// Pinned rows like pivot totals: bold gold, they stay below the data
+		color = tcell.NewRGBColor(255, 215, 0) // Gold
+		attributes = tcell.AttrBold
+	} else if isHeaderCol {
+		// Frozen column: gold color for row headers
+		color = tcell.NewRGBColor(255, 215, 0) // Gold
+		attributes = tcell.AttrBold
+	}
+
+	// Modern search match highlighting (overrides header styling)
+	if isSearchMatch {
+		// Check if this is the current search result
+		if currentSearchIndex >= 0 && currentSearchIndex < len(searchResults) &&
+			searchResults[currentSearchIndex].Row == r &&
+			searchResults[currentSearchIndex].Col == c {
+			// Current match: vibrant cyan highlight
+			backgroundColor = tcell.NewRGBColor(0, 180, 216)
+			color = tcell.ColorBlack
+			attributes = tcell.AttrBold
+		} else {
+			// Other matches: soft purple highlight
+			backgroundColor = tcell.NewRGBColor(100, 100, 150)
+			color = tcell.ColorWhite
+			attributes = tcell.AttrNone
+		}
+	}
+
+	// Visual selection: teal background over the selected block
+	if inVisualSelection(r, c) {
+		backgroundColor = tcell.NewRGBColor(0, 110, 110)
+		color = tcell.ColorWhite
+	}
+
+	if maxWidth > 0 {
+		cellText = truncateText(cellText, maxWidth)
+	}
+
+	// Create cell with modern styling
+	cell := tview.NewTableCell(cellText).
+		SetTextColor(color).
+		SetBackgroundColor(backgroundColor).
+		SetAttributes(attributes).
+		SetAlign(alignment).
+		SetExpansion(1)
+
+	if maxWidth > 0 {
+		cell.SetMaxWidth(maxWidth)
+	}
+	return cell
+}
+
+// indexedTableContent feeds bufferTable from a line index, rows are read
+// from disk as they scroll into view
+type indexedTableContent struct {
+	tview.TableContentReadOnly
+	b    *Buffer
+	hits map[SearchResult]bool // searchResults as a set, built on first use
+}
+
+func (tc *indexedTableContent) GetRowCount() int {
+	return tc.b.rowCount()
+}
+
+func (tc *indexedTableContent) GetColumnCount() int {
+	return tc.b.colLen
+}
 
-			t.SetCell(r, c, cell)
+func (tc *indexedTableContent) GetCell(row, column int) *tview.TableCell {
+	cells := tc.b.index.row(row)
+	if column < 0 || column >= len(cells) {
+		return nil
+	}
+	if tc.hits == nil {
+		tc.hits = make(map[SearchResult]bool, len(searchResults))
+		if searchQuery != "" {
+			for _, result := range searchResults {
+				tc.hits[result] = true
+			}
 		}
 	}
+	isSearchMatch := tc.hits[SearchResult{Row: row, Col: column}]
+	return newBufferCell(tc.b, row, column, cells[column], isSearchMatch, wrappedColumns[column])
 }
 
 // add stats data to stats table
@@ -176,8 +278,10 @@ func drawStats(s statsSummary, t *tview.Table) {
 	}
 }
 
-// draw app UI
-func drawUI(b *Buffer) error {
+// draw app UI, the key handlers work on the global b, which filters, sheets
+// and tabs replace
+func drawUI(nb *Buffer) error {
+	b = nb
 
 	//bufferTable init with modern styling
 	bufferTable = tview.NewTable()
@@ -202,9 +306,8 @@ func drawUI(b *Buffer) error {
 	if statusMessage == "" {
 		statusMessage = "All Done"
 	}
-	shorFileName := filepath.Base(args.FileName)
-	fileNameStr = shorFileName + "  |  " + "? help" //footer left
-	filterInfoStr := buildFilterInfoStr(0)          // Top strip for filter info, initially at column 0
+	fileNameStr = buildFileNameStr()       //footer left
+	filterInfoStr := buildFilterInfoStr(0) // Top strip for filter info, initially at column 0
 
 	mainPage = tview.NewFrame(bufferTable).
 		SetBorders(0, 0, 0, 0, 0, 0)
@@ -218,6 +321,7 @@ func drawUI(b *Buffer) error {
 	mainPage.AddText(fileNameStr, false, tview.AlignLeft, tcell.NewRGBColor(255, 150, 50)).
 		AddText(statusMessage, false, tview.AlignCenter, tcell.NewRGBColor(100, 200, 255)).
 		AddText(cursorPosStr, false, tview.AlignRight, tcell.NewRGBColor(150, 255, 150))
+	addTabStrip(mainPage)
 
 	drawFooterText := func(lstr, cstr, rstr string) {
 		statusMessage = cstr // Update global status
@@ -233,6 +337,7 @@ func drawUI(b *Buffer) error {
 		mainPage.AddText(lstr, false, tview.AlignLeft, tcell.NewRGBColor(255, 150, 50)).
 			AddText(cstr, false, tview.AlignCenter, tcell.NewRGBColor(100, 200, 255)).
 			AddText(rstr, false, tview.AlignRight, tcell.NewRGBColor(150, 255, 150))
+		addTabStrip(mainPage)
 	}
 
 	//UI init - add pages to UI container
@@ -252,6 +357,7 @@ func drawUI(b *Buffer) error {
 
 		// Update current cursor column
 		currentCursorColumn = column
+		moveVisual(row, column)
 
 		cursorPosStr = buildCursorPosStr(row, column)
 
@@ -266,6 +372,11 @@ func drawUI(b *Buffer) error {
 
 	//bufferTable HotKey Event
 	bufferTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// y, yy, yc - copy cell, row or column; v - visual selection
+		if handleYankKey(event) {
+			return nil
+		}
+
 		// Mark that user is interacting with cursor movement keys
 		if event.Key() == tcell.KeyUp || event.Key() == tcell.KeyDown ||
 			event.Key() == tcell.KeyLeft || event.Key() == tcell.KeyRight ||
@@ -298,7 +409,7 @@ func drawUI(b *Buffer) error {
 		// j - move down
 		if event.Key() == tcell.KeyRune && event.Rune() == 'j' {
 			row, col := bufferTable.GetSelection()
-			if row < b.rowLen-1 {
+			if row < b.rowCount()-1 {
 				bufferTable.Select(row+1, col)
 			}
 			return nil
@@ -333,7 +444,7 @@ func drawUI(b *Buffer) error {
 		// G - go to last row
 		if event.Key() == tcell.KeyRune && event.Rune() == 'G' {
 			_, col := bufferTable.GetSelection()
-			bufferTable.Select(b.rowLen-1, col)
+			bufferTable.Select(b.rowCount()-1, col)
 			bufferTable.ScrollToEnd()
 			return nil
 		}
@@ -342,8 +453,8 @@ func drawUI(b *Buffer) error {
 		if event.Key() == tcell.KeyCtrlD {
 			row, col := bufferTable.GetSelection()
 			newRow := row + 10 // Move 10 rows down
-			if newRow >= b.rowLen {
-				newRow = b.rowLen - 1
+			if newRow >= b.rowCount() {
+				newRow = b.rowCount() - 1
 			}
 			bufferTable.Select(newRow, col)
 			return nil
@@ -548,6 +659,10 @@ func drawUI(b *Buffer) error {
 
 		// f - column filter functionality
 		if event.Key() == tcell.KeyRune && event.Rune() == 'f' {
+			if b.index != nil {
+				drawFooterText(fileNameStr, indexedUnsupportedMsg, cursorPosStr)
+				return nil
+			}
 			_, column := bufferTable.GetSelection()
 
 			// Create filter form
@@ -603,10 +718,7 @@ func drawUI(b *Buffer) error {
 					}
 
 					// Start with original buffer and apply all filters sequentially
-					filteredBuffer := originalBuffer
-					for col, opts := range activeFilters {
-						filteredBuffer = filteredBuffer.filterByColumn(col, opts)
-					}
+					filteredBuffer := applyFilters()
 
 					// Update display with filtered data
 					if filteredBuffer.rowLen <= filteredBuffer.rowFreeze {
@@ -622,7 +734,7 @@ func drawUI(b *Buffer) error {
 						bufferTable.Select(0, column) // Stay at same column, go to first row
 						matchCount := b.rowLen - b.rowFreeze
 						drawFooterText(fileNameStr,
-							fmt.Sprintf("Filtered: %d rows match (%d filters active, r to reset)", matchCount, len(activeFilters)),
+							fmt.Sprintf("Filtered: %d rows match (%d filters active, r to reset)", matchCount, activeFilterCount()),
 							cursorPosStr)
 					}
 				} else {
@@ -631,7 +743,7 @@ func drawUI(b *Buffer) error {
 						delete(activeFilters, column)
 
 						// Reapply remaining filters
-						if len(activeFilters) == 0 {
+						if activeFilterCount() == 0 {
 							// No more filters, restore original
 							b = originalBuffer
 							isFiltered = false
@@ -640,16 +752,12 @@ func drawUI(b *Buffer) error {
 							drawFooterText(fileNameStr, "All filters cleared - showing all rows", cursorPosStr)
 						} else {
 							// Apply remaining filters
-							filteredBuffer := originalBuffer
-							for col, opts := range activeFilters {
-								filteredBuffer = filteredBuffer.filterByColumn(col, opts)
-							}
-							b = filteredBuffer
+							b = applyFilters()
 							drawBuffer(b, bufferTable)
 							bufferTable.Select(0, column) // Stay at same column
 							matchCount := b.rowLen - b.rowFreeze
 							drawFooterText(fileNameStr,
-								fmt.Sprintf("Filter removed: %d rows match (%d filters active)", matchCount, len(activeFilters)),
+								fmt.Sprintf("Filter removed: %d rows match (%d filters active)", matchCount, activeFilterCount()),
 								cursorPosStr)
 						}
 					}
@@ -744,7 +852,7 @@ func drawUI(b *Buffer) error {
 					delete(activeFilters, column)
 
 					// Reapply remaining filters
-					if len(activeFilters) == 0 {
+					if activeFilterCount() == 0 {
 						// No more filters, restore original
 						b = originalBuffer
 						isFiltered = false
@@ -753,19 +861,15 @@ func drawUI(b *Buffer) error {
 						drawFooterText(fileNameStr, "All filters cleared - showing all rows", cursorPosStr)
 					} else {
 						// Apply remaining filters
-						filteredBuffer := originalBuffer
-						for col, opts := range activeFilters {
-							filteredBuffer = filteredBuffer.filterByColumn(col, opts)
-						}
-						b = filteredBuffer
+						b = applyFilters()
 						drawBuffer(b, bufferTable)
 						bufferTable.Select(row, column)
 						matchCount := b.rowLen - b.rowFreeze
 						drawFooterText(fileNameStr,
-							fmt.Sprintf("Filter removed from current column: %d rows match (%d filters active)", matchCount, len(activeFilters)),
+							fmt.Sprintf("Filter removed from current column: %d rows match (%d filters active)", matchCount, activeFilterCount()),
 							cursorPosStr)
 					}
-				} else if len(activeFilters) > 0 {
+				} else if activeFilterCount() > 0 {
 					// Current column doesn't have a filter, but others do
 					drawFooterText(fileNameStr, "Current column has no filter - navigate to filtered column to remove", cursorPosStr)
 				}
@@ -775,6 +879,10 @@ func drawUI(b *Buffer) error {
 
 		// s - sort by column, ascending (s for sort)
 		if event.Key() == tcell.KeyRune && event.Rune() == 's' {
+			if b.index != nil {
+				drawFooterText(fileNameStr, indexedUnsupportedMsg, cursorPosStr)
+				return nil
+			}
 			_, column := bufferTable.GetSelection()
 			drawFooterText(fileNameStr, "Sorting...", cursorPosStr)
 			app.ForceDraw()
@@ -793,6 +901,10 @@ func drawUI(b *Buffer) error {
 
 		// S - sort by column, descending (capital S for reverse sort)
 		if event.Key() == tcell.KeyRune && event.Rune() == 'S' {
+			if b.index != nil {
+				drawFooterText(fileNameStr, indexedUnsupportedMsg, cursorPosStr)
+				return nil
+			}
 			_, column := bufferTable.GetSelection()
 			drawFooterText(fileNameStr, "Sorting...", cursorPosStr)
 			app.ForceDraw()
@@ -811,6 +923,10 @@ func drawUI(b *Buffer) error {
 
 		// i - show stats info for current column
 		if event.Key() == tcell.KeyRune && event.Rune() == 'i' {
+			if b.index != nil {
+				drawFooterText(fileNameStr, indexedUnsupportedMsg, cursorPosStr)
+				return nil
+			}
 			_, column := bufferTable.GetSelection()
 			drawFooterText(fileNameStr, "Calculating statistics...", cursorPosStr)
 			app.ForceDraw()
@@ -821,6 +937,8 @@ func drawUI(b *Buffer) error {
 
 			var statsS statsSummary
 			summaryArray := currentBuffer.getCol(column)
+			// Pinned rows like pivot totals are not data
+			summaryArray = summaryArray[:len(summaryArray)-currentBuffer.pinnedRows]
 			columnName := "Column " + I2S(column)
 
 			// Get column name from header if available
@@ -892,6 +1010,95 @@ func drawUI(b *Buffer) error {
 			return nil
 		}
 
+		// F - turn auto-scroll to new rows on or off in follow mode
+		if event.Key() == tcell.KeyRune && event.Rune() == 'F' {
+			toggleFollowScroll()
+			return nil
+		}
+
+		// & - filter rows by a boolean expression over all columns
+		if event.Key() == tcell.KeyRune && event.Rune() == '&' {
+			if b.index != nil {
+				drawFooterText(fileNameStr, indexedUnsupportedMsg, cursorPosStr)
+				return nil
+			}
+			showFilterExprForm()
+			return nil
+		}
+
+		// Q - run a SQL query against the displayed rows
+		if event.Key() == tcell.KeyRune && event.Rune() == 'Q' {
+			if args.Follow {
+				drawFooterText(fileNameStr, "SQL queries are not available in follow mode", cursorPosStr)
+				return nil
+			}
+			if b.index != nil {
+				drawFooterText(fileNameStr, indexedUnsupportedMsg, cursorPosStr)
+				return nil
+			}
+			showSQLForm()
+			return nil
+		}
+
+		// A - group the displayed rows and aggregate columns per group
+		if event.Key() == tcell.KeyRune && event.Rune() == 'A' {
+			if b.index != nil {
+				drawFooterText(fileNameStr, indexedUnsupportedMsg, cursorPosStr)
+				return nil
+			}
+			showGroupForm()
+			return nil
+		}
+
+		// P - cross-tabulate two columns with an aggregate of a third
+		if event.Key() == tcell.KeyRune && event.Rune() == 'P' {
+			if b.index != nil {
+				drawFooterText(fileNameStr, indexedUnsupportedMsg, cursorPosStr)
+				return nil
+			}
+			showPivotForm()
+			return nil
+		}
+
+		// = - add a column computed from an expression over the other columns
+		if event.Key() == tcell.KeyRune && event.Rune() == '=' {
+			if b.index != nil {
+				drawFooterText(fileNameStr, indexedUnsupportedMsg, cursorPosStr)
+				return nil
+			}
+			showComputedForm()
+			return nil
+		}
+
+		// Enter - show the rows of the group under the cursor in a group-by tab
+		if event.Key() == tcell.KeyEnter && len(tabs) > 0 && tabs[currentTab].drill != nil {
+			row, _ := bufferTable.GetSelection()
+			drillDown(row)
+			return nil
+		}
+
+		// e - export the displayed rows to a file
+		if event.Key() == tcell.KeyRune && event.Rune() == 'e' {
+			showExportForm()
+			return nil
+		}
+
+		// x - pick another sheet of a workbook
+		if event.Key() == tcell.KeyRune && event.Rune() == 'x' {
+			showSourcePicker()
+			return nil
+		}
+
+		// Tab / Shift+Tab - next or previous file when several are open
+		if event.Key() == tcell.KeyTab && len(tabs) > 1 {
+			switchTab(currentTab + 1)
+			return nil
+		}
+		if event.Key() == tcell.KeyBacktab && len(tabs) > 1 {
+			switchTab(currentTab - 1)
+			return nil
+		}
+
 		// ? - switch to help page
 		if event.Key() == tcell.KeyRune && event.Rune() == '?' {
 			showHelpDialog()
@@ -918,7 +1125,7 @@ func drawUI(b *Buffer) error {
 			return action, event
 		case tview.MouseScrollDown:
 			row, col := bufferTable.GetSelection()
-			if row < b.rowLen-1 {
+			if row < b.rowCount()-1 {
 				bufferTable.Select(row+1, col)
 			}
 			return action, event
@@ -1047,8 +1254,8 @@ func showStatsDialog(statsS statsSummary, columnName string, colType int) {
 	title := fmt.Sprintf(" 📊 Statistics: %s [%s] ", columnName, typeName)
 
 	// Add filter indicator if data is filtered
-	if isFiltered && len(activeFilters) > 0 {
-		title = fmt.Sprintf(" 📊 Statistics: %s [%s] (Filtered Data - %d filters active) ", columnName, typeName, len(activeFilters))
+	if isFiltered && activeFilterCount() > 0 {
+		title = fmt.Sprintf(" 📊 Statistics: %s [%s] (Filtered Data - %d filters active) ", columnName, typeName, activeFilterCount())
 	}
 
 	statsTable.SetTitle(title)
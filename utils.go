@@ -122,6 +122,9 @@ func getHelpContent() string {
                     AND: same cell has both terms
                     ROR: different rows, any match (uppercase only)
   [yellow]r[-]                   Remove filter from current column
+  [yellow]&[-]                   Filter by an expression over all columns
+                    (status == "failed" OR retries > 3) AND NOT region ~ "^eu"
+                    Columns by name or number ($3), empty to remove
 
 [::b][purple]🏷️  Data Type[white]
   [yellow]t[-]                   Toggle column data type
@@ -138,6 +141,43 @@ func getHelpContent() string {
 [::b][blue]📊 Stats[white]
   [yellow]i[-]                   Show stats info for current column
 
+[::b][green]📋 Copy[white]
+  [yellow]y[-]                   Copy the current cell
+  [yellow]yy[-]                  Copy the current row (tab-separated)
+  [yellow]yc[-]                  Copy the current column, without the header
+  [yellow]v[-]                   Start a visual selection, move to extend,
+                    [yellow]y[-] to copy it, [yellow]Esc[-] to cancel
+
+[::b][green]Σ  Group By[white]
+  [yellow]A[-]                   Group by columns with count, sum(x), mean(x),
+                    median(x), min(x), max(x) or distinct(x)
+  [yellow]Enter[-]               In the group tab, show the rows of a group
+  [yellow]P[-]                   Pivot: rows by one column, columns by the values
+                    of another, cells with an aggregate, with totals
+
+[::b][green]➕ Computed Columns[white]
+  [yellow]=[-]                   Add a column from an expression, e.g.
+                    price * qty, upper(name), first & " " & last,
+                    date_diff(end, start, "days"), regex(url, "...")
+
+[::b][green]🗃  SQL[white]
+  [yellow]Q[-]                   Query the displayed rows as table t, e.g.
+                    SELECT region, count(*) FROM t GROUP BY region
+                    The result opens in a new tab
+
+[::b][green]💾 Export[white]
+  [yellow]e[-]                   Save the displayed rows, filtered and sorted, as
+                    CSV, TSV, JSON, JSON Lines, Markdown or HTML
+
+[::b][green]📑 Sheets[white]
+  [yellow]x[-]                   Switch sheet, archive file or database table
+
+[::b][green]🗂️  Tabs[white]
+  [yellow]Tab[-] / [yellow]Shift+Tab[-]     Next / previous file (several files open)
+
+[::b][green]📡 Follow[white]
+  [yellow]F[-]                   Auto-scroll to new rows on/off (with -F)
+
 [::b][yellow]❓ Help[white]
   [yellow]?[-]                   Show this help dialog
 
@@ -303,6 +343,21 @@ func performSearch(b *Buffer, query string, useRegex bool, caseSensitive bool) [
 		query = strings.ToLower(query)
 	}
 
+	matches := func(cellText string) bool {
+		if useRegex {
+			return re.MatchString(cellText)
+		}
+		if caseSensitive {
+			return strings.Contains(cellText, query)
+		}
+		return strings.Contains(strings.ToLower(cellText), query)
+	}
+
+	// Indexed files are searched by streaming them from disk
+	if b.index != nil {
+		return b.index.search(matches, maxIndexedSearchResults)
+	}
+
 	// Parallel search across columns for better performance
 	resultChan := make(chan []SearchResult, b.colLen)
 	var wg sync.WaitGroup
@@ -314,20 +369,7 @@ func performSearch(b *Buffer, query string, useRegex bool, caseSensitive bool) [
 			var colResults []SearchResult
 
 			for r := 0; r < b.rowLen; r++ {
-				cellText := b.cont[r][col]
-
-				var matches bool
-				if useRegex {
-					matches = re.MatchString(cellText)
-				} else {
-					if caseSensitive {
-						matches = strings.Contains(cellText, query)
-					} else {
-						matches = strings.Contains(strings.ToLower(cellText), query)
-					}
-				}
-
-				if matches {
+				if matches(b.cont[r][col]) {
 					colResults = append(colResults, SearchResult{Row: r, Col: col})
 				}
 			}
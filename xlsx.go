@@ -0,0 +1,455 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zipMagic starts every zip container (xlsx workbooks are zip files)
+const zipMagic = "PK\x03\x04"
+
+// how a numeric cell is displayed, derived from its number format
+const (
+	xlsxNumGeneral = iota
+	xlsxNumDate
+	xlsxNumTime
+	xlsxNumDateTime
+	xlsxNumPercent
+)
+
+// isXLSXFile checks the file name for an Excel workbook extension
+func isXLSXFile(fn string) bool {
+	lower := strings.ToLower(fn)
+	return strings.HasSuffix(lower, ".xlsx") || strings.HasSuffix(lower, ".xlsm")
+}
+
+// isXLSXZipFile checks whether a zip file holds an Excel workbook
+func isXLSXZipFile(fn string) bool {
+	zr, err := zip.OpenReader(fn)
+	if err != nil {
+		return false
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if f.Name == "xl/workbook.xml" {
+			return true
+		}
+	}
+	return false
+}
+
+// xlsxWorkbook is an opened workbook, sheets are read on demand
+type xlsxWorkbook struct {
+	zr       *zip.Reader
+	sheets   []xlsxSheetRef
+	shared   []string
+	numKinds []int // display kind per cell style index
+	date1904 bool
+}
+
+// xlsxSheetRef is a sheet name and the path of its part in the zip
+type xlsxSheetRef struct {
+	name string
+	path string
+}
+
+// XML shapes of the workbook parts, only the parts ftv needs
+type xlsxWorkbookXML struct {
+	WorkbookPr struct {
+		Date1904 string `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// text joins plain and rich text runs
+func (x xlsxText) text() string {
+	if len(x.R) == 0 {
+		return x.T
+	}
+	var sb strings.Builder
+	sb.WriteString(x.T)
+	for _, r := range x.R {
+		sb.WriteString(r.T)
+	}
+	return sb.String()
+}
+
+type xlsxSharedStringsXML struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxStylesXML struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type xlsxSheetXML struct {
+	Rows []struct {
+		Ref   int `xml:"r,attr"` // 1-based row number, blank rows are left out
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Style  int      `xml:"s,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// openXLSXFile opens a workbook file
+func openXLSXFile(fn string) (*xlsxWorkbook, error) {
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	return openXLSX(data)
+}
+
+// openXLSX reads the workbook structure, shared strings and styles
+func openXLSX(data []byte) (*xlsxWorkbook, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	wb := &xlsxWorkbook{zr: zr}
+
+	var workbook xlsxWorkbookXML
+	if err := wb.readXML("xl/workbook.xml", &workbook); err != nil {
+		return nil, errors.New("not an Excel workbook: " + err.Error())
+	}
+	wb.date1904 = workbook.WorkbookPr.Date1904 == "1" || workbook.WorkbookPr.Date1904 == "true"
+
+	var rels xlsxRelsXML
+	if err := wb.readXML("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			target = strings.TrimPrefix(target, "/")
+		} else {
+			target = path.Join("xl", target)
+		}
+		targets[rel.ID] = target
+	}
+	for _, s := range workbook.Sheets {
+		if target, ok := targets[s.RID]; ok {
+			wb.sheets = append(wb.sheets, xlsxSheetRef{name: s.Name, path: target})
+		}
+	}
+	if len(wb.sheets) == 0 {
+		return nil, errors.New("workbook has no sheets")
+	}
+
+	// Shared strings and styles are optional parts
+	var sst xlsxSharedStringsXML
+	if err := wb.readXML("xl/sharedStrings.xml", &sst); err == nil {
+		wb.shared = make([]string, len(sst.Items))
+		for i, si := range sst.Items {
+			wb.shared[i] = si.text()
+		}
+	}
+	var styles xlsxStylesXML
+	if err := wb.readXML("xl/styles.xml", &styles); err == nil {
+		custom := make(map[int]string, len(styles.NumFmts))
+		for _, nf := range styles.NumFmts {
+			custom[nf.ID] = nf.Code
+		}
+		wb.numKinds = make([]int, len(styles.CellXfs))
+		for i, xf := range styles.CellXfs {
+			wb.numKinds[i] = xlsxNumFmtKind(xf.NumFmtID, custom[xf.NumFmtID])
+		}
+	}
+	return wb, nil
+}
+
+// readXML decodes a zip member into v
+func (wb *xlsxWorkbook) readXML(name string, v interface{}) error {
+	f, err := wb.zr.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return xml.NewDecoder(f).Decode(v)
+}
+
+// sheetNames lists the sheets in workbook order
+func (wb *xlsxWorkbook) sheetNames() []string {
+	names := make([]string, len(wb.sheets))
+	for i, s := range wb.sheets {
+		names[i] = s.name
+	}
+	return names
+}
+
+// readSheet returns the cells of a sheet as display text, rows padded to the
+// widest row
+func (wb *xlsxWorkbook) readSheet(name string) ([][]string, error) {
+	var ref *xlsxSheetRef
+	for i := range wb.sheets {
+		if wb.sheets[i].name == name {
+			ref = &wb.sheets[i]
+			break
+		}
+	}
+	if ref == nil {
+		return nil, errors.New("sheet " + name + " not found")
+	}
+
+	var sheet xlsxSheetXML
+	if err := wb.readXML(ref.path, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	width := 0
+	for _, r := range sheet.Rows {
+		// Rows the writer left out are blank rows
+		for r.Ref > 0 && len(rows) < r.Ref-1 {
+			rows = append(rows, nil)
+		}
+		var row []string
+		for i, c := range r.Cells {
+			col := i
+			if c.Ref != "" {
+				col = xlsxColumnIndex(c.Ref)
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			row[col] = wb.cellText(c.Type, c.Style, c.Value, c.Inline)
+		}
+		// Drop trailing empty cells so formatting-only cells do not widen the table
+		for len(row) > 0 && row[len(row)-1] == "" {
+			row = row[:len(row)-1]
+		}
+		if len(row) > width {
+			width = len(row)
+		}
+		rows = append(rows, row)
+	}
+	for i := range rows {
+		for len(rows[i]) < width {
+			rows[i] = append(rows[i], "")
+		}
+	}
+	return rows, nil
+}
+
+// cellText renders a cell the way Excel displays it
+func (wb *xlsxWorkbook) cellText(cellType string, style int, value string, inline xlsxText) string {
+	switch cellType {
+	case "s":
+		idx, err := strconv.Atoi(value)
+		if err != nil || idx < 0 || idx >= len(wb.shared) {
+			return ""
+		}
+		return wb.shared[idx]
+	case "inlineStr":
+		return inline.text()
+	case "b":
+		if value == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	case "str", "e", "d":
+		return value
+	}
+
+	if value == "" {
+		return ""
+	}
+	num, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	kind := xlsxNumGeneral
+	if style >= 0 && style < len(wb.numKinds) {
+		kind = wb.numKinds[style]
+	}
+	switch kind {
+	case xlsxNumDate:
+		return xlsxSerialToTime(num, wb.date1904).Format(time.DateOnly)
+	case xlsxNumTime:
+		return xlsxSerialToTime(num, wb.date1904).Format(time.TimeOnly)
+	case xlsxNumDateTime:
+		return xlsxSerialToTime(num, wb.date1904).Format(time.DateTime)
+	case xlsxNumPercent:
+		return xlsxGeneralNumber(num*100) + "%"
+	default:
+		return xlsxGeneralNumber(num)
+	}
+}
+
+// xlsxGeneralNumber formats a number like Excel's General format, which shows
+// at most 15 significant digits
+func xlsxGeneralNumber(num float64) string {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(num, 'g', 15, 64), 64)
+	if err != nil {
+		rounded = num
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+// xlsxSerialToTime converts an Excel serial date, the 1900 system epoch is
+// 1899-12-30 to absorb Excel's fictitious 1900-02-29
+func xlsxSerialToTime(serial float64, date1904 bool) time.Time {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 86400)
+	return epoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+}
+
+// xlsxColumnIndex turns the letters of a cell reference (e.g. "AB12") into a
+// 0-based column index
+func xlsxColumnIndex(ref string) int {
+	col := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+	}
+	return col - 1
+}
+
+// xlsxNumFmtKind classifies a number format as general, date, time, date-time
+// or percent, from its built-in id or custom format code
+func xlsxNumFmtKind(id int, code string) int {
+	switch {
+	case id >= 14 && id <= 17, id >= 27 && id <= 36, id >= 50 && id <= 58:
+		return xlsxNumDate
+	case id >= 18 && id <= 21, id >= 45 && id <= 47:
+		return xlsxNumTime
+	case id == 22:
+		return xlsxNumDateTime
+	case id == 9 || id == 10:
+		return xlsxNumPercent
+	}
+	if code == "" {
+		return xlsxNumGeneral
+	}
+
+	// Only look at the first section and drop literals, colors and locales
+	code = strings.SplitN(code, ";", 2)[0]
+	var sb strings.Builder
+	inQuote := false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case c == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '\\' || c == '_' || c == '*':
+			i++ // escaped or padding character
+		case c == '[':
+			end := strings.IndexByte(code[i:], ']')
+			if end < 0 {
+				i = len(code)
+			} else {
+				// Elapsed time like [h] still counts as time
+				inner := strings.ToLower(code[i+1 : i+end])
+				if inner == "h" || inner == "hh" || inner == "m" || inner == "mm" || inner == "s" || inner == "ss" {
+					sb.WriteString(inner)
+				}
+				i += end
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	clean := strings.ToLower(sb.String())
+
+	hasDate := strings.ContainsAny(clean, "yd")
+	hasTime := strings.ContainsAny(clean, "hs")
+	if !hasDate && !hasTime && strings.Contains(clean, "m") && !strings.Contains(clean, "general") {
+		hasDate = true // e.g. "mmm"
+	}
+	switch {
+	case hasDate && hasTime:
+		return xlsxNumDateTime
+	case hasDate:
+		return xlsxNumDate
+	case hasTime:
+		return xlsxNumTime
+	case strings.Contains(clean, "%"):
+		return xlsxNumPercent
+	}
+	return xlsxNumGeneral
+}
+
+// loadSheet reads a sheet into b and detects column types
+func (wb *xlsxWorkbook) loadSheet(name string, b *Buffer) error {
+	rows, err := wb.readSheet(name)
+	if err != nil {
+		return err
+	}
+	// Skip rules are applied per sheet, so switching sheets behaves the same
+	if args.SkipNum > 0 {
+		if args.SkipNum >= len(rows) {
+			rows = nil
+		} else {
+			rows = rows[args.SkipNum:]
+		}
+	}
+	totalAddedLN := 0
+	for _, row := range rows {
+		if totalAddedLN >= args.NLine && args.NLine > 0 {
+			break
+		}
+		// A row starts with the text of its first cell
+		if len(row) > 0 && skipLine(row[0], args.SkipSymbol) {
+			continue
+		}
+		if err := appendVisible(b, row); err != nil {
+			return err
+		}
+		totalAddedLN++
+	}
+	b.detectAllColumnTypes()
+	b.enableStringInterning()
+	return nil
+}
+
+// newXLSXSourceSet exposes the sheets of a workbook to the source picker
+func newXLSXSourceSet(wb *xlsxWorkbook) *sourceSet {
+	return &sourceSet{
+		kind:  "Sheet",
+		names: wb.sheetNames(),
+		load:  wb.loadSheet,
+	}
+}
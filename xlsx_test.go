@@ -0,0 +1,211 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestXLSX builds a minimal two-sheet workbook
+func writeTestXLSX(t *testing.T) []byte {
+	t.Helper()
+	parts := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Sales" sheetId="1" r:id="rId1"/><sheet name="Notes" sheetId="2" r:id="rId2"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>region</t></si><si><t>day</t></si><si><t>amount</t></si><si><t>share</t></si>
+<si><r><t>No</t></r><r><t>rth</t></r></si>
+</sst>`,
+		"xl/styles.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd hh:mm"/></numFmts>
+<cellXfs><xf numFmtId="0"/><xf numFmtId="14"/><xf numFmtId="9"/><xf numFmtId="164"/></cellXfs>
+</styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="s"><v>3</v></c><c r="E1" t="inlineStr"><is><t>stamp</t></is></c></row>
+<row r="2"><c r="A2" t="s"><v>4</v></c><c r="B2" s="1"><v>45582</v></c><c r="C2"><v>0.1</v></c><c r="D2" s="2"><v>0.25</v></c><c r="E2" s="3"><v>45582.5</v></c></row>
+<row r="3"><c r="A3" t="inlineStr"><is><t>South</t></is></c><c r="C3"><v>12</v></c><c r="E3" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>note</t></is></c></row>
+<row r="2"><c r="A2" t="str"><v>checked</v></c></row>
+<row r="4"><c r="A4" t="inlineStr"><is><t># draft</t></is></c></row>
+<row r="5"><c r="A5" t="inlineStr"><is><t>later</t></is></c></row>
+</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestOpenXLSX(t *testing.T) {
+	wb, err := openXLSX(writeTestXLSX(t))
+	if err != nil {
+		t.Fatalf("openXLSX() error = %v", err)
+	}
+	names := wb.sheetNames()
+	if len(names) != 2 || names[0] != "Sales" || names[1] != "Notes" {
+		t.Fatalf("sheetNames() = %v, want [Sales Notes]", names)
+	}
+
+	rows, err := wb.readSheet("Sales")
+	if err != nil {
+		t.Fatalf("readSheet() error = %v", err)
+	}
+	want := [][]string{
+		{"region", "day", "amount", "share", "stamp"},
+		{"North", "2024-10-17", "0.1", "25%", "2024-10-17 12:00:00"},
+		{"South", "", "12", "", "TRUE"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("readSheet() returned %d rows, want %d", len(rows), len(want))
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("cell [%d][%d] = %q, want %q", i, j, rows[i][j], want[i][j])
+			}
+		}
+	}
+
+	if _, err := openXLSX([]byte("not a zip")); err == nil {
+		t.Error("openXLSX() should fail on non-zip data")
+	}
+}
+
+func TestXLSXLoadSheet(t *testing.T) {
+	wb, err := openXLSX(writeTestXLSX(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := newXLSXSourceSet(wb)
+
+	b := createNewBuffer()
+	if err := set.loadInto("Sales", b); err != nil {
+		t.Fatalf("loadInto() error = %v", err)
+	}
+	if set.current != "Sales" {
+		t.Errorf("current = %q, want Sales", set.current)
+	}
+	if b.rowLen != 3 || b.colLen != 5 {
+		t.Fatalf("Expected 3x5 buffer, got %dx%d", b.rowLen, b.colLen)
+	}
+	if b.getColType(2) != colTypeFloat {
+		t.Errorf("amount column type = %s, want Number", type2name(b.getColType(2)))
+	}
+
+	// Blank rows keep the rows below them at their row numbers
+	nb := createNewBuffer()
+	if err := set.loadInto("Notes", nb); err != nil {
+		t.Fatalf("loadInto() error = %v", err)
+	}
+	if nb.rowLen != 5 || nb.cont[1][0] != "checked" || nb.cont[2][0] != "" || nb.cont[4][0] != "later" {
+		t.Errorf("Unexpected Notes sheet: %q", nb.cont)
+	}
+
+	args.SkipSymbol = []string{"#"}
+	defer args.setDefault()
+	nb = createNewBuffer()
+	if err := set.loadInto("Notes", nb); err != nil {
+		t.Fatalf("loadInto() error = %v", err)
+	}
+	if nb.rowLen != 4 || nb.cont[3][0] != "later" {
+		t.Errorf("--skip-prefix left %q", nb.cont)
+	}
+	if err := set.loadInto("Missing", createNewBuffer()); err == nil {
+		t.Error("loadInto() should fail for an unknown sheet")
+	}
+}
+
+func TestXLSXNumFmtKind(t *testing.T) {
+	tests := []struct {
+		id   int
+		code string
+		want int
+	}{
+		{0, "", xlsxNumGeneral},
+		{2, "", xlsxNumGeneral},
+		{14, "", xlsxNumDate},
+		{21, "", xlsxNumTime},
+		{22, "", xlsxNumDateTime},
+		{9, "", xlsxNumPercent},
+		{164, "yyyy-mm-dd", xlsxNumDate},
+		{164, "d-mmm", xlsxNumDate},
+		{164, "mmm", xlsxNumDate},
+		{164, "hh:mm:ss", xlsxNumTime},
+		{164, "[h]:mm", xlsxNumTime},
+		{164, "yyyy\\-mm\\-dd hh:mm", xlsxNumDateTime},
+		{164, "[$-409]dd/mm/yyyy;@", xlsxNumDate},
+		{164, "0.00%", xlsxNumPercent},
+		{164, "#,##0.00", xlsxNumGeneral},
+		{164, "\"days\" 0", xlsxNumGeneral},
+		{164, "[Red]0.00", xlsxNumGeneral},
+	}
+	for _, tt := range tests {
+		if got := xlsxNumFmtKind(tt.id, tt.code); got != tt.want {
+			t.Errorf("xlsxNumFmtKind(%d, %q) = %d, want %d", tt.id, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestXLSXHelpers(t *testing.T) {
+	for ref, want := range map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "AB12": 27} {
+		if got := xlsxColumnIndex(ref); got != want {
+			t.Errorf("xlsxColumnIndex(%q) = %d, want %d", ref, got, want)
+		}
+	}
+
+	if got := xlsxSerialToTime(1, false); !got.Equal(time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("xlsxSerialToTime(1) = %v", got)
+	}
+	if got := xlsxSerialToTime(0, true); !got.Equal(time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("xlsxSerialToTime(0, 1904) = %v", got)
+	}
+
+	for num, want := range map[float64]string{0.1 + 0.2: "0.3", 12: "12", 1234567.125: "1234567.125"} {
+		if got := xlsxGeneralNumber(num); got != want {
+			t.Errorf("xlsxGeneralNumber(%v) = %q, want %q", num, got, want)
+		}
+	}
+}
+
+func TestDetectXLSXFormat(t *testing.T) {
+	data := writeTestXLSX(t)
+	dir := t.TempDir()
+	for _, name := range []string{"book.xlsx", "book.zip"} {
+		fn := filepath.Join(dir, name)
+		if err := os.WriteFile(fn, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		format, err := detectFileFormat(fn)
+		if err != nil || format != formatXLSX {
+			t.Errorf("detectFileFormat(%s) = %d, %v, want xlsx", name, format, err)
+		}
+	}
+}
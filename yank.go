@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// time to wait for the second key of yy or yc before y copies the cell
+const yankDelay = 500 * time.Millisecond
+
+// cellPos is the row and column of a table cell
+type cellPos struct {
+	row, col int
+}
+
+// tsvText joins rows with tabs and newlines, quoting cells that hold either so
+// they paste into spreadsheets as they are
+func tsvText(rows [][]string) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = '\t'
+	_ = w.WriteAll(rows) // writing to a bytes.Buffer does not fail
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// yankRect returns the cells between two corners as TSV, a single cell as is
+func yankRect(nb *Buffer, from, to cellPos) string {
+	nb.mu.RLock()
+	defer nb.mu.RUnlock()
+	r0, r1 := min(from.row, to.row), max(from.row, to.row)
+	c0, c1 := min(from.col, to.col), max(from.col, to.col)
+	rows := make([][]string, 0, r1-r0+1)
+	for r := r0; r <= r1; r++ {
+		cells := nb.rowAt(r)
+		row := make([]string, 0, c1-c0+1)
+		for c := c0; c <= c1; c++ {
+			if c < len(cells) {
+				row = append(row, cells[c])
+			} else {
+				row = append(row, "")
+			}
+		}
+		rows = append(rows, row)
+	}
+	if r0 == r1 && c0 == c1 {
+		return rows[0][0]
+	}
+	return tsvText(rows)
+}
+
+// yankRow returns row r as a line of TSV
+func yankRow(nb *Buffer, r int) string {
+	return yankRect(nb, cellPos{r, 0}, cellPos{r, max(nb.colLen-1, 0)})
+}
+
+// yankColumn returns the values of column c one per line, without the header
+func yankColumn(nb *Buffer, c int) string {
+	last := nb.rowCount() - 1
+	if last < nb.rowFreeze {
+		return ""
+	}
+	return yankRect(nb, cellPos{nb.rowFreeze, c}, cellPos{last, c})
+}
+
+// copyToClipboard sends text to --yank-command or --yank-file when set and
+// to the terminal's clipboard otherwise, it returns where the text went
+func copyToClipboard(text string) (string, error) {
+	switch {
+	case args.YankCommand != "":
+		cmd := exec.Command("sh", "-c", args.YankCommand)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			msg := strings.TrimSpace(string(out))
+			if msg == "" {
+				msg = err.Error()
+			}
+			return "", errors.New(args.YankCommand + ": " + msg)
+		}
+		return args.YankCommand, nil
+	case args.YankFile != "":
+		if err := os.WriteFile(args.YankFile, []byte(text), 0o644); err != nil {
+			return "", err
+		}
+		return args.YankFile, nil
+	}
+	// The screen sends the OSC 52 escape between its own writes, so it does
+	// not land in the middle of a redraw
+	if uiScreen == nil {
+		return "", errors.New("no terminal to copy to, use --yank-command or --yank-file")
+	}
+	uiScreen.SetClipboard([]byte(text))
+	return "clipboard", nil
+}
+
+// yank copies text and reports it in the footer
+func yank(what, text string) {
+	target, err := copyToClipboard(text)
+	if err != nil {
+		updateFooterWithStatus("Copy failed: " + err.Error())
+		return
+	}
+	updateFooterWithStatus(fmt.Sprintf("Copied %s to %s (%s)", what, target, formatBytes(int64(len(text)))))
+}
+
+// handleYankKey runs the y, yy, yc and v commands and reports whether it
+// used up the key. Any other key after y copies the cell first.
+func handleYankKey(event *tcell.EventKey) bool {
+	row, col := bufferTable.GetSelection()
+	isRune := func(r rune) bool { return event.Key() == tcell.KeyRune && event.Rune() == r }
+
+	if yankPending {
+		yankPending = false
+		switch {
+		case isRune('y'):
+			yank("row", yankRow(b, row))
+			return true
+		case isRune('c'):
+			yank("column", yankColumn(b, col))
+			return true
+		}
+		yank("cell", yankRect(b, cellPos{row, col}, cellPos{row, col}))
+	}
+
+	switch {
+	case visualAnchor != nil && isRune('y'):
+		from := *visualAnchor
+		stopVisual()
+		yank("selection", yankRect(b, from, cellPos{row, col}))
+		return true
+	case visualAnchor != nil && (isRune('v') || event.Key() == tcell.KeyEscape):
+		stopVisual()
+		updateFooterWithStatus("Visual selection cleared")
+		return true
+	case isRune('v'):
+		visualAnchor = &cellPos{row, col}
+		visualCursor = *visualAnchor
+		refreshVisual(*visualAnchor, *visualAnchor, *visualAnchor)
+		updateFooterWithStatus("Visual selection: move to extend, y to copy, Esc to cancel")
+		return true
+	case isRune('y'):
+		// y waits for a second key, without one it copies the cell
+		yankPending = true
+		yankSeq++
+		seq := yankSeq
+		time.AfterFunc(yankDelay, func() {
+			app.QueueUpdateDraw(func() {
+				if yankPending && seq == yankSeq {
+					yankPending = false
+					row, col := bufferTable.GetSelection()
+					yank("cell", yankRect(b, cellPos{row, col}, cellPos{row, col}))
+				}
+			})
+		})
+		return true
+	}
+	return false
+}
+
+// inVisualSelection reports whether cell r, c lies in the visual selection
+func inVisualSelection(r, c int) bool {
+	if visualAnchor == nil {
+		return false
+	}
+	return r >= min(visualAnchor.row, visualCursor.row) && r <= max(visualAnchor.row, visualCursor.row) &&
+		c >= min(visualAnchor.col, visualCursor.col) && c <= max(visualAnchor.col, visualCursor.col)
+}
+
+// moveVisual extends the visual selection to the cursor at row, col
+func moveVisual(row, col int) {
+	if visualAnchor == nil {
+		return
+	}
+	old := visualCursor
+	visualCursor = cellPos{row, col}
+	refreshVisual(*visualAnchor, old, visualCursor)
+}
+
+// stopVisual leaves visual mode and removes the highlight
+func stopVisual() {
+	if visualAnchor == nil {
+		return
+	}
+	anchor, cursor := *visualAnchor, visualCursor
+	visualAnchor = nil
+	refreshVisual(anchor, cursor, cursor)
+}
+
+// refreshVisual restyles the cells whose highlight may have changed when the
+// selection from anchor moved from old to cur. Indexed tables build their
+// cells as they are drawn and need nothing.
+func refreshVisual(anchor, old, cur cellPos) {
+	if b.index != nil {
+		return
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var hits map[SearchResult]bool
+	if searchQuery != "" {
+		hits = make(map[SearchResult]bool, len(searchResults))
+		for _, result := range searchResults {
+			hits[result] = true
+		}
+	}
+	r0, r1 := min(anchor.row, old.row, cur.row), max(anchor.row, old.row, cur.row)
+	c0, c1 := min(anchor.col, old.col, cur.col), max(anchor.col, old.col, cur.col)
+	for r := r0; r <= r1 && r < b.rowLen; r++ {
+		for c := c0; c <= c1 && c < len(b.cont[r]); c++ {
+			bufferTable.SetCell(r, c, newBufferCell(b, r, c, b.cont[r][c], hits[SearchResult{Row: r, Col: c}], wrappedColumns[c]))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestYankText(t *testing.T) {
+	b, err := createNewBufferWithData([][]string{{"id", "note"}, {"1", "two\twords"}, {"2", "line\nbreak"}, {"3", `say "hi"`}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.rowFreeze = 1
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"Cell is copied as is", yankRect(b, cellPos{1, 1}, cellPos{1, 1}), "two\twords"},
+		{"Row", yankRow(b, 3), "3\t\"say \"\"hi\"\"\""},
+		{"Column without header", yankColumn(b, 0), "1\n2\n3"},
+		{"Selection from any corner", yankRect(b, cellPos{2, 1}, cellPos{1, 0}), "1\t\"two\twords\"\n2\t\"line\nbreak\""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestCopyToClipboardTargets(t *testing.T) {
+	defer args.setDefault()
+	dir := t.TempDir()
+
+	args.YankFile = filepath.Join(dir, "yank.txt")
+	if _, err := copyToClipboard("a\tb"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(args.YankFile); string(got) != "a\tb" {
+		t.Errorf("--yank-file wrote %q", got)
+	}
+
+	args.YankFile = ""
+	out := filepath.Join(dir, "cmd.txt")
+	args.YankCommand = "cat > " + out
+	if _, err := copyToClipboard("x\ny"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(out); string(got) != "x\ny" {
+		t.Errorf("--yank-command received %q", got)
+	}
+
+	args.YankCommand = "echo nope >&2; exit 3"
+	if _, err := copyToClipboard("x"); err == nil || err.Error() != args.YankCommand+": nope" {
+		t.Errorf("failing command error = %v", err)
+	}
+}
+
+func TestCopyToClipboardThroughScreen(t *testing.T) {
+	defer func() { uiScreen = nil }()
+	screen := tcell.NewSimulationScreen("UTF-8")
+	uiScreen = screen
+	if target, err := copyToClipboard("a\tb"); err != nil || target != "clipboard" {
+		t.Fatalf("copyToClipboard() = %q, %v", target, err)
+	}
+	if got := string(screen.GetClipboardData()); got != "a\tb" {
+		t.Errorf("clipboard = %q", got)
+	}
+
+	uiScreen = nil
+	if _, err := copyToClipboard("x"); err == nil {
+		t.Error("copying without a screen succeeded")
+	}
+}